@@ -9,10 +9,19 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// Часовые пояса пользователей проверяются и без системной базы tzdata в образе.
+	_ "time/tzdata"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
 	"example.com/ai-budget-planner/backend/internal/config"
 	"example.com/ai-budget-planner/backend/internal/database"
+	"example.com/ai-budget-planner/backend/internal/notifications"
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/scheduler"
 	"example.com/ai-budget-planner/backend/internal/server"
+	"example.com/ai-budget-planner/backend/internal/webhooks"
 )
 
 func main() {
@@ -36,7 +45,45 @@ func main() {
 		db.Close()
 	}()
 
-	e := server.New(cfg, logger, db)
+	// ADMIN_EMAILS назначает первых администраторов, пока их нет; дальше роли управляются через API.
+	granted, err := repository.NewRoleRepository(db).Bootstrap(context.Background(), cfg.Admin.Emails)
+	if err != nil {
+		logger.Error("failed to bootstrap admin roles", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if granted > 0 {
+		logger.Info("admin roles bootstrapped", slog.Int64("granted", granted))
+	}
+
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookDispatcher := webhooks.NewDispatcher(webhookRepo, logger, cfg.Webhooks.Timeout, cfg.Webhooks.MaxAttempts)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobs := scheduler.New(logger)
+	jobs.Add(scheduler.PlanRolloverJob(repository.NewPlanRepository(db), logger, cfg.Scheduler.RolloverInterval))
+	jobs.Add(scheduler.NotificationPruneJob(repository.NewNotificationRepository(db), logger, cfg.Scheduler.NotificationPruneInterval, cfg.Notifications.Retention))
+	jobs.Add(scheduler.WebhookDeliveryJob(webhookDispatcher, logger, cfg.Scheduler.WebhookDeliveryInterval))
+	jobs.Add(scheduler.WebhookPruneJob(webhookRepo, logger, cfg.Scheduler.NotificationPruneInterval, cfg.Notifications.Retention))
+	jobs.Add(scheduler.AccountPurgeJob(repository.NewAccountRepository(db), logger, cfg.Scheduler.AccountPurgeInterval, cfg.Auth.DeletionGrace))
+
+	// Ключи подписи нужны до старта HTTP-сервера, поэтому первая загрузка синхронная.
+	var signingKeys *auth.KeySet
+	if cfg.Auth.SigningAlgorithm != config.JWTAlgorithmHS256 {
+		signingKeys = auth.NewKeySet()
+		verifyFor := max(cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+		signingKeyJob := scheduler.SigningKeyJob(repository.NewSigningKeyRepository(db), signingKeys, logger,
+			cfg.Scheduler.SigningKeyInterval, cfg.Auth.SigningAlgorithm, cfg.Auth.KeyEncryptionKey, cfg.Auth.KeyRotation, verifyFor)
+		if err := signingKeyJob.Run(context.Background()); err != nil {
+			logger.Error("failed to load signing keys", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		jobs.Add(signingKeyJob)
+	}
+	jobs.Start(jobsCtx)
+
+	notificationHub := newNotificationHub(jobsCtx, cfg.Notifications, logger, db, webhookDispatcher)
+
+	e := server.New(cfg, logger, db, notificationHub, signingKeys)
 	httpServer := server.NewHTTPServer(cfg.Server, e)
 
 	go func() {
@@ -49,6 +96,9 @@ func main() {
 	signal.Notify(shutdownSignal, syscall.SIGINT, syscall.SIGTERM)
 	<-shutdownSignal
 
+	stopJobs()
+	jobs.Wait()
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -57,6 +107,23 @@ func main() {
 	}
 }
 
+// newNotificationHub собирает хаб уведомлений с журналом событий, очередью вебхуков
+// и выбранным брокером. Слушатель Postgres-брокера работает, пока не отменен ctx.
+func newNotificationHub(ctx context.Context, cfg config.NotificationsConfig, logger *slog.Logger, db *pgxpool.Pool, sink notifications.Sink) *notifications.Hub {
+	options := []notifications.Option{
+		notifications.WithStore(repository.NewNotificationRepository(db)),
+		notifications.WithSink(sink),
+	}
+
+	if cfg.Broker == config.NotificationBrokerPostgres {
+		broker := notifications.NewPostgresBroker(db, logger)
+		go broker.Run(ctx)
+		options = append(options, notifications.WithBroker(broker))
+	}
+
+	return notifications.NewHub(options...)
+}
+
 func ensureEnvFile() {
 	if os.Getenv("ENV_FILE") != "" {
 		return
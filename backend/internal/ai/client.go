@@ -7,8 +7,26 @@ type Message struct {
 	Content string `json:"content"`
 }
 
+const (
+	ProviderGemini = "gemini"
+	ProviderGroq   = "groq"
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+)
+
+const defaultMaxTokens = 4096
+
+// ChatResult — ответ модели: текст, сырой ответ API и провайдер с моделью,
+// которые его дали. При ошибке Raw, Provider и Model заполняются, если известны.
+type ChatResult struct {
+	Content  string
+	Raw      []byte
+	Provider string
+	Model    string
+}
+
 type Client interface {
-	Chat(ctx context.Context, messages []Message) (string, []byte, error)
+	Chat(ctx context.Context, messages []Message) (ChatResult, error)
 }
 
 func resolveMaxTokens(value int) int {
@@ -0,0 +1,167 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrNoProviders возвращается, если все провайдеры цепочки временно отключены.
+var ErrNoProviders = errors.New("no ai providers available")
+
+// FailoverProvider — звено цепочки FailoverClient. Timeout ограничивает одну
+// попытку обращения к провайдеру; 0 — без отдельного ограничения.
+type FailoverProvider struct {
+	Name    string
+	Client  Client
+	Timeout time.Duration
+}
+
+// FailoverClient опрашивает провайдеров по порядку и возвращает первый
+// успешный ответ. После threshold ошибок подряд провайдер пропускается на
+// время cooldown, затем получает одну пробную попытку.
+type FailoverClient struct {
+	providers []failoverEntry
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+type failoverEntry struct {
+	FailoverProvider
+	breaker *breaker
+}
+
+// NewFailoverClient создает клиент с цепочкой провайдеров в порядке приоритета.
+func NewFailoverClient(providers []FailoverProvider, threshold int, cooldown time.Duration, logger *slog.Logger) *FailoverClient {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	entries := make([]failoverEntry, 0, len(providers))
+	for _, provider := range providers {
+		entries = append(entries, failoverEntry{
+			FailoverProvider: provider,
+			breaker:          &breaker{threshold: threshold, cooldown: cooldown},
+		})
+	}
+
+	return &FailoverClient{
+		providers: entries,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Chat отправляет сообщения первому доступному провайдеру и при ошибке
+// переходит к следующему. Если не ответил никто, возвращает ошибки всех
+// попыток и результат последней из них.
+func (c *FailoverClient) Chat(ctx context.Context, messages []Message) (ChatResult, error) {
+	var last ChatResult
+	errs := make([]error, 0, len(c.providers))
+
+	for _, provider := range c.providers {
+		if !provider.breaker.allow(c.now()) {
+			continue
+		}
+
+		result, err := c.attempt(ctx, provider.FailoverProvider, messages)
+		if err == nil {
+			provider.breaker.success()
+			return result, nil
+		}
+
+		// Запрос отменил клиент: провайдер не виноват, остальных не опрашиваем.
+		if ctx.Err() != nil {
+			provider.breaker.release()
+			return result, err
+		}
+
+		disabled := provider.breaker.failure(c.now())
+		c.logger.Warn("ai provider failed",
+			slog.String("provider", provider.Name),
+			slog.Bool("disabled", disabled),
+			slog.String("error", err.Error()),
+		)
+
+		last = result
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name, err))
+	}
+
+	if len(errs) == 0 {
+		return last, ErrNoProviders
+	}
+
+	return last, errors.Join(errs...)
+}
+
+func (c *FailoverClient) attempt(ctx context.Context, provider FailoverProvider, messages []Message) (ChatResult, error) {
+	if provider.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, provider.Timeout)
+		defer cancel()
+	}
+
+	return provider.Client.Chat(ctx, messages)
+}
+
+// breaker — автомат отключения провайдера: закрыт, пока ошибок подряд меньше
+// threshold, затем открыт до openUntil, после чего пропускает одну пробную попытку.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow сообщает, можно ли сейчас обратиться к провайдеру.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+// success закрывает автомат после удачного ответа.
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// failure учитывает ошибку и возвращает true, если провайдер только что отключен.
+func (b *breaker) failure(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return false
+	}
+
+	b.openUntil = now.Add(b.cooldown)
+	return true
+}
+
+// release снимает пробную попытку, не засчитывая ее результат.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
@@ -0,0 +1,141 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type stubClient struct {
+	name  string
+	err   error
+	delay time.Duration
+	calls int
+}
+
+func (c *stubClient) Chat(ctx context.Context, _ []Message) (ChatResult, error) {
+	c.calls++
+	result := ChatResult{Provider: c.name, Model: c.name + "-model"}
+
+	if c.delay > 0 {
+		select {
+		case <-time.After(c.delay):
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+
+	if c.err != nil {
+		return result, c.err
+	}
+
+	result.Content = "{}"
+	return result, nil
+}
+
+func newTestFailover(threshold int, cooldown time.Duration, clients ...*stubClient) *FailoverClient {
+	providers := make([]FailoverProvider, 0, len(clients))
+	for _, client := range clients {
+		providers = append(providers, FailoverProvider{Name: client.name, Client: client, Timeout: 50 * time.Millisecond})
+	}
+	return NewFailoverClient(providers, threshold, cooldown, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// TestFailoverClientFallsBack проверяет переход к следующему провайдеру при ошибке.
+func TestFailoverClientFallsBack(t *testing.T) {
+	primary := &stubClient{name: "gemini", err: errors.New("unavailable")}
+	secondary := &stubClient{name: "groq"}
+	client := newTestFailover(3, time.Minute, primary, secondary)
+
+	result, err := client.Chat(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "groq" || result.Model != "groq-model" {
+		t.Fatalf("expected answer from groq, got %s/%s", result.Provider, result.Model)
+	}
+	if primary.calls != 1 || secondary.calls != 1 {
+		t.Fatalf("expected one call each, got %d and %d", primary.calls, secondary.calls)
+	}
+}
+
+// TestFailoverClientTimeout проверяет, что зависший провайдер прерывается по таймауту.
+func TestFailoverClientTimeout(t *testing.T) {
+	slow := &stubClient{name: "gemini", delay: time.Second}
+	fast := &stubClient{name: "groq"}
+	client := newTestFailover(3, time.Minute, slow, fast)
+
+	started := time.Now()
+	result, err := client.Chat(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "groq" {
+		t.Fatalf("expected answer from groq, got %s", result.Provider)
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected timeout to cut slow provider, took %s", elapsed)
+	}
+}
+
+// TestFailoverClientAllFailed проверяет ошибку, когда не ответил ни один провайдер.
+func TestFailoverClientAllFailed(t *testing.T) {
+	errPrimary := errors.New("primary down")
+	errSecondary := errors.New("secondary down")
+	client := newTestFailover(3, time.Minute,
+		&stubClient{name: "gemini", err: errPrimary},
+		&stubClient{name: "groq", err: errSecondary},
+	)
+
+	result, err := client.Chat(context.Background(), nil)
+	if !errors.Is(err, errPrimary) || !errors.Is(err, errSecondary) {
+		t.Fatalf("expected both provider errors, got %v", err)
+	}
+	if result.Provider != "groq" {
+		t.Fatalf("expected last attempted provider, got %s", result.Provider)
+	}
+}
+
+// TestFailoverClientBreaker проверяет отключение провайдера после серии ошибок
+// и пробную попытку после паузы.
+func TestFailoverClientBreaker(t *testing.T) {
+	primary := &stubClient{name: "gemini", err: errors.New("unavailable")}
+	secondary := &stubClient{name: "groq"}
+	client := newTestFailover(2, time.Minute, primary, secondary)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Chat(context.Background(), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if primary.calls != 2 {
+		t.Fatalf("expected primary to be skipped after 2 failures, got %d calls", primary.calls)
+	}
+
+	now = now.Add(time.Minute)
+	primary.err = nil
+
+	result, err := client.Chat(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "gemini" || primary.calls != 3 {
+		t.Fatalf("expected probe to reach primary, got %s after %d calls", result.Provider, primary.calls)
+	}
+}
+
+// TestFailoverClientNoProviders проверяет ответ, когда все провайдеры отключены.
+func TestFailoverClientNoProviders(t *testing.T) {
+	client := newTestFailover(1, time.Minute, &stubClient{name: "gemini", err: errors.New("unavailable")})
+
+	_, _ = client.Chat(context.Background(), nil)
+	if _, err := client.Chat(context.Background(), nil); !errors.Is(err, ErrNoProviders) {
+		t.Fatalf("expected ErrNoProviders, got %v", err)
+	}
+}
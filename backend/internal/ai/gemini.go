@@ -66,9 +66,11 @@ func NewGeminiClient(apiKey, baseURL, model string, timeout time.Duration, maxTo
 }
 
 // Chat отправляет сообщения в Gemini и возвращает текст ответа и сырой ответ API.
-func (c *GeminiClient) Chat(ctx context.Context, messages []Message) (string, []byte, error) {
+func (c *GeminiClient) Chat(ctx context.Context, messages []Message) (ChatResult, error) {
+	result := ChatResult{Provider: ProviderGemini, Model: c.model}
+
 	if strings.TrimSpace(c.apiKey) == "" {
-		return "", nil, errors.New("gemini api key is missing")
+		return result, errors.New("gemini api key is missing")
 	}
 
 	systemParts := make([]geminiPart, 0)
@@ -92,7 +94,7 @@ func (c *GeminiClient) Chat(ctx context.Context, messages []Message) (string, []
 	}
 
 	if len(contents) == 0 {
-		return "", nil, errors.New("gemini request has no user content")
+		return result, errors.New("gemini request has no user content")
 	}
 
 	request := geminiRequest{
@@ -110,47 +112,48 @@ func (c *GeminiClient) Chat(ctx context.Context, messages []Message) (string, []
 
 	payload, err := json.Marshal(request)
 	if err != nil {
-		return "", nil, err
+		return result, err
 	}
 
 	endpoint := fmt.Sprintf("%s/models/%s:generateContent?key=%s", c.baseURL, c.model, c.apiKey)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
 	if err != nil {
-		return "", nil, err
+		return result, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	response, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", nil, err
+		return result, err
 	}
 	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return "", nil, err
+		return result, err
 	}
+	result.Raw = body
 
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
 		var apiErr geminiResponse
 		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error != nil {
-			return "", body, fmt.Errorf("gemini api error: %s", apiErr.Error.Message)
+			return result, fmt.Errorf("gemini api error: %s", apiErr.Error.Message)
 		}
-		return "", body, fmt.Errorf("gemini api error: %s", strings.TrimSpace(string(body)))
+		return result, fmt.Errorf("gemini api error: %s", strings.TrimSpace(string(body)))
 	}
 
 	var parsed geminiResponse
 	if err := json.Unmarshal(body, &parsed); err != nil {
-		return "", body, err
+		return result, err
 	}
 
 	if len(parsed.Candidates) == 0 {
-		return "", body, errors.New("gemini response missing candidates")
+		return result, errors.New("gemini response missing candidates")
 	}
 
 	parts := parsed.Candidates[0].Content.Parts
 	if len(parts) == 0 {
-		return "", body, errors.New("gemini response missing content")
+		return result, errors.New("gemini response missing content")
 	}
 
 	var builder strings.Builder
@@ -158,5 +161,6 @@ func (c *GeminiClient) Chat(ctx context.Context, messages []Message) (string, []
 		builder.WriteString(part.Text)
 	}
 
-	return builder.String(), body, nil
+	result.Content = builder.String()
+	return result, nil
 }
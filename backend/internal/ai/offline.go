@@ -0,0 +1,163 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Доли бюджета по правилу 50/30/20: обязательные расходы, желания, накопления.
+const (
+	needsPercent   = 50
+	wantsPercent   = 30
+	savingsPercent = 20
+)
+
+// OfflinePlan составляет план без модели по правилу 50/30/20: обязательные
+// расходы из анкеты получают половину бюджета, необязательные — 30%,
+// остаток уходит в накопления. Если обязательные расходы больше своей доли,
+// остаток делится между желаниями и накоплениями в пропорции 30:20.
+// Результат детерминирован и укладывается в budget_cents.
+func OfflinePlan(input GeneratePlanInput) PlanResponse {
+	budget := max(input.BudgetCents, 0)
+	notes := []Note{{
+		Content: fmt.Sprintf("План составлен без AI по правилу %d/%d/%d: обязательные расходы, желания и накопления.",
+			needsPercent, wantsPercent, savingsPercent),
+		Type: noteTypeAI,
+	}}
+
+	needsTarget := budget * needsPercent / 100
+	mandatory := offlineItems(input.UserData.MandatoryExpenses, priorityRed)
+	if len(mandatory) == 0 {
+		mandatory = []Item{{Title: "Обязательные расходы", AmountCents: needsTarget, Priority: priorityRed}}
+	}
+
+	needs := sumItems(mandatory)
+	if needs > budget {
+		scaleItems(mandatory, needs, budget)
+		needs = sumItems(mandatory)
+		notes = append(notes, Note{
+			Content: fmt.Sprintf("Обязательные расходы больше бюджета (%s), суммы уменьшены пропорционально.",
+				formatCents(budget, input.Currency)),
+			Type: noteTypeAI,
+		})
+	} else if needs > needsTarget {
+		notes = append(notes, Note{
+			Content: fmt.Sprintf("Обязательные расходы превышают %d%% бюджета, доли желаний и накоплений уменьшены.", needsPercent),
+			Type:    noteTypeAI,
+		})
+	}
+
+	remaining := budget - needs
+	wants := budget * wantsPercent / 100
+	if remaining < budget-needsTarget {
+		wants = remaining * wantsPercent / (wantsPercent + savingsPercent)
+	}
+
+	optional := offlineItems(input.UserData.OptionalExpenses, priorityYellow)
+	if len(optional) == 0 {
+		optional = []Item{{Title: "Необязательные расходы", AmountCents: wants, Priority: priorityYellow}}
+	}
+
+	if requested := sumItems(optional); requested > wants {
+		scaleItems(optional, requested, wants)
+		notes = append(notes, Note{
+			Content: fmt.Sprintf("Необязательные расходы сокращены до %s.", formatCents(wants, input.Currency)),
+			Type:    noteTypeAI,
+		})
+	}
+
+	savings := remaining - sumItems(optional)
+	savingsItems := []Item{
+		{Title: "Резервный фонд", AmountCents: savings - savings/2, Priority: priorityGreen},
+		{Title: "Накопления", AmountCents: savings / 2, Priority: priorityGreen},
+	}
+	if len(input.UserData.Debts) > 0 && savings > 1 {
+		savingsItems[1].Title = "Досрочное погашение долгов"
+		notes = append(notes, Note{Content: "Половина накоплений направлена на досрочное погашение долгов.", Type: noteTypeAI})
+	}
+
+	plan := Plan{
+		Title: fmt.Sprintf("Бюджетный план %s - %s", input.PeriodStart, input.PeriodEnd),
+		Categories: []Category{
+			{Title: "Обязательные расходы", Type: mandatoryType, Items: positiveItems(mandatory)},
+			{Title: "Желания", Type: optionalType, Items: positiveItems(optional)},
+			{Title: "Накопления", Type: mandatoryType, Items: positiveItems(savingsItems)},
+		},
+	}
+
+	categories := plan.Categories[:0]
+	for _, category := range plan.Categories {
+		if len(category.Items) > 0 {
+			categories = append(categories, category)
+		}
+	}
+	plan.Categories = categories
+
+	for _, adjustment := range rebalancePlan(&plan, budget) {
+		notes = append(notes, Note{Content: adjustment, Type: noteTypeAI})
+	}
+	plan.Notes = notes
+
+	return PlanResponse{Plan: plan}
+}
+
+// offlineItems превращает расходы из анкеты в расходы плана, пропуская пустые.
+func offlineItems(expenses []Expense, priority string) []Item {
+	items := make([]Item, 0, len(expenses))
+	for _, expense := range expenses {
+		title := strings.TrimSpace(expense.Title)
+		if title == "" || expense.AmountCents <= 0 {
+			continue
+		}
+		items = append(items, Item{Title: title, AmountCents: expense.AmountCents, Priority: priority})
+	}
+	return items
+}
+
+// scaleItems пропорционально уменьшает суммы с total до limit; остаток от
+// округления снимается с первых расходов, поэтому сумма не превышает limit.
+func scaleItems(items []Item, total, limit int64) {
+	if total <= 0 {
+		return
+	}
+
+	ratio := float64(limit) / float64(total)
+	var scaled int64
+	for i := range items {
+		items[i].AmountCents = int64(math.Floor(float64(items[i].AmountCents) * ratio))
+		scaled += items[i].AmountCents
+	}
+
+	for i := range items {
+		if scaled <= limit {
+			break
+		}
+		cut := min(scaled-limit, items[i].AmountCents)
+		items[i].AmountCents -= cut
+		scaled -= cut
+	}
+}
+
+func positiveItems(items []Item) []Item {
+	out := make([]Item, 0, len(items))
+	for _, item := range items {
+		if item.AmountCents > 0 {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func sumItems(items []Item) int64 {
+	var total int64
+	for _, item := range items {
+		total += item.AmountCents
+	}
+	return total
+}
+
+// formatCents форматирует сумму в копейках вида 1234.56 RUB.
+func formatCents(amount int64, currency string) string {
+	return strings.TrimSpace(fmt.Sprintf("%d.%02d %s", amount/100, amount%100, currency))
+}
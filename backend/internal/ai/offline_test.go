@@ -0,0 +1,95 @@
+package ai
+
+import "testing"
+
+func categoryTotals(plan Plan) map[string]int64 {
+	totals := make(map[string]int64, len(plan.Categories))
+	for _, category := range plan.Categories {
+		totals[category.Title] = sumItems(category.Items)
+	}
+	return totals
+}
+
+// TestOfflinePlanSplit проверяет разбиение 50/30/20 и перенос остатка обязательных в накопления.
+func TestOfflinePlanSplit(t *testing.T) {
+	plan := OfflinePlan(GeneratePlanInput{
+		PeriodStart: "2024-11-01",
+		PeriodEnd:   "2024-11-30",
+		BudgetCents: 100000,
+		Currency:    "RUB",
+		UserData: UserData{
+			MandatoryExpenses: []Expense{{Title: "Аренда", AmountCents: 30000}, {Title: "Связь", AmountCents: 5000}},
+			OptionalExpenses:  []Expense{{Title: "Кино", AmountCents: 10000}, {Title: " ", AmountCents: 500}},
+		},
+	}).Plan
+
+	if plan.Title != "Бюджетный план 2024-11-01 - 2024-11-30" {
+		t.Fatalf("unexpected title %q", plan.Title)
+	}
+
+	totals := categoryTotals(plan)
+	if totals["Обязательные расходы"] != 35000 || totals["Желания"] != 10000 || totals["Накопления"] != 55000 {
+		t.Fatalf("unexpected totals %v", totals)
+	}
+
+	if err := validatePlanResponse(PlanResponse{Plan: plan}, 100000); err != nil {
+		t.Fatalf("expected valid plan, got %v", err)
+	}
+}
+
+// TestOfflinePlanOverBudget проверяет уменьшение обязательных расходов, превышающих бюджет.
+func TestOfflinePlanOverBudget(t *testing.T) {
+	plan := OfflinePlan(GeneratePlanInput{
+		BudgetCents: 10000,
+		UserData: UserData{
+			MandatoryExpenses: []Expense{{Title: "Аренда", AmountCents: 15000}, {Title: "Кредит", AmountCents: 5000}},
+			OptionalExpenses:  []Expense{{Title: "Кино", AmountCents: 1000}},
+			Debts:             []Debt{{Title: "Кредит", AmountCents: 100000}},
+		},
+	}).Plan
+
+	totals := categoryTotals(plan)
+	if totals["Обязательные расходы"] != 10000 || len(totals) != 1 {
+		t.Fatalf("expected only mandatory expenses within budget, got %v", totals)
+	}
+	if len(plan.Notes) < 2 {
+		t.Fatalf("expected notes about adjustments, got %+v", plan.Notes)
+	}
+}
+
+// TestOfflinePlanWithoutExpenses проверяет шаблон по долям, когда анкета пуста.
+func TestOfflinePlanWithoutExpenses(t *testing.T) {
+	plan := OfflinePlan(GeneratePlanInput{BudgetCents: 100001}).Plan
+
+	totals := categoryTotals(plan)
+	if totals["Обязательные расходы"] != 50000 || totals["Желания"] != 30000 || totals["Накопления"] != 20001 {
+		t.Fatalf("unexpected totals %v", totals)
+	}
+	if err := validatePlanResponse(PlanResponse{Plan: plan}, 100001); err != nil {
+		t.Fatalf("expected valid plan, got %v", err)
+	}
+}
+
+// TestOfflinePlanManyExpenses проверяет, что план из длинной анкеты
+// укладывается в лимит расходов и проходит проверку.
+func TestOfflinePlanManyExpenses(t *testing.T) {
+	mandatory := make([]Expense, 0, maxPlanItems+1)
+	for i := 0; i <= maxPlanItems; i++ {
+		mandatory = append(mandatory, Expense{Title: "Платеж", AmountCents: 1000})
+	}
+
+	plan := OfflinePlan(GeneratePlanInput{
+		BudgetCents: 100000,
+		UserData: UserData{
+			MandatoryExpenses: mandatory,
+			OptionalExpenses:  []Expense{{Title: "Кино", AmountCents: 1000}},
+		},
+	}).Plan
+
+	if len(plan.Categories) != 1 || len(plan.Categories[0].Items) != maxPlanItems {
+		t.Fatalf("expected only clamped mandatory category, got %+v", plan.Categories)
+	}
+	if err := validatePlanResponse(PlanResponse{Plan: plan}, 100000); err != nil {
+		t.Fatalf("expected valid plan, got %v", err)
+	}
+}
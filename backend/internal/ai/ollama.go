@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OllamaClient обращается к локальному серверу Ollama через /api/chat.
+type OllamaClient struct {
+	baseURL    string
+	model      string
+	maxTokens  int
+	httpClient *http.Client
+}
+
+type ollamaChatRequest struct {
+	Model    string        `json:"model"`
+	Messages []Message     `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format,omitempty"`
+	Options  ollamaOptions `json:"options"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Message *Message `json:"message,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// NewOllamaClient создает клиент Ollama. Ключ API не нужен: сервер обычно
+// работает в той же сети.
+func NewOllamaClient(baseURL, model string, timeout time.Duration, maxTokens int) *OllamaClient {
+	return &OllamaClient{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		model:     model,
+		maxTokens: maxTokens,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Chat отправляет сообщения в Ollama и возвращает текст ответа и сырой ответ API.
+func (c *OllamaClient) Chat(ctx context.Context, messages []Message) (ChatResult, error) {
+	result := ChatResult{Provider: ProviderOllama, Model: c.model}
+
+	reqBody := ollamaChatRequest{
+		Model:    c.model,
+		Messages: messages,
+		Format:   "json",
+		Options: ollamaOptions{
+			Temperature: 0.2,
+			NumPredict:  resolveMaxTokens(c.maxTokens),
+		},
+	}
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return result, err
+	}
+
+	endpoint := fmt.Sprintf("%s/api/chat", c.baseURL)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return result, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return result, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return result, err
+	}
+	result.Raw = body
+
+	var parsed ollamaChatResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return result, fmt.Errorf("ollama api error: %s", strings.TrimSpace(string(body)))
+		}
+		return result, err
+	}
+
+	if parsed.Error != "" {
+		return result, fmt.Errorf("ollama api error: %s", parsed.Error)
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return result, fmt.Errorf("ollama api error: %s", strings.TrimSpace(string(body)))
+	}
+
+	if parsed.Message == nil {
+		return result, errors.New("ollama response missing message")
+	}
+
+	result.Content = parsed.Message.Content
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestOllamaClientChat проверяет запрос к /api/chat и разбор ответа Ollama.
+func TestOllamaClientChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		var body ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if body.Model != "llama3.1" || body.Stream || body.Format != "json" || body.Options.NumPredict != 512 {
+			t.Errorf("unexpected request %+v", body)
+		}
+
+		_, _ = w.Write([]byte(`{"model":"llama3.1","message":{"role":"assistant","content":"{\"ok\":true}"},"done":true}`))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL+"/", "llama3.1", time.Second, 512)
+
+	result, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content != `{"ok":true}` || result.Provider != ProviderOllama || result.Model != "llama3.1" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+// TestOllamaClientError проверяет разбор ошибки Ollama, например неизвестной модели.
+func TestOllamaClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model \"missing\" not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "missing", time.Second, 0)
+
+	_, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}})
+	if err == nil || err.Error() != `ollama api error: model "missing" not found, try pulling it first` {
+		t.Fatalf("expected api error, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient обращается к API chat completions в формате OpenAI: к самому
+// OpenAI, Groq и другим совместимым сервисам.
+type OpenAIClient struct {
+	provider      string
+	apiKey        string
+	baseURL       string
+	model         string
+	maxTokens     int
+	organization  string
+	headers       map[string]string
+	jsonResponse  bool
+	requireAPIKey bool
+	httpClient    *http.Client
+}
+
+// OpenAIOption настраивает OpenAIClient.
+type OpenAIOption func(*OpenAIClient)
+
+// WithProviderName задает имя провайдера для логов AI-запросов; по умолчанию openai.
+func WithProviderName(name string) OpenAIOption {
+	return func(c *OpenAIClient) {
+		c.provider = name
+	}
+}
+
+// WithOrganization передает идентификатор организации в заголовке OpenAI-Organization.
+func WithOrganization(organization string) OpenAIOption {
+	return func(c *OpenAIClient) {
+		c.organization = organization
+	}
+}
+
+// WithHeaders добавляет произвольные заголовки к каждому запросу.
+func WithHeaders(headers map[string]string) OpenAIOption {
+	return func(c *OpenAIClient) {
+		for name, value := range headers {
+			c.headers[name] = value
+		}
+	}
+}
+
+// WithJSONResponse просит модель отвечать JSON-объектом (response_format json_object).
+func WithJSONResponse() OpenAIOption {
+	return func(c *OpenAIClient) {
+		c.jsonResponse = true
+	}
+}
+
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []Message             `json:"messages"`
+	Temperature    float64               `json:"temperature,omitempty"`
+	MaxTokens      int                   `json:"max_tokens,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewOpenAIClient создает клиент OpenAI-совместимого API. Ключ может быть
+// пустым, если сервис его не требует.
+func NewOpenAIClient(apiKey, baseURL, model string, timeout time.Duration, maxTokens int, opts ...OpenAIOption) *OpenAIClient {
+	client := &OpenAIClient{
+		provider:  ProviderOpenAI,
+		apiKey:    apiKey,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		model:     model,
+		maxTokens: maxTokens,
+		headers:   make(map[string]string),
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// NewGroqClient создает клиент Groq: OpenAI-совместимый API с обязательным ключом.
+func NewGroqClient(apiKey, baseURL, model string, timeout time.Duration, maxTokens int, opts ...OpenAIOption) *OpenAIClient {
+	client := NewOpenAIClient(apiKey, baseURL, model, timeout, maxTokens, append([]OpenAIOption{WithProviderName(ProviderGroq)}, opts...)...)
+	client.requireAPIKey = true
+	return client
+}
+
+// Chat отправляет сообщения в chat completions и возвращает текст ответа и сырой ответ API.
+func (c *OpenAIClient) Chat(ctx context.Context, messages []Message) (ChatResult, error) {
+	result := ChatResult{Provider: c.provider, Model: c.model}
+
+	if c.requireAPIKey && strings.TrimSpace(c.apiKey) == "" {
+		return result, fmt.Errorf("%s api key is missing", c.provider)
+	}
+
+	reqBody := openAIChatRequest{
+		Model:       c.model,
+		Messages:    messages,
+		Temperature: 0.2,
+		MaxTokens:   resolveMaxTokens(c.maxTokens),
+	}
+	if c.jsonResponse {
+		reqBody.ResponseFormat = &openAIResponseFormat{Type: "json_object"}
+	}
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return result, err
+	}
+
+	endpoint := fmt.Sprintf("%s/chat/completions", c.baseURL)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return result, err
+	}
+
+	for name, value := range c.headers {
+		request.Header.Set(name, value)
+	}
+	if c.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if c.organization != "" {
+		request.Header.Set("OpenAI-Organization", c.organization)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return result, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return result, err
+	}
+	result.Raw = body
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		var apiErr openAIChatResponse
+		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error != nil {
+			return result, fmt.Errorf("%s api error: %s", c.provider, apiErr.Error.Message)
+		}
+		return result, fmt.Errorf("%s api error: %s", c.provider, strings.TrimSpace(string(body)))
+	}
+
+	var parsed openAIChatResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return result, err
+	}
+
+	if len(parsed.Choices) == 0 {
+		return result, errors.New(c.provider + " response missing choices")
+	}
+
+	result.Content = parsed.Choices[0].Message.Content
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestOpenAIClientChat проверяет запрос к OpenAI-совместимому API и разбор ответа.
+func TestOpenAIClientChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		if got := r.Header.Get("OpenAI-Organization"); got != "org-1" {
+			t.Errorf("unexpected organization %q", got)
+		}
+		if got := r.Header.Get("X-Title"); got != "Budget Planner" {
+			t.Errorf("unexpected custom header %q", got)
+		}
+
+		var body openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if body.Model != "gpt-4o-mini" || body.ResponseFormat == nil || body.ResponseFormat.Type != "json_object" {
+			t.Errorf("unexpected request %+v", body)
+		}
+
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"ok\":true}"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("secret", server.URL+"/v1/", "gpt-4o-mini", time.Second, 0,
+		WithOrganization("org-1"),
+		WithHeaders(map[string]string{"X-Title": "Budget Planner"}),
+		WithJSONResponse(),
+	)
+
+	result, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content != `{"ok":true}` || result.Provider != ProviderOpenAI || result.Model != "gpt-4o-mini" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+// TestOpenAIClientError проверяет разбор ошибки API и имя провайдера в ней.
+func TestOpenAIClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("expected no authorization without key, got %q", got)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"rate limit"}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("", server.URL, "local-model", time.Second, 0, WithProviderName("vllm"))
+
+	result, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}})
+	if err == nil || err.Error() != "vllm api error: rate limit" {
+		t.Fatalf("expected api error, got %v", err)
+	}
+	if !strings.Contains(string(result.Raw), "rate limit") {
+		t.Fatalf("expected raw response, got %q", result.Raw)
+	}
+}
+
+// TestGroqClientRequiresKey проверяет, что Groq без ключа не вызывается.
+func TestGroqClientRequiresKey(t *testing.T) {
+	client := NewGroqClient("", "http://127.0.0.1:0", "llama", time.Second, 0)
+
+	result, err := client.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}})
+	if err == nil || err.Error() != "groq api key is missing" {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+	if result.Provider != ProviderGroq {
+		t.Fatalf("expected groq provider, got %s", result.Provider)
+	}
+}
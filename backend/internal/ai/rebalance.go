@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxPlanCategories   = 6
+	maxPlanItems        = 20
+	maxPlanTitleLen     = 200
+	maxCategoryTitleLen = 100
+	maxItemTitleLen     = 200
+)
+
+// prioritySynonyms переводит приоритеты, которые модели пишут словами, в цвета.
+var prioritySynonyms = map[string]string{
+	"high":     priorityRed,
+	"critical": priorityRed,
+	"medium":   priorityYellow,
+	"normal":   priorityYellow,
+	"low":      priorityGreen,
+}
+
+// rebalancePlan исправляет в плане то, что можно исправить без модели:
+// приводит приоритеты к допустимым, сокращает названия, отбрасывает лишние
+// категории и расходы (и категории, оставшиеся без расходов) и
+// пропорционально уменьшает суммы до бюджета.
+// Возвращает описание каждой правки для заметки к плану.
+func rebalancePlan(plan *Plan, budgetCents int64) []string {
+	adjustments := make([]string, 0)
+
+	if title, ok := truncateTitle(plan.Title, maxPlanTitleLen); ok {
+		adjustments = append(adjustments, fmt.Sprintf("Название плана сокращено до %d символов.", utf8.RuneCountInString(title)))
+		plan.Title = title
+	}
+
+	if len(plan.Categories) > maxPlanCategories {
+		dropped := make([]string, 0, len(plan.Categories)-maxPlanCategories)
+		for _, category := range plan.Categories[maxPlanCategories:] {
+			dropped = append(dropped, "«"+strings.TrimSpace(category.Title)+"»")
+		}
+		adjustments = append(adjustments, fmt.Sprintf("Категорий было %d, оставлены первые %d; отброшены: %s.",
+			len(plan.Categories), maxPlanCategories, strings.Join(dropped, ", ")))
+		plan.Categories = plan.Categories[:maxPlanCategories]
+	}
+
+	itemCount := 0
+	kept := plan.Categories[:0]
+	overflow := make([]string, 0)
+	for i := range plan.Categories {
+		category := plan.Categories[i]
+		category.Type = strings.ToLower(strings.TrimSpace(category.Type))
+
+		if title, ok := truncateTitle(category.Title, maxCategoryTitleLen); ok {
+			adjustments = append(adjustments, fmt.Sprintf("Название категории «%s» сокращено.", title))
+			category.Title = title
+		}
+
+		if remaining := maxPlanItems - itemCount; len(category.Items) > remaining {
+			// Категория, в которой не осталось ни одного расхода, отбрасывается целиком.
+			if remaining == 0 {
+				overflow = append(overflow, "«"+strings.TrimSpace(category.Title)+"»")
+				continue
+			}
+			adjustments = append(adjustments, fmt.Sprintf("Из категории «%s» убрано расходов: %d (в плане не больше %d расходов).",
+				category.Title, len(category.Items)-remaining, maxPlanItems))
+			category.Items = category.Items[:remaining]
+		}
+		itemCount += len(category.Items)
+
+		for j := range category.Items {
+			item := &category.Items[j]
+
+			if title, ok := truncateTitle(item.Title, maxItemTitleLen); ok {
+				adjustments = append(adjustments, fmt.Sprintf("Название расхода «%s» сокращено.", title))
+				item.Title = title
+			}
+
+			priority := normalizePriority(item.Priority)
+			if priority != strings.ToLower(strings.TrimSpace(item.Priority)) {
+				adjustments = append(adjustments, fmt.Sprintf("Приоритет расхода «%s» «%s» заменен на «%s».",
+					item.Title, item.Priority, priority))
+			}
+			item.Priority = priority
+		}
+
+		kept = append(kept, category)
+	}
+	plan.Categories = kept
+
+	if len(overflow) > 0 {
+		adjustments = append(adjustments, fmt.Sprintf("В плане не больше %d расходов, отброшены категории: %s.",
+			maxPlanItems, strings.Join(overflow, ", ")))
+	}
+
+	if adjustment, ok := scaleToBudget(plan.Categories, budgetCents); ok {
+		adjustments = append(adjustments, adjustment)
+	}
+
+	return adjustments
+}
+
+// scaleToBudget пропорционально уменьшает суммы расходов, если их сумма больше
+// бюджета. Остаток от округления снимается с самых крупных расходов.
+func scaleToBudget(categories []Category, budgetCents int64) (string, bool) {
+	var total int64
+	for _, category := range categories {
+		for _, item := range category.Items {
+			if item.AmountCents > 0 {
+				total += item.AmountCents
+			}
+		}
+	}
+
+	if budgetCents <= 0 || total <= budgetCents {
+		return "", false
+	}
+
+	ratio := float64(budgetCents) / float64(total)
+	var scaled int64
+	for i := range categories {
+		for j := range categories[i].Items {
+			item := &categories[i].Items[j]
+			if item.AmountCents <= 0 {
+				continue
+			}
+			item.AmountCents = max(1, int64(math.Floor(float64(item.AmountCents)*ratio)))
+			scaled += item.AmountCents
+		}
+	}
+
+	for scaled > budgetCents {
+		largest := largestItem(categories)
+		if largest == nil || largest.AmountCents <= 1 {
+			break
+		}
+		cut := min(scaled-budgetCents, largest.AmountCents-1)
+		largest.AmountCents -= cut
+		scaled -= cut
+	}
+
+	return fmt.Sprintf("Сумма расходов превышала бюджет на %d%%, все суммы уменьшены пропорционально.",
+		int64(math.Ceil(float64(total-budgetCents)*100/float64(budgetCents)))), true
+}
+
+func largestItem(categories []Category) *Item {
+	var largest *Item
+	for i := range categories {
+		for j := range categories[i].Items {
+			item := &categories[i].Items[j]
+			if largest == nil || item.AmountCents > largest.AmountCents {
+				largest = item
+			}
+		}
+	}
+	return largest
+}
+
+// normalizePriority приводит приоритет к red, yellow или green; неизвестный
+// приоритет считается средним.
+func normalizePriority(value string) string {
+	priority := strings.ToLower(strings.TrimSpace(value))
+	if isPriority(priority) {
+		return priority
+	}
+	if mapped, ok := prioritySynonyms[priority]; ok {
+		return mapped
+	}
+	return priorityYellow
+}
+
+// truncateTitle обрезает название до maxBytes байт по границе символа.
+// Второе значение сообщает, было ли название сокращено.
+func truncateTitle(value string, maxBytes int) (string, bool) {
+	if len(value) <= maxBytes {
+		return value, false
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+
+	return strings.TrimSpace(value[:cut]), true
+}
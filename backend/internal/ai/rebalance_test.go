@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func testItems(amounts ...int64) []Item {
+	items := make([]Item, 0, len(amounts))
+	for _, amount := range amounts {
+		items = append(items, Item{Title: "Расход", AmountCents: amount, Priority: priorityRed})
+	}
+	return items
+}
+
+// TestRebalancePlanScalesToBudget проверяет пропорциональное уменьшение сумм до бюджета.
+func TestRebalancePlanScalesToBudget(t *testing.T) {
+	plan := Plan{Title: "План", Categories: []Category{
+		{Title: "Обязательные", Type: mandatoryType, Items: testItems(6000, 3000)},
+		{Title: "Прочие", Type: optionalType, Items: testItems(1000, 1)},
+	}}
+
+	adjustments := rebalancePlan(&plan, 7001)
+	if len(adjustments) != 1 {
+		t.Fatalf("expected 1 adjustment, got %v", adjustments)
+	}
+
+	var total int64
+	for _, category := range plan.Categories {
+		for _, item := range category.Items {
+			if item.AmountCents <= 0 {
+				t.Fatalf("expected positive amounts, got %d", item.AmountCents)
+			}
+			total += item.AmountCents
+		}
+	}
+	if total > 7001 {
+		t.Fatalf("expected total within budget, got %d", total)
+	}
+	if got := plan.Categories[0].Items[0].AmountCents; got != 4200 {
+		t.Fatalf("expected 4200, got %d", got)
+	}
+}
+
+// TestRebalancePlanWithinBudget проверяет, что корректный план не меняется.
+func TestRebalancePlanWithinBudget(t *testing.T) {
+	plan := Plan{Title: "План", Categories: []Category{
+		{Title: "Обязательные", Type: mandatoryType, Items: testItems(1000, 2000)},
+	}}
+
+	if adjustments := rebalancePlan(&plan, 3000); len(adjustments) != 0 {
+		t.Fatalf("expected no adjustments, got %v", adjustments)
+	}
+}
+
+// TestRebalancePlanClampsAndNormalizes проверяет отбрасывание лишних категорий,
+// сокращение названий и замену приоритетов.
+func TestRebalancePlanClampsAndNormalizes(t *testing.T) {
+	categories := make([]Category, 0, maxPlanCategories+1)
+	for i := 0; i <= maxPlanCategories; i++ {
+		categories = append(categories, Category{Title: "Категория", Type: " Optional ", Items: testItems(100)})
+	}
+	categories[0].Title = strings.Repeat("я", maxCategoryTitleLen)
+	categories[0].Items[0].Priority = "High"
+	categories[1].Items[0].Priority = "urgent"
+	categories[2].Items[0].Priority = " GREEN "
+
+	plan := Plan{Title: "План", Categories: categories}
+	adjustments := rebalancePlan(&plan, 10000)
+
+	if len(plan.Categories) != maxPlanCategories {
+		t.Fatalf("expected %d categories, got %d", maxPlanCategories, len(plan.Categories))
+	}
+	if got := plan.Categories[0].Title; len(got) > maxCategoryTitleLen || !strings.HasPrefix(got, "яя") {
+		t.Fatalf("expected title truncated on rune boundary, got %q", got)
+	}
+	if plan.Categories[1].Type != optionalType {
+		t.Fatalf("expected normalized category type, got %q", plan.Categories[1].Type)
+	}
+
+	priorities := []string{plan.Categories[0].Items[0].Priority, plan.Categories[1].Items[0].Priority, plan.Categories[2].Items[0].Priority}
+	if priorities[0] != priorityRed || priorities[1] != priorityYellow || priorities[2] != priorityGreen {
+		t.Fatalf("unexpected priorities %v", priorities)
+	}
+
+	// Категории, название и два приоритета; регистр приоритета правится молча.
+	if len(adjustments) != 4 {
+		t.Fatalf("expected 4 adjustments, got %v", adjustments)
+	}
+}
+
+// TestRebalancePlanDropsOverflowCategories проверяет, что категории, в которых
+// после ограничения числа расходов ничего не осталось, отбрасываются, а план
+// проходит проверку.
+func TestRebalancePlanDropsOverflowCategories(t *testing.T) {
+	amounts := func(count int) []int64 {
+		values := make([]int64, count)
+		for i := range values {
+			values[i] = 100
+		}
+		return values
+	}
+
+	plan := Plan{Title: "План", Categories: []Category{
+		{Title: "Жилье", Type: mandatoryType, Items: testItems(amounts(15)...)},
+		{Title: "Еда", Type: mandatoryType, Items: testItems(amounts(6)...)},
+		{Title: "Досуг", Type: optionalType, Items: testItems(amounts(2)...)},
+	}}
+
+	adjustments := rebalancePlan(&plan, 10000)
+
+	if len(plan.Categories) != 2 || plan.Categories[1].Title != "Еда" || len(plan.Categories[1].Items) != 5 {
+		t.Fatalf("expected overflow category dropped, got %+v", plan.Categories)
+	}
+	if err := validatePlanResponse(PlanResponse{Plan: plan}, 10000); err != nil {
+		t.Fatalf("expected valid plan, got %v", err)
+	}
+	if len(adjustments) != 2 || !strings.Contains(adjustments[1], "«Досуг»") {
+		t.Fatalf("expected dropped category in adjustments, got %v", adjustments)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 const (
@@ -18,73 +19,145 @@ const (
 	noteTypeUser   = "user"
 )
 
+const systemPrompt = "You are a budgeting assistant. Respond with JSON only, without extra text."
+
+// ErrNotConfigured возвращается, если у сервиса нет ни одного провайдера AI.
+var ErrNotConfigured = errors.New("no ai providers configured")
+
 type Service struct {
-	client Client
+	client         Client
+	repairAttempts int
+	timeout        time.Duration
 }
 
-// NewService создает сервис работы с AI-клиентом.
-func NewService(client Client) *Service {
-	return &Service{client: client}
+// Attempt — одно обращение к модели: промпт последнего сообщения (исходный
+// запрос или просьба исправить ответ), ответ и ошибка запроса, разбора или проверки.
+type Attempt struct {
+	Prompt string
+	Result ChatResult
+	Err    error
 }
 
-// GeneratePlan запрашивает у AI план бюджета и валидирует ответ.
-func (s *Service) GeneratePlan(ctx context.Context, input GeneratePlanInput) (PlanResponse, string, []byte, error) {
-	prompt, err := buildGeneratePlanPrompt(input)
-	if err != nil {
-		return PlanResponse{}, "", nil, err
-	}
+// NewService создает сервис работы с AI-клиентом. repairAttempts — сколько раз
+// модели можно вернуть ответ, не прошедший проверку, с просьбой его исправить;
+// отрицательное значение считается нулем. timeout ограничивает весь запрос
+// вместе с исправлениями; 0 — без отдельного ограничения. Без клиента (nil)
+// планы составляются по правилу 50/30/20, см. OfflinePlan.
+func NewService(client Client, repairAttempts int, timeout time.Duration) *Service {
+	return &Service{client: client, repairAttempts: max(repairAttempts, 0), timeout: timeout}
+}
 
-	messages := []Message{
-		{Role: "system", Content: "You are a budgeting assistant. Respond with JSON only, without extra text."},
-		{Role: "user", Content: prompt},
+// Enabled сообщает, настроен ли провайдер AI.
+func (s *Service) Enabled() bool {
+	return s.client != nil
+}
+
+// GeneratePlan запрашивает у AI план бюджета, исправляет в ответе то, что
+// можно исправить без модели (см. rebalancePlan), и валидирует его. Возвращает
+// все попытки, включая исправления; последняя соответствует итоговому ответу.
+// Если провайдеры не настроены, сразу возвращает OfflinePlan без попыток.
+func (s *Service) GeneratePlan(ctx context.Context, input GeneratePlanInput) (PlanResponse, []Attempt, error) {
+	if !s.Enabled() {
+		return OfflinePlan(input), nil, nil
 	}
 
-	content, raw, err := s.client.Chat(ctx, messages)
+	prompt, err := buildGeneratePlanPrompt(input)
 	if err != nil {
-		return PlanResponse{}, prompt, raw, err
+		return PlanResponse{}, nil, err
 	}
 
 	var response PlanResponse
-	if err := parseJSON(content, &response); err != nil {
-		return PlanResponse{}, prompt, raw, err
-	}
+	attempts, err := s.chatJSON(ctx, prompt, func(content string) error {
+		response = PlanResponse{}
+		if err := parseJSON(content, &response); err != nil {
+			return err
+		}
 
-	normalizePlanResponse(&response)
-	if err := validatePlanResponse(response, input.BudgetCents); err != nil {
-		return PlanResponse{}, prompt, raw, err
+		normalizePlanResponse(&response)
+		for _, adjustment := range rebalancePlan(&response.Plan, input.BudgetCents) {
+			response.Plan.Notes = append(response.Plan.Notes, Note{Content: adjustment, Type: noteTypeAI})
+		}
+		return validatePlanResponse(response, input.BudgetCents)
+	})
+	if err != nil {
+		return PlanResponse{}, attempts, err
 	}
 
-	return response, prompt, raw, nil
+	return response, attempts, nil
 }
 
-// AnalyzeSpending запрашивает у AI рекомендации по расходам.
-func (s *Service) AnalyzeSpending(ctx context.Context, input AnalyzeSpendingInput) (AdviceResponse, string, []byte, error) {
+// AnalyzeSpending запрашивает у AI рекомендации по расходам. Если провайдеры
+// не настроены, возвращает ErrNotConfigured.
+func (s *Service) AnalyzeSpending(ctx context.Context, input AnalyzeSpendingInput) (AdviceResponse, []Attempt, error) {
+	if !s.Enabled() {
+		return AdviceResponse{}, nil, ErrNotConfigured
+	}
+
 	prompt, err := buildAnalyzePrompt(input)
 	if err != nil {
-		return AdviceResponse{}, "", nil, err
+		return AdviceResponse{}, nil, err
 	}
 
-	messages := []Message{
-		{Role: "system", Content: "You are a budgeting assistant. Respond with JSON only, without extra text."},
-		{Role: "user", Content: prompt},
-	}
+	var response AdviceResponse
+	attempts, err := s.chatJSON(ctx, prompt, func(content string) error {
+		response = AdviceResponse{}
+		if err := parseJSON(content, &response); err != nil {
+			return err
+		}
 
-	content, raw, err := s.client.Chat(ctx, messages)
+		normalizeAdviceResponse(&response)
+		return validateAdviceResponse(response)
+	})
 	if err != nil {
-		return AdviceResponse{}, prompt, raw, err
+		return AdviceResponse{}, attempts, err
 	}
 
-	var response AdviceResponse
-	if err := parseJSON(content, &response); err != nil {
-		return AdviceResponse{}, prompt, raw, err
+	return response, attempts, nil
+}
+
+// chatJSON отправляет промпт и разбирает ответ через decode. Если ответ не
+// разобрался или не прошел проверку, модель получает ошибку и просьбу прислать
+// исправленный JSON — не больше repairAttempts раз. Ошибки самого запроса не
+// повторяются. Все обращения укладываются в timeout сервиса.
+func (s *Service) chatJSON(ctx context.Context, prompt string, decode func(content string) error) ([]Attempt, error) {
+	messages := []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: prompt},
+	}
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
 	}
+	attempts := make([]Attempt, 0, s.repairAttempts+1)
+
+	for {
+		result, err := s.client.Chat(ctx, messages)
+		if err != nil {
+			attempts = append(attempts, Attempt{Prompt: prompt, Result: result, Err: err})
+			return attempts, err
+		}
 
-	normalizeAdviceResponse(&response)
-	if err := validateAdviceResponse(response); err != nil {
-		return AdviceResponse{}, prompt, raw, err
+		err = decode(result.Content)
+		attempts = append(attempts, Attempt{Prompt: prompt, Result: result, Err: err})
+		if err == nil || len(attempts) > s.repairAttempts {
+			return attempts, err
+		}
+
+		prompt = buildRepairPrompt(err)
+		messages = append(messages,
+			Message{Role: "assistant", Content: result.Content},
+			Message{Role: "user", Content: prompt},
+		)
 	}
+}
+
+func buildRepairPrompt(err error) string {
+	return fmt.Sprintf(`Your previous answer was rejected: %s.
 
-	return response, prompt, raw, nil
+Fix the problem and return the whole corrected JSON.
+Follow all requirements and the schema from the first message.
+Output JSON only, no code fences, no extra text.`, err.Error())
 }
 
 func buildGeneratePlanPrompt(input GeneratePlanInput) (string, error) {
@@ -200,14 +273,14 @@ func validatePlanResponse(response PlanResponse, budgetCents int64) error {
 	if strings.TrimSpace(response.Plan.Title) == "" {
 		return errors.New("plan title is required")
 	}
-	if len(response.Plan.Title) > 200 {
+	if len(response.Plan.Title) > maxPlanTitleLen {
 		return errors.New("plan title is too long")
 	}
 
 	if len(response.Plan.Categories) == 0 {
 		return errors.New("plan categories are required")
 	}
-	if len(response.Plan.Categories) > 6 {
+	if len(response.Plan.Categories) > maxPlanCategories {
 		return errors.New("too many categories")
 	}
 
@@ -218,7 +291,7 @@ func validatePlanResponse(response PlanResponse, budgetCents int64) error {
 		if strings.TrimSpace(category.Title) == "" {
 			return errors.New("category title is required")
 		}
-		if len(category.Title) > 100 {
+		if len(category.Title) > maxCategoryTitleLen {
 			return errors.New("category title is too long")
 		}
 		if !isCategoryType(category.Type) {
@@ -233,7 +306,7 @@ func validatePlanResponse(response PlanResponse, budgetCents int64) error {
 			if strings.TrimSpace(item.Title) == "" {
 				return errors.New("item title is required")
 			}
-			if len(item.Title) > 200 {
+			if len(item.Title) > maxItemTitleLen {
 				return errors.New("item title is too long")
 			}
 			if item.AmountCents <= 0 {
@@ -249,7 +322,7 @@ func validatePlanResponse(response PlanResponse, budgetCents int64) error {
 	if itemCount < 4 {
 		return errors.New("not enough items")
 	}
-	if itemCount > 20 {
+	if itemCount > maxPlanItems {
 		return errors.New("too many items")
 	}
 
@@ -0,0 +1,162 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedClient отвечает заранее заданными ответами и запоминает переписку.
+type scriptedClient struct {
+	answers  []string
+	messages [][]Message
+}
+
+func (c *scriptedClient) Chat(_ context.Context, messages []Message) (ChatResult, error) {
+	c.messages = append(c.messages, append([]Message(nil), messages...))
+	answer := c.answers[len(c.messages)-1]
+	return ChatResult{Content: answer, Raw: []byte(answer), Provider: "stub", Model: "stub-model"}, nil
+}
+
+// planJSON возвращает план из четырех расходов по amount копеек каждый.
+func planJSON(amount string) string {
+	item := `{"title":"Расход","amount_cents":` + amount + `,"priority":"red"}`
+	items := `[` + item + `,` + item + `]`
+	return `{"plan":{"title":"План","categories":[` +
+		`{"title":"Обязательные","type":"mandatory","items":` + items + `},` +
+		`{"title":"Прочие","type":"optional","items":` + items + `}]}}`
+}
+
+var (
+	overBudgetPlan = planJSON("2000")
+	validPlan      = planJSON("1000")
+	// shortPlan нельзя исправить без модели: в нем только два расхода.
+	shortPlan = `{"plan":{"title":"План","categories":[{"title":"Жилье","type":"mandatory","items":[` +
+		`{"title":"Аренда","amount_cents":1000,"priority":"red"},{"title":"Свет","amount_cents":500,"priority":"red"}]}]}}`
+)
+
+// TestGeneratePlanRepairsInvalidAnswer проверяет, что ошибка проверки
+// отправляется модели и исправленный ответ принимается.
+func TestGeneratePlanRepairsInvalidAnswer(t *testing.T) {
+	client := &scriptedClient{answers: []string{shortPlan, validPlan}}
+	service := NewService(client, 2, 0)
+
+	response, attempts, err := service.GeneratePlan(context.Background(), GeneratePlanInput{BudgetCents: 5000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Plan.Categories[0].Items[0].AmountCents != 1000 {
+		t.Fatalf("expected repaired plan, got %+v", response)
+	}
+
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(attempts))
+	}
+	if attempts[0].Err == nil || attempts[1].Err != nil {
+		t.Fatalf("expected only first attempt to fail, got %v and %v", attempts[0].Err, attempts[1].Err)
+	}
+
+	followUp := client.messages[1]
+	if len(followUp) != 4 || followUp[2].Role != "assistant" || followUp[2].Content != shortPlan {
+		t.Fatalf("expected rejected answer in conversation, got %+v", followUp)
+	}
+	if !strings.Contains(followUp[3].Content, "not enough items") || attempts[1].Prompt != followUp[3].Content {
+		t.Fatalf("expected validation error in repair prompt, got %q", followUp[3].Content)
+	}
+}
+
+// TestGeneratePlanRepairLimit проверяет, что число исправлений ограничено.
+func TestGeneratePlanRepairLimit(t *testing.T) {
+	client := &scriptedClient{answers: []string{shortPlan, shortPlan, shortPlan}}
+	service := NewService(client, 1, 0)
+
+	_, attempts, err := service.GeneratePlan(context.Background(), GeneratePlanInput{BudgetCents: 5000})
+	if err == nil || !strings.Contains(err.Error(), "not enough items") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if len(attempts) != 2 || len(client.messages) != 2 {
+		t.Fatalf("expected 2 attempts, got %d attempts and %d calls", len(attempts), len(client.messages))
+	}
+}
+
+// TestGeneratePlanRebalancesBudget проверяет, что превышение бюджета
+// исправляется без повторного запроса и отмечается заметкой.
+func TestGeneratePlanRebalancesBudget(t *testing.T) {
+	client := &scriptedClient{answers: []string{overBudgetPlan}}
+	service := NewService(client, 2, 0)
+
+	response, attempts, err := service.GeneratePlan(context.Background(), GeneratePlanInput{BudgetCents: 5000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(attempts) != 1 {
+		t.Fatalf("expected 1 attempt, got %d", len(attempts))
+	}
+	if len(response.Plan.Notes) != 1 || response.Plan.Notes[0].Type != noteTypeAI {
+		t.Fatalf("expected rebalance note, got %+v", response.Plan.Notes)
+	}
+}
+
+// TestGeneratePlanNegativeRepairAttempts проверяет, что отрицательное число
+// исправлений не ломает сервис и означает один запрос без исправлений.
+func TestGeneratePlanNegativeRepairAttempts(t *testing.T) {
+	client := &scriptedClient{answers: []string{shortPlan}}
+	service := NewService(client, -2, 0)
+
+	_, attempts, err := service.GeneratePlan(context.Background(), GeneratePlanInput{BudgetCents: 5000})
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if len(attempts) != 1 || len(client.messages) != 1 {
+		t.Fatalf("expected 1 attempt, got %d attempts and %d calls", len(attempts), len(client.messages))
+	}
+}
+
+// blockingClient отвечает только после отмены контекста.
+type blockingClient struct{}
+
+func (blockingClient) Chat(ctx context.Context, _ []Message) (ChatResult, error) {
+	<-ctx.Done()
+	return ChatResult{}, ctx.Err()
+}
+
+// TestGeneratePlanTimeout проверяет, что весь запрос к AI ограничен таймаутом сервиса.
+func TestGeneratePlanTimeout(t *testing.T) {
+	service := NewService(blockingClient{}, 2, 20*time.Millisecond)
+
+	started := time.Now()
+	_, attempts, err := service.GeneratePlan(context.Background(), GeneratePlanInput{BudgetCents: 5000})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(attempts) != 1 {
+		t.Fatalf("expected 1 attempt, got %d", len(attempts))
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("expected request to stop at timeout, took %s", elapsed)
+	}
+}
+
+// TestServiceWithoutProviders проверяет, что без провайдеров план составляется
+// без модели, а советы недоступны.
+func TestServiceWithoutProviders(t *testing.T) {
+	service := NewService(nil, 2, 0)
+	if service.Enabled() {
+		t.Fatalf("expected service without client to be disabled")
+	}
+
+	input := GeneratePlanInput{BudgetCents: 100000}
+	response, attempts, err := service.GeneratePlan(context.Background(), input)
+	if err != nil || len(attempts) != 0 {
+		t.Fatalf("expected offline plan without attempts, got %d attempts and %v", len(attempts), err)
+	}
+	if err := validatePlanResponse(response, input.BudgetCents); err != nil {
+		t.Fatalf("expected valid offline plan, got %v", err)
+	}
+
+	if _, _, err := service.AnalyzeSpending(context.Background(), AnalyzeSpendingInput{}); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("expected ErrNotConfigured, got %v", err)
+	}
+}
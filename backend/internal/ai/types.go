@@ -83,6 +83,7 @@ type ItemSnapshot struct {
 	AmountCents int64  `json:"amount_cents"`
 	Priority    string `json:"priority"`
 	IsCompleted bool   `json:"is_completed"`
+	SpentCents  int64  `json:"spent_cents"`
 }
 
 type AdviceResponse struct {
@@ -1,8 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 )
 
@@ -17,3 +19,12 @@ func CompareTokenHash(hash, token string) bool {
 	computed := HashToken(token)
 	return subtle.ConstantTimeCompare([]byte(hash), []byte(computed)) == 1
 }
+
+// GenerateToken возвращает криптографически случайный токен из size байт в base64url.
+func GenerateToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
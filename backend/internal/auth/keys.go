@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const rsaKeyBits = 2048
+
+// KeyEncryptionKeySize — длина ключа шифрования ключей подписи (AES-256).
+const KeyEncryptionKeySize = 32
+
+// SigningKey — асимметричный ключ подписи JWT. Ключ подписывает токены с
+// ActivatesAt и проверяет их, пока не наступил ExpiresAt.
+type SigningKey struct {
+	ID          string
+	Algorithm   string
+	PrivateKey  crypto.Signer
+	ActivatesAt time.Time
+	ExpiresAt   *time.Time
+}
+
+// GenerateSigningKey создает закрытый ключ для алгоритма и возвращает его в PEM (PKCS#8).
+func GenerateSigningKey(algorithm string) ([]byte, error) {
+	var key crypto.Signer
+	var err error
+
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParseSigningKey разбирает PEM закрытого ключа и проверяет, что он подходит алгоритму.
+func ParseSigningKey(algorithm string, data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if algorithm == AlgorithmRS256 {
+			return key, nil
+		}
+	case ed25519.PrivateKey:
+		if algorithm == AlgorithmEdDSA {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("signing key does not match algorithm %q", algorithm)
+}
+
+// SealSigningKey шифрует PEM закрытого ключа AES-256-GCM для хранения в базе.
+// kid участвует в проверке целостности: шифротекст, перенесенный к другому
+// ключу, не расшифруется.
+func SealSigningKey(encryptionKey []byte, kid string, data []byte) ([]byte, error) {
+	aead, err := newKeyCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, data, []byte(kid)), nil
+}
+
+// OpenSigningKey расшифровывает закрытый ключ, зашифрованный SealSigningKey.
+func OpenSigningKey(encryptionKey []byte, kid string, sealed []byte) ([]byte, error) {
+	aead, err := newKeyCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted signing key is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return nil, errors.New("signing key cannot be decrypted: wrong encryption key or corrupted data")
+	}
+
+	return data, nil
+}
+
+func newKeyCipher(encryptionKey []byte) (cipher.AEAD, error) {
+	if len(encryptionKey) != KeyEncryptionKeySize {
+		return nil, fmt.Errorf("key encryption key must be %d bytes", KeyEncryptionKeySize)
+	}
+
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	switch algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return nil
+	}
+}
+
+// KeySet хранит загруженные ключи подписи и безопасен для конкурентного доступа.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []SigningKey
+}
+
+// NewKeySet создает пустой набор ключей.
+func NewKeySet() *KeySet {
+	return &KeySet{}
+}
+
+// Replace заменяет набор ключей целиком.
+func (s *KeySet) Replace(keys []SigningKey) {
+	copied := make([]SigningKey, len(keys))
+	copy(copied, keys)
+
+	s.mu.Lock()
+	s.keys = copied
+	s.mu.Unlock()
+}
+
+// current возвращает ключ, которым подписываются новые токены: последний из
+// уже активированных и не истекших.
+func (s *KeySet) current(now time.Time) (SigningKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var selected SigningKey
+	found := false
+	for _, key := range s.keys {
+		if key.ActivatesAt.After(now) || expired(key, now) {
+			continue
+		}
+		if !found || key.ActivatesAt.After(selected.ActivatesAt) {
+			selected = key
+			found = true
+		}
+	}
+
+	return selected, found
+}
+
+// lookup возвращает ключ проверки подписи по kid.
+func (s *KeySet) lookup(kid string, now time.Time) (SigningKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.ID == kid && !expired(key, now) {
+			return key, true
+		}
+	}
+
+	return SigningKey{}, false
+}
+
+func expired(key SigningKey, now time.Time) bool {
+	return key.ExpiresAt != nil && !key.ExpiresAt.After(now)
+}
+
+// JWK — открытый ключ в формате RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS возвращает открытые ключи всех неистекших ключей, включая еще не
+// активированные, чтобы проверяющие сервисы узнали о них заранее.
+func (s *KeySet) JWKS(now time.Time) JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		if expired(key, now) {
+			continue
+		}
+
+		jwk := JWK{Use: "sig", Algorithm: key.Algorithm, KeyID: key.ID}
+		switch public := key.PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func mustSigningKey(t *testing.T, id, algorithm string, activatesAt time.Time) SigningKey {
+	t.Helper()
+
+	data, err := GenerateSigningKey(algorithm)
+	if err != nil {
+		t.Fatalf("generate %s key: %v", algorithm, err)
+	}
+
+	privateKey, err := ParseSigningKey(algorithm, data)
+	if err != nil {
+		t.Fatalf("parse %s key: %v", algorithm, err)
+	}
+
+	return SigningKey{ID: id, Algorithm: algorithm, PrivateKey: privateKey, ActivatesAt: activatesAt}
+}
+
+// TestKeySetRotation проверяет, что токены старого ключа проверяются после ротации,
+// а новые подписываются активированным ключом.
+func TestKeySetRotation(t *testing.T) {
+	now := time.Now()
+	keys := NewKeySet()
+	manager := NewTokenManager("", "issuer", time.Minute, time.Hour, WithKeySet(keys))
+
+	oldKey := mustSigningKey(t, "old", AlgorithmRS256, now.Add(-time.Hour))
+	keys.Replace([]SigningKey{oldKey})
+
+	pair, err := manager.NewTokenPair(uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatalf("token pair: %v", err)
+	}
+
+	expiresAt := now.Add(time.Hour)
+	oldKey.ExpiresAt = &expiresAt
+	newKey := mustSigningKey(t, "new", AlgorithmEdDSA, now.Add(-time.Second))
+	pending := mustSigningKey(t, "pending", AlgorithmEdDSA, now.Add(time.Hour))
+	keys.Replace([]SigningKey{oldKey, newKey, pending})
+
+	if _, err := manager.ParseAccessToken(pair.AccessToken); err != nil {
+		t.Fatalf("expected token of rotated key to verify: %v", err)
+	}
+
+	current, ok := keys.current(now)
+	if !ok || current.ID != "new" {
+		t.Fatalf("expected new key to sign, got %q", current.ID)
+	}
+
+	next, err := manager.NewTokenPair(uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatalf("token pair: %v", err)
+	}
+	if _, err := manager.ParseRefreshToken(next.RefreshToken); err != nil {
+		t.Fatalf("expected EdDSA token to verify: %v", err)
+	}
+
+	if got := len(keys.JWKS(now).Keys); got != 3 {
+		t.Fatalf("expected 3 published keys, got %d", got)
+	}
+
+	keys.Replace([]SigningKey{newKey})
+	if _, err := manager.ParseAccessToken(pair.AccessToken); err == nil {
+		t.Fatal("expected token of removed key to be rejected")
+	}
+}
+
+// TestKeySetLegacySecret проверяет, что HS256-токены принимаются, пока задан секрет.
+func TestKeySetLegacySecret(t *testing.T) {
+	legacy := NewTokenManager("secret", "issuer", time.Minute, time.Hour)
+	pair, err := legacy.NewTokenPair(uuid.New(), uuid.New())
+	if err != nil {
+		t.Fatalf("token pair: %v", err)
+	}
+
+	keys := NewKeySet()
+	keys.Replace([]SigningKey{mustSigningKey(t, "kid", AlgorithmEdDSA, time.Now().Add(-time.Minute))})
+
+	withSecret := NewTokenManager("secret", "issuer", time.Minute, time.Hour, WithKeySet(keys))
+	if _, err := withSecret.ParseAccessToken(pair.AccessToken); err != nil {
+		t.Fatalf("expected legacy token to verify: %v", err)
+	}
+
+	withoutSecret := NewTokenManager("", "issuer", time.Minute, time.Hour, WithKeySet(keys))
+	if _, err := withoutSecret.ParseAccessToken(pair.AccessToken); err == nil {
+		t.Fatal("expected legacy token to be rejected without secret")
+	}
+}
+
+// TestJWKSFormat проверяет поля открытых ключей RSA и Ed25519.
+func TestJWKSFormat(t *testing.T) {
+	now := time.Now()
+	keys := NewKeySet()
+	keys.Replace([]SigningKey{
+		mustSigningKey(t, "rsa", AlgorithmRS256, now),
+		mustSigningKey(t, "ed", AlgorithmEdDSA, now),
+	})
+
+	set := keys.JWKS(now)
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(set.Keys))
+	}
+
+	rsaKey, edKey := set.Keys[0], set.Keys[1]
+	if rsaKey.KeyType != "RSA" || rsaKey.Algorithm != AlgorithmRS256 || rsaKey.N == "" || rsaKey.E != "AQAB" {
+		t.Fatalf("unexpected RSA jwk: %+v", rsaKey)
+	}
+	if edKey.KeyType != "OKP" || edKey.Curve != "Ed25519" || edKey.X == "" || edKey.KeyID != "ed" {
+		t.Fatalf("unexpected Ed25519 jwk: %+v", edKey)
+	}
+}
+
+// TestSealSigningKey проверяет шифрование закрытого ключа и привязку шифротекста к kid.
+func TestSealSigningKey(t *testing.T) {
+	encryptionKey := bytes.Repeat([]byte{7}, KeyEncryptionKeySize)
+	data, err := GenerateSigningKey(AlgorithmEdDSA)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	sealed, err := SealSigningKey(encryptionKey, "kid-1", data)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("PRIVATE KEY")) {
+		t.Fatal("expected private key to be encrypted")
+	}
+
+	opened, err := OpenSigningKey(encryptionKey, "kid-1", sealed)
+	if err != nil || !bytes.Equal(opened, data) {
+		t.Fatalf("expected original key, got error %v", err)
+	}
+
+	if _, err := OpenSigningKey(encryptionKey, "kid-2", sealed); err == nil {
+		t.Fatal("expected error for another kid")
+	}
+	if _, err := OpenSigningKey(bytes.Repeat([]byte{8}, KeyEncryptionKeySize), "kid-1", sealed); err == nil {
+		t.Fatal("expected error for another encryption key")
+	}
+	if _, err := SealSigningKey([]byte("short"), "kid-1", data); err == nil {
+		t.Fatal("expected error for short encryption key")
+	}
+}
@@ -0,0 +1,40 @@
+package auth
+
+import "time"
+
+const (
+	// lockoutFreeAttempts — число неудачных попыток, после которых задержки еще нет.
+	lockoutFreeAttempts = 3
+	lockoutBaseDelay    = 5 * time.Second
+)
+
+// LoginLockout задает прогрессивную задержку входа после неудачных попыток
+// и временную блокировку после Threshold неудач подряд.
+type LoginLockout struct {
+	Threshold int
+	Duration  time.Duration
+}
+
+// Delay возвращает, на сколько закрывается вход после failures неудач подряд:
+// первые попытки без задержки, затем 5s, 10s, 20s... но не дольше Duration,
+// а начиная с Threshold — блокировка на Duration.
+func (l LoginLockout) Delay(failures int) time.Duration {
+	if failures >= l.Threshold {
+		return l.Duration
+	}
+	if failures < lockoutFreeAttempts {
+		return 0
+	}
+
+	delay := lockoutBaseDelay
+	for i := lockoutFreeAttempts; i < failures && delay < l.Duration; i++ {
+		delay *= 2
+	}
+
+	return min(delay, l.Duration)
+}
+
+// Locked сообщает, достигнут ли порог блокировки.
+func (l LoginLockout) Locked(failures int) bool {
+	return failures >= l.Threshold
+}
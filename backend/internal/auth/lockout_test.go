@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLoginLockoutDelay проверяет рост задержки и блокировку по порогу.
+func TestLoginLockoutDelay(t *testing.T) {
+	lockout := LoginLockout{Threshold: 10, Duration: 30 * time.Minute}
+
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{2, 0},
+		{3, 5 * time.Second},
+		{4, 10 * time.Second},
+		{6, 40 * time.Second},
+		{9, 320 * time.Second},
+		{10, 30 * time.Minute},
+		{25, 30 * time.Minute},
+	}
+
+	for _, tc := range cases {
+		if got := lockout.Delay(tc.failures); got != tc.want {
+			t.Fatalf("failures=%d: expected %s, got %s", tc.failures, tc.want, got)
+		}
+	}
+
+	short := LoginLockout{Threshold: 100, Duration: time.Minute}
+	if got := short.Delay(50); got != time.Minute {
+		t.Fatalf("expected delay capped at lockout duration, got %s", got)
+	}
+}
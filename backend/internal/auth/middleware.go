@@ -8,10 +8,15 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-const ContextUserIDKey = "user_id"
+const (
+	ContextUserIDKey    = "user_id"
+	ContextSessionIDKey = "session_id"
+)
 
-// JWTMiddleware проверяет access-токен и сохраняет user_id в контексте.
-func JWTMiddleware(manager *TokenManager) echo.MiddlewareFunc {
+// JWTMiddleware проверяет access-токен или персональный токен доступа и
+// сохраняет user_id в контексте. Для персональных токенов в контекст также
+// попадают их scopes; проверяют их RequireScope, MethodScope и SessionOnly.
+func JWTMiddleware(manager *TokenManager, personalTokens PersonalTokenAuthenticator) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
@@ -29,6 +34,26 @@ func JWTMiddleware(manager *TokenManager) echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header")
 			}
 
+			if IsPersonalToken(tokenString) {
+				if personalTokens == nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+				}
+
+				userID, rawScopes, err := personalTokens.AuthenticatePersonalToken(c.Request().Context(), HashToken(tokenString))
+				if err != nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+				}
+
+				scopes := make([]Scope, 0, len(rawScopes))
+				for _, scope := range rawScopes {
+					scopes = append(scopes, Scope(scope))
+				}
+
+				c.Set(ContextUserIDKey, userID)
+				c.Set(ContextScopesKey, scopes)
+				return next(c)
+			}
+
 			claims, err := manager.ParseAccessToken(tokenString)
 			if err != nil {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
@@ -40,6 +65,9 @@ func JWTMiddleware(manager *TokenManager) echo.MiddlewareFunc {
 			}
 
 			c.Set(ContextUserIDKey, userID)
+			if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+				c.Set(ContextSessionIDKey, sessionID)
+			}
 			return next(c)
 		}
 	}
@@ -51,3 +79,10 @@ func UserIDFromContext(c echo.Context) (uuid.UUID, bool) {
 	userID, ok := value.(uuid.UUID)
 	return userID, ok
 }
+
+// SessionIDFromContext извлекает идентификатор сессии (refresh-токена) текущего access-токена.
+func SessionIDFromContext(c echo.Context) (uuid.UUID, bool) {
+	value := c.Get(ContextSessionIDKey)
+	sessionID, ok := value.(uuid.UUID)
+	return sessionID, ok
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// PersonalTokenPrefix отличает персональные токены доступа от JWT в заголовке Authorization.
+const PersonalTokenPrefix = "bp_pat_"
+
+const (
+	personalTokenBytes = 32
+	ContextScopesKey   = "token_scopes"
+)
+
+type Scope string
+
+const (
+	// ScopeRead разрешает только чтение (GET/HEAD).
+	ScopeRead Scope = "read"
+	// ScopeWrite разрешает чтение и изменение данных.
+	ScopeWrite Scope = "write"
+	// ScopeAI разрешает AI-эндпоинты.
+	ScopeAI Scope = "ai"
+)
+
+// ValidScope сообщает, известен ли scope.
+func ValidScope(scope string) bool {
+	switch Scope(scope) {
+	case ScopeRead, ScopeWrite, ScopeAI:
+		return true
+	default:
+		return false
+	}
+}
+
+// PersonalTokenAuthenticator находит действующий персональный токен по хэшу,
+// отмечает его использование и возвращает владельца и scopes.
+type PersonalTokenAuthenticator interface {
+	AuthenticatePersonalToken(ctx context.Context, tokenHash string) (uuid.UUID, []string, error)
+}
+
+// GeneratePersonalToken возвращает новый персональный токен с префиксом PersonalTokenPrefix.
+func GeneratePersonalToken() (string, error) {
+	token, err := GenerateToken(personalTokenBytes)
+	if err != nil {
+		return "", err
+	}
+	return PersonalTokenPrefix + token, nil
+}
+
+// IsPersonalToken сообщает, является ли строка персональным токеном.
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
+
+// ScopesFromContext возвращает scopes персонального токена запроса.
+// Для запросов с JWT-сессией ok равен false: им доступно все.
+func ScopesFromContext(c echo.Context) ([]Scope, bool) {
+	scopes, ok := c.Get(ContextScopesKey).([]Scope)
+	return scopes, ok
+}
+
+// hasScope проверяет scope персонального токена; write включает read.
+func hasScope(scopes []Scope, required Scope) bool {
+	for _, scope := range scopes {
+		if scope == required || (required == ScopeRead && scope == ScopeWrite) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope пропускает JWT-сессии и персональные токены с нужным scope.
+func RequireScope(required Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if scopes, ok := ScopesFromContext(c); ok && !hasScope(scopes, required) {
+				return echo.NewHTTPError(http.StatusForbidden, "token scope "+string(required)+" required")
+			}
+			return next(c)
+		}
+	}
+}
+
+// MethodScope требует от персональных токенов scope read для GET/HEAD и write для остальных методов.
+func MethodScope() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			required := ScopeWrite
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				required = ScopeRead
+			}
+			return RequireScope(required)(next)(c)
+		}
+	}
+}
+
+// SessionOnly отклоняет персональные токены: управлять учетной записью можно
+// только из сессии, полученной входом.
+func SessionOnly() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := ScopesFromContext(c); ok {
+				return echo.NewHTTPError(http.StatusForbidden, "personal access tokens are not allowed here")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type stubPersonalTokens struct {
+	hash   string
+	userID uuid.UUID
+	scopes []string
+}
+
+func (s stubPersonalTokens) AuthenticatePersonalToken(_ context.Context, tokenHash string) (uuid.UUID, []string, error) {
+	if tokenHash != s.hash {
+		return uuid.Nil, nil, errors.New("not found")
+	}
+	return s.userID, s.scopes, nil
+}
+
+func serve(t *testing.T, e *echo.Echo, method, token string) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/resource", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// TestPersonalTokenScopes проверяет аутентификацию персональным токеном и scopes по методу.
+func TestPersonalTokenScopes(t *testing.T) {
+	token, err := GeneratePersonalToken()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	userID := uuid.New()
+	manager := NewTokenManager("secret", "issuer", time.Minute, time.Hour)
+	store := stubPersonalTokens{hash: HashToken(token), userID: userID, scopes: []string{"read"}}
+
+	e := echo.New()
+	handler := func(c echo.Context) error {
+		got, ok := UserIDFromContext(c)
+		if !ok || got != userID {
+			t.Fatalf("unexpected user in context: %v", got)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+	e.GET("/resource", handler, JWTMiddleware(manager, store), MethodScope())
+	e.POST("/resource", handler, JWTMiddleware(manager, store), MethodScope())
+	e.PUT("/resource", handler, JWTMiddleware(manager, store), SessionOnly())
+
+	if code := serve(t, e, http.MethodGet, token); code != http.StatusNoContent {
+		t.Fatalf("expected read to be allowed, got %d", code)
+	}
+	if code := serve(t, e, http.MethodPost, token); code != http.StatusForbidden {
+		t.Fatalf("expected write to be forbidden, got %d", code)
+	}
+	if code := serve(t, e, http.MethodPut, token); code != http.StatusForbidden {
+		t.Fatalf("expected session-only route to reject token, got %d", code)
+	}
+	if code := serve(t, e, http.MethodGet, PersonalTokenPrefix+"unknown"); code != http.StatusUnauthorized {
+		t.Fatalf("expected unknown token to be rejected, got %d", code)
+	}
+}
+
+// TestHasScope проверяет, что write включает read, но не ai.
+func TestHasScope(t *testing.T) {
+	scopes := []Scope{ScopeWrite}
+	if !hasScope(scopes, ScopeRead) || !hasScope(scopes, ScopeWrite) {
+		t.Fatal("expected write to grant read and write")
+	}
+	if hasScope(scopes, ScopeAI) {
+		t.Fatal("expected write not to grant ai")
+	}
+}
@@ -13,10 +13,16 @@ type TokenType string
 const (
 	TokenTypeAccess  TokenType = "access"
 	TokenTypeRefresh TokenType = "refresh"
+	TokenTypeMFA     TokenType = "mfa"
 )
 
+// mfaTokenTTL ограничивает время между вводом пароля и кода второго фактора.
+const mfaTokenTTL = 5 * time.Minute
+
 type Claims struct {
 	TokenType TokenType `json:"typ"`
+	// SessionID — идентификатор refresh-токена, вместе с которым выпущен access-токен.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -27,31 +33,51 @@ type TokenPair struct {
 	RefreshExpiresAt time.Time
 }
 
+var errNoSigningKey = errors.New("no active signing key")
+
 type TokenManager struct {
 	secret     []byte
+	keys       *KeySet
 	issuer     string
 	accessTTL  time.Duration
 	refreshTTL time.Duration
 }
 
+// TokenManagerOption настраивает TokenManager.
+type TokenManagerOption func(*TokenManager)
+
+// WithKeySet включает подпись асимметричными ключами из набора. Токены,
+// подписанные секретом HS256, продолжают проверяться, если секрет задан.
+func WithKeySet(keys *KeySet) TokenManagerOption {
+	return func(m *TokenManager) {
+		m.keys = keys
+	}
+}
+
 // NewTokenManager инициализирует менеджер JWT токенов.
-func NewTokenManager(secret string, issuer string, accessTTL, refreshTTL time.Duration) *TokenManager {
-	return &TokenManager{
+func NewTokenManager(secret string, issuer string, accessTTL, refreshTTL time.Duration, opts ...TokenManagerOption) *TokenManager {
+	manager := &TokenManager{
 		secret:     []byte(secret),
 		issuer:     issuer,
 		accessTTL:  accessTTL,
 		refreshTTL: refreshTTL,
 	}
+
+	for _, opt := range opts {
+		opt(manager)
+	}
+
+	return manager
 }
 
 // NewTokenPair создает пару access/refresh токенов для пользователя.
 func (m *TokenManager) NewTokenPair(userID uuid.UUID, refreshTokenID uuid.UUID) (TokenPair, error) {
-	accessToken, accessExp, err := m.newToken(userID, uuid.New(), TokenTypeAccess, m.accessTTL)
+	accessToken, accessExp, err := m.newToken(userID, uuid.New(), TokenTypeAccess, m.accessTTL, refreshTokenID.String())
 	if err != nil {
 		return TokenPair{}, err
 	}
 
-	refreshToken, refreshExp, err := m.newToken(userID, refreshTokenID, TokenTypeRefresh, m.refreshTTL)
+	refreshToken, refreshExp, err := m.newToken(userID, refreshTokenID, TokenTypeRefresh, m.refreshTTL, "")
 	if err != nil {
 		return TokenPair{}, err
 	}
@@ -64,6 +90,17 @@ func (m *TokenManager) NewTokenPair(userID uuid.UUID, refreshTokenID uuid.UUID)
 	}, nil
 }
 
+// NewMFAToken создает короткоживущий токен MFA-челленджа после проверки пароля.
+// Он не дает доступа к API и обменивается на пару токенов только вместе с кодом.
+func (m *TokenManager) NewMFAToken(userID uuid.UUID) (string, time.Time, error) {
+	return m.newToken(userID, uuid.New(), TokenTypeMFA, mfaTokenTTL, "")
+}
+
+// ParseMFAToken валидирует токен MFA-челленджа и возвращает claims.
+func (m *TokenManager) ParseMFAToken(tokenString string) (*Claims, error) {
+	return m.parseToken(tokenString, TokenTypeMFA)
+}
+
 // ParseAccessToken валидирует access-токен и возвращает claims.
 func (m *TokenManager) ParseAccessToken(tokenString string) (*Claims, error) {
 	return m.parseToken(tokenString, TokenTypeAccess)
@@ -74,12 +111,13 @@ func (m *TokenManager) ParseRefreshToken(tokenString string) (*Claims, error) {
 	return m.parseToken(tokenString, TokenTypeRefresh)
 }
 
-func (m *TokenManager) newToken(userID uuid.UUID, tokenID uuid.UUID, tokenType TokenType, ttl time.Duration) (string, time.Time, error) {
+func (m *TokenManager) newToken(userID uuid.UUID, tokenID uuid.UUID, tokenType TokenType, ttl time.Duration, sessionID string) (string, time.Time, error) {
 	now := time.Now()
 	expiresAt := now.Add(ttl)
 
 	claims := Claims{
 		TokenType: tokenType,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    m.issuer,
 			Subject:   userID.String(),
@@ -89,8 +127,7 @@ func (m *TokenManager) newToken(userID uuid.UUID, tokenID uuid.UUID, tokenType T
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(m.secret)
+	signed, err := m.sign(claims, now)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -98,13 +135,58 @@ func (m *TokenManager) newToken(userID uuid.UUID, tokenID uuid.UUID, tokenType T
 	return signed, expiresAt, nil
 }
 
+// sign подписывает claims текущим ключом из набора, а без набора — секретом HS256.
+func (m *TokenManager) sign(claims Claims, now time.Time) (string, error) {
+	if m.keys != nil {
+		key, ok := m.keys.current(now)
+		if !ok {
+			return "", errNoSigningKey
+		}
+
+		token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.PrivateKey)
+	}
+
+	if len(m.secret) == 0 {
+		return "", errNoSigningKey
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+}
+
+func (m *TokenManager) validMethods() []string {
+	methods := make([]string, 0, 3)
+	if len(m.secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if m.keys != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg())
+	}
+	return methods
+}
+
+// verificationKey выбирает ключ проверки подписи: секрет для HS256 или
+// открытый ключ из набора по заголовку kid.
+func (m *TokenManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return m.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys.lookup(kid, time.Now())
+	if !ok || key.Algorithm != token.Method.Alg() {
+		return nil, errors.New("unknown signing key")
+	}
+
+	return key.PrivateKey.Public(), nil
+}
+
 func (m *TokenManager) parseToken(tokenString string, tokenType TokenType) (*Claims, error) {
 	claims := &Claims{}
 
-	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}), jwt.WithIssuer(m.issuer))
-	token, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return m.secret, nil
-	})
+	parser := jwt.NewParser(jwt.WithValidMethods(m.validMethods()), jwt.WithIssuer(m.issuer))
+	token, err := parser.ParseWithClaims(tokenString, claims, m.verificationKey)
 	if err != nil {
 		return nil, err
 	}
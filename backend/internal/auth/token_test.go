@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestMFATokenType проверяет, что MFA-токен не принимается как access и наоборот.
+func TestMFATokenType(t *testing.T) {
+	manager := NewTokenManager("secret", "issuer", time.Minute, time.Hour)
+	userID := uuid.New()
+
+	mfaToken, _, err := manager.NewMFAToken(userID)
+	if err != nil {
+		t.Fatalf("mfa token: %v", err)
+	}
+
+	claims, err := manager.ParseMFAToken(mfaToken)
+	if err != nil {
+		t.Fatalf("parse mfa token: %v", err)
+	}
+	if claims.Subject != userID.String() {
+		t.Fatalf("unexpected subject %s", claims.Subject)
+	}
+
+	if _, err := manager.ParseAccessToken(mfaToken); err == nil {
+		t.Fatal("expected mfa token to be rejected as access token")
+	}
+
+	pair, err := manager.NewTokenPair(userID, uuid.New())
+	if err != nil {
+		t.Fatalf("token pair: %v", err)
+	}
+	if _, err := manager.ParseMFAToken(pair.AccessToken); err == nil {
+		t.Fatal("expected access token to be rejected as mfa token")
+	}
+}
+
+// TestAccessTokenSessionID проверяет, что access-токен ссылается на свой refresh-токен.
+func TestAccessTokenSessionID(t *testing.T) {
+	manager := NewTokenManager("secret", "issuer", time.Minute, time.Hour)
+	refreshID := uuid.New()
+
+	pair, err := manager.NewTokenPair(uuid.New(), refreshID)
+	if err != nil {
+		t.Fatalf("token pair: %v", err)
+	}
+
+	claims, err := manager.ParseAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("parse access token: %v", err)
+	}
+	if claims.SessionID != refreshID.String() {
+		t.Fatalf("expected sid %s, got %s", refreshID, claims.SessionID)
+	}
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret — ключ из тестовых векторов RFC 6238 для SHA1.
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// TestTOTPCodeRFC6238 сверяет коды с тестовыми векторами RFC 6238 (последние 6 цифр).
+func TestTOTPCodeRFC6238(t *testing.T) {
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range cases {
+		got, err := TOTPCode(rfc6238Secret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatalf("code at %d: %v", unix, err)
+		}
+		if got != want {
+			t.Fatalf("code at %d: expected %s, got %s", unix, want, got)
+		}
+	}
+}
+
+// TestValidateTOTPWindow проверяет допуск в один интервал и номер интервала.
+func TestValidateTOTPWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	previous, err := TOTPCode(rfc6238Secret, now.Add(-30*time.Second))
+	if err != nil {
+		t.Fatalf("code: %v", err)
+	}
+
+	counter, ok := ValidateTOTP(rfc6238Secret, previous, now)
+	if !ok {
+		t.Fatal("expected previous interval code to be accepted")
+	}
+	if counter != now.Unix()/30-1 {
+		t.Fatalf("unexpected counter %d", counter)
+	}
+
+	stale, err := TOTPCode(rfc6238Secret, now.Add(-90*time.Second))
+	if err != nil {
+		t.Fatalf("code: %v", err)
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, stale, now); ok {
+		t.Fatal("expected code outside the window to be rejected")
+	}
+
+	if _, ok := ValidateTOTP(rfc6238Secret, "12345", now); ok {
+		t.Fatal("expected short code to be rejected")
+	}
+}
+
+// TestTOTPURI проверяет формат otpauth URI.
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("budget-planner", "user@example.com", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/budget-planner:user@example.com?") {
+		t.Fatalf("unexpected uri %s", uri)
+	}
+	if !strings.Contains(uri, "secret=ABC") || !strings.Contains(uri, "issuer=budget-planner") {
+		t.Fatalf("missing parameters in %s", uri)
+	}
+}
+
+// TestRecoveryCodes проверяет формат и нормализацию кодов восстановления.
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("expected %d codes, got %d", recoveryCodeCount, len(codes))
+	}
+
+	seen := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		if len(code) != 9 || code[4] != '-' {
+			t.Fatalf("unexpected code format %q", code)
+		}
+		seen[code] = struct{}{}
+	}
+	if len(seen) != len(codes) {
+		t.Fatal("expected unique codes")
+	}
+
+	if got := NormalizeRecoveryCode(" ABCD-EFGH "); got != "abcdefgh" {
+		t.Fatalf("unexpected normalized code %q", got)
+	}
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"strconv"
@@ -12,12 +14,18 @@ import (
 )
 
 type Config struct {
-	Env      string
-	Server   ServerConfig
-	Database DatabaseConfig
-	Auth     AuthConfig
-	AI       AIConfig
-	Admin    AdminConfig
+	Env           string
+	AppURL        string
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Auth          AuthConfig
+	AI            AIConfig
+	Admin         AdminConfig
+	Scheduler     SchedulerConfig
+	Notifications NotificationsConfig
+	Webhooks      WebhooksConfig
+	Mail          MailConfig
+	OIDC          OIDCConfig
 }
 
 type ServerConfig struct {
@@ -26,6 +34,9 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// TrustedProxies — сети прокси, которым доверяется X-Forwarded-For.
+	// Без них адрес клиента берется из соединения.
+	TrustedProxies []*net.IPNet
 }
 
 type DatabaseConfig struct {
@@ -41,9 +52,22 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration
 }
 
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+)
+
 type AuthConfig struct {
-	JWTSecret          string
-	JWTIssuer          string
+	JWTSecret        string
+	JWTIssuer        string
+	SigningAlgorithm string
+	KeyRotation      time.Duration
+	// KeyEncryptionKey шифрует закрытые ключи RS256/EdDSA в базе (AES-256-GCM).
+	KeyEncryptionKey   []byte
+	LockoutThreshold   int
+	LockoutDuration    time.Duration
+	DeletionGrace      time.Duration
 	AccessTokenTTL     time.Duration
 	RefreshTokenTTL    time.Duration
 	RateLimitPerMinute int
@@ -56,15 +80,95 @@ type AIConfig struct {
 	BaseURL            string
 	Model              string
 	Timeout            time.Duration
+	Organization       string
+	Headers            map[string]string
+	JSONResponse       bool
 	RateLimitPerMinute int
 	RateLimitBurst     int
 	MaxOutputTokens    int
+	// RepairAttempts — сколько раз модель просят исправить ответ, не прошедший проверку.
+	RepairAttempts int
+	// RequestTimeout ограничивает весь запрос к AI вместе с резервными
+	// провайдерами и исправлениями; должен быть меньше SERVER_WRITE_TIMEOUT.
+	RequestTimeout time.Duration
+	// Fallbacks опрашиваются по порядку, если основной провайдер не ответил.
+	Fallbacks []AIProviderConfig
+	// После BreakerThreshold ошибок подряд провайдер пропускается на BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+const (
+	AIProviderGemini = "gemini"
+	AIProviderGroq   = "groq"
+	AIProviderOpenAI = "openai"
+	AIProviderOllama = "ollama"
+)
+
+// AIProviderConfig — настройки одного провайдера в цепочке AI.
+type AIProviderConfig struct {
+	Name    string
+	APIKey  string
+	BaseURL string
+	Model   string
+	Timeout time.Duration
+	// Organization, Headers и JSONResponse применяются к OpenAI-совместимым провайдерам.
+	Organization string
+	Headers      map[string]string
+	JSONResponse bool
 }
 
 type AdminConfig struct {
 	Emails []string
 }
 
+type SchedulerConfig struct {
+	RolloverInterval          time.Duration
+	NotificationPruneInterval time.Duration
+	WebhookDeliveryInterval   time.Duration
+	SigningKeyInterval        time.Duration
+	AccountPurgeInterval      time.Duration
+}
+
+const (
+	NotificationBrokerMemory   = "memory"
+	NotificationBrokerPostgres = "postgres"
+)
+
+type NotificationsConfig struct {
+	Retention time.Duration
+	Broker    string
+}
+
+type WebhooksConfig struct {
+	Timeout     time.Duration
+	MaxAttempts int
+}
+
+const (
+	MailDriverLog  = "log"
+	MailDriverSMTP = "smtp"
+)
+
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// OIDCConfig описывает провайдера OpenID Connect. Вход через провайдера
+// включен, если задан IssuerURL.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Timeout      time.Duration
+}
+
 // Load загружает конфигурацию приложения из окружения и .env.
 func Load() (Config, error) {
 	cfg := Config{}
@@ -74,6 +178,7 @@ func Load() (Config, error) {
 	}
 
 	cfg.Env = getEnv("APP_ENV", "local")
+	cfg.AppURL = strings.TrimRight(getEnv("APP_URL", "http://localhost:3000"), "/")
 
 	serverPort, err := parseIntEnv("SERVER_PORT", 8080)
 	if err != nil {
@@ -95,12 +200,18 @@ func Load() (Config, error) {
 		return cfg, err
 	}
 
+	trustedProxies, err := parseCIDRListEnv("SERVER_TRUSTED_PROXIES")
+	if err != nil {
+		return cfg, err
+	}
+
 	cfg.Server = ServerConfig{
-		Host:         getEnv("SERVER_HOST", "0.0.0.0"),
-		Port:         serverPort,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		IdleTimeout:  idleTimeout,
+		Host:           getEnv("SERVER_HOST", "0.0.0.0"),
+		Port:           serverPort,
+		ReadTimeout:    readTimeout,
+		WriteTimeout:   writeTimeout,
+		IdleTimeout:    idleTimeout,
+		TrustedProxies: trustedProxies,
 	}
 
 	dbPort, err := parseIntEnv("DB_PORT", 5432)
@@ -151,6 +262,31 @@ func Load() (Config, error) {
 		return cfg, err
 	}
 
+	keyEncryptionKey, err := parseBase64Env("JWT_KEY_ENCRYPTION_KEY")
+	if err != nil {
+		return cfg, err
+	}
+
+	keyRotation, err := parseDurationEnv("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+
+	lockoutThreshold, err := parseIntEnv("AUTH_LOCKOUT_THRESHOLD", 10)
+	if err != nil {
+		return cfg, err
+	}
+
+	lockoutDuration, err := parseDurationEnv("AUTH_LOCKOUT_DURATION", 30*time.Minute)
+	if err != nil {
+		return cfg, err
+	}
+
+	deletionGrace, err := parseDurationEnv("ACCOUNT_DELETION_GRACE", 30*24*time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+
 	rateLimitPerMinute, err := parseIntEnv("AUTH_RATE_LIMIT_PER_MINUTE", 60)
 	if err != nil {
 		return cfg, err
@@ -164,60 +300,171 @@ func Load() (Config, error) {
 	cfg.Auth = AuthConfig{
 		JWTSecret:          getEnv("JWT_SECRET", ""),
 		JWTIssuer:          getEnv("JWT_ISSUER", "budget-planner"),
+		SigningAlgorithm:   parseJWTAlgorithm(getEnv("JWT_SIGNING_ALG", JWTAlgorithmHS256)),
+		KeyRotation:        keyRotation,
+		KeyEncryptionKey:   keyEncryptionKey,
+		LockoutThreshold:   lockoutThreshold,
+		LockoutDuration:    lockoutDuration,
+		DeletionGrace:      deletionGrace,
 		AccessTokenTTL:     accessTTL,
 		RefreshTokenTTL:    refreshTTL,
 		RateLimitPerMinute: rateLimitPerMinute,
 		RateLimitBurst:     rateLimitBurst,
 	}
 
-	aiTimeout, err := parseDurationEnv("AI_TIMEOUT", 20*time.Second)
+	aiRateLimitPerMinute, err := parseIntEnv("AI_RATE_LIMIT_PER_MINUTE", 30)
 	if err != nil {
 		return cfg, err
 	}
 
-	aiRateLimitPerMinute, err := parseIntEnv("AI_RATE_LIMIT_PER_MINUTE", 30)
+	aiRateLimitBurst, err := parseIntEnv("AI_RATE_LIMIT_BURST", 10)
 	if err != nil {
 		return cfg, err
 	}
 
-	aiRateLimitBurst, err := parseIntEnv("AI_RATE_LIMIT_BURST", 10)
+	aiMaxOutputTokens, err := parseIntEnv("AI_MAX_OUTPUT_TOKENS", 4096)
 	if err != nil {
 		return cfg, err
 	}
 
-	aiMaxOutputTokens, err := parseIntEnv("AI_MAX_OUTPUT_TOKENS", 4096)
+	aiRepairAttempts, err := parseIntEnv("AI_REPAIR_ATTEMPTS", 2)
 	if err != nil {
 		return cfg, err
 	}
 
-	aiProvider := strings.ToLower(getEnv("AI_PROVIDER", "gemini"))
-	defaultBaseURL := "https://api.groq.com/openai/v1"
-	defaultModel := "llama-3.1-8b-instant"
-	if aiProvider == "gemini" {
-		defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
-		defaultModel = "gemini-1.5-flash"
+	aiRequestTimeout, err := parseDurationEnv("AI_REQUEST_TIMEOUT", 8*time.Second)
+	if err != nil {
+		return cfg, err
 	}
 
-	aiAPIKey := getEnv("AI_API_KEY", "")
-	if aiAPIKey == "" && aiProvider == "gemini" {
-		aiAPIKey = getEnv("GEMINI_API_KEY", "")
+	aiBreakerThreshold, err := parseIntEnv("AI_BREAKER_THRESHOLD", 3)
+	if err != nil {
+		return cfg, err
+	}
+
+	aiBreakerCooldown, err := parseDurationEnv("AI_BREAKER_COOLDOWN", time.Minute)
+	if err != nil {
+		return cfg, err
+	}
+
+	aiPrimary, err := loadAIProvider(strings.ToLower(getEnv("AI_PROVIDER", AIProviderGemini)), "AI_", 20*time.Second)
+	if err != nil {
+		return cfg, err
+	}
+
+	aiFallbacks, err := loadAIFallbacks(aiPrimary.Timeout)
+	if err != nil {
+		return cfg, err
 	}
 
 	cfg.AI = AIConfig{
-		Provider:           aiProvider,
-		APIKey:             aiAPIKey,
-		BaseURL:            getEnv("AI_BASE_URL", defaultBaseURL),
-		Model:              getEnv("AI_MODEL", defaultModel),
-		Timeout:            aiTimeout,
+		Provider:           aiPrimary.Name,
+		APIKey:             aiPrimary.APIKey,
+		BaseURL:            aiPrimary.BaseURL,
+		Model:              aiPrimary.Model,
+		Timeout:            aiPrimary.Timeout,
+		Organization:       aiPrimary.Organization,
+		Headers:            aiPrimary.Headers,
+		JSONResponse:       aiPrimary.JSONResponse,
 		RateLimitPerMinute: aiRateLimitPerMinute,
 		RateLimitBurst:     aiRateLimitBurst,
 		MaxOutputTokens:    aiMaxOutputTokens,
+		RepairAttempts:     aiRepairAttempts,
+		RequestTimeout:     aiRequestTimeout,
+		Fallbacks:          aiFallbacks,
+		BreakerThreshold:   aiBreakerThreshold,
+		BreakerCooldown:    aiBreakerCooldown,
 	}
 
 	cfg.Admin = AdminConfig{
 		Emails: parseCSVEnv("ADMIN_EMAILS"),
 	}
 
+	rolloverInterval, err := parseDurationEnv("SCHEDULER_ROLLOVER_INTERVAL", time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+
+	notificationPruneInterval, err := parseDurationEnv("SCHEDULER_NOTIFICATION_PRUNE_INTERVAL", time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+
+	webhookDeliveryInterval, err := parseDurationEnv("SCHEDULER_WEBHOOK_DELIVERY_INTERVAL", 10*time.Second)
+	if err != nil {
+		return cfg, err
+	}
+
+	signingKeyInterval, err := parseDurationEnv("SCHEDULER_SIGNING_KEY_INTERVAL", time.Minute)
+	if err != nil {
+		return cfg, err
+	}
+
+	accountPurgeInterval, err := parseDurationEnv("SCHEDULER_ACCOUNT_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+
+	cfg.Scheduler = SchedulerConfig{
+		RolloverInterval:          rolloverInterval,
+		NotificationPruneInterval: notificationPruneInterval,
+		WebhookDeliveryInterval:   webhookDeliveryInterval,
+		SigningKeyInterval:        signingKeyInterval,
+		AccountPurgeInterval:      accountPurgeInterval,
+	}
+
+	notificationRetention, err := parseDurationEnv("NOTIFICATIONS_RETENTION", 7*24*time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+
+	cfg.Notifications = NotificationsConfig{
+		Retention: notificationRetention,
+		Broker:    strings.ToLower(strings.TrimSpace(getEnv("NOTIFICATIONS_BROKER", NotificationBrokerMemory))),
+	}
+
+	webhookTimeout, err := parseDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return cfg, err
+	}
+
+	webhookMaxAttempts, err := parseIntEnv("WEBHOOK_MAX_ATTEMPTS", 8)
+	if err != nil {
+		return cfg, err
+	}
+
+	cfg.Webhooks = WebhooksConfig{
+		Timeout:     webhookTimeout,
+		MaxAttempts: webhookMaxAttempts,
+	}
+
+	smtpPort, err := parseIntEnv("SMTP_PORT", 587)
+	if err != nil {
+		return cfg, err
+	}
+
+	cfg.Mail = MailConfig{
+		Driver:       strings.ToLower(strings.TrimSpace(getEnv("MAIL_DRIVER", MailDriverLog))),
+		From:         getEnv("MAIL_FROM", "Budget Planner <noreply@localhost>"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     smtpPort,
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+	}
+
+	oidcTimeout, err := parseDurationEnv("OIDC_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return cfg, err
+	}
+
+	cfg.OIDC = OIDCConfig{
+		IssuerURL:    strings.TrimRight(strings.TrimSpace(getEnv("OIDC_ISSUER_URL", "")), "/"),
+		ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+		ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:  getEnv("OIDC_REDIRECT_URL", cfg.AppURL+"/auth/oidc/callback"),
+		Timeout:      oidcTimeout,
+	}
+
 	if err := cfg.validate(); err != nil {
 		return cfg, err
 	}
@@ -261,8 +508,23 @@ func (c Config) validate() error {
 		return fmt.Errorf("DB_MAX_IDLE_CONNS cannot exceed DB_MAX_OPEN_CONNS")
 	}
 
-	if c.Auth.JWTSecret == "" {
-		return fmt.Errorf("JWT_SECRET is required")
+	switch c.Auth.SigningAlgorithm {
+	case JWTAlgorithmHS256:
+		if c.Auth.JWTSecret == "" {
+			return fmt.Errorf("JWT_SECRET is required")
+		}
+	case JWTAlgorithmRS256, JWTAlgorithmEdDSA:
+		if len(c.Auth.KeyEncryptionKey) != 32 {
+			return fmt.Errorf("JWT_KEY_ENCRYPTION_KEY must be 32 bytes encoded in base64 when JWT_SIGNING_ALG is RS256 or EdDSA")
+		}
+		if c.Auth.KeyRotation < time.Hour {
+			return fmt.Errorf("JWT_KEY_ROTATION_INTERVAL must be at least 1h")
+		}
+		if c.Scheduler.SigningKeyInterval <= 0 || c.Scheduler.SigningKeyInterval > 5*time.Minute {
+			return fmt.Errorf("SCHEDULER_SIGNING_KEY_INTERVAL must be between 0 and 5m")
+		}
+	default:
+		return fmt.Errorf("JWT_SIGNING_ALG must be HS256, RS256 or EdDSA")
 	}
 
 	if c.Auth.AccessTokenTTL <= 0 {
@@ -273,6 +535,14 @@ func (c Config) validate() error {
 		return fmt.Errorf("JWT_REFRESH_TTL must be greater than 0")
 	}
 
+	if c.Auth.LockoutThreshold <= 0 {
+		return fmt.Errorf("AUTH_LOCKOUT_THRESHOLD must be greater than 0")
+	}
+
+	if c.Auth.LockoutDuration <= 0 {
+		return fmt.Errorf("AUTH_LOCKOUT_DURATION must be greater than 0")
+	}
+
 	if c.Auth.RateLimitPerMinute <= 0 {
 		return fmt.Errorf("AUTH_RATE_LIMIT_PER_MINUTE must be greater than 0")
 	}
@@ -293,9 +563,161 @@ func (c Config) validate() error {
 		return fmt.Errorf("AI_MAX_OUTPUT_TOKENS must be greater than 0")
 	}
 
+	if c.AI.RepairAttempts < 0 {
+		return fmt.Errorf("AI_REPAIR_ATTEMPTS must not be negative")
+	}
+
+	if c.AI.RequestTimeout <= 0 || c.AI.RequestTimeout >= c.Server.WriteTimeout {
+		return fmt.Errorf("AI_REQUEST_TIMEOUT must be greater than 0 and less than SERVER_WRITE_TIMEOUT")
+	}
+
+	if c.AI.BreakerThreshold < 0 {
+		return fmt.Errorf("AI_BREAKER_THRESHOLD must not be negative")
+	}
+
+	if c.AI.BreakerCooldown < 0 {
+		return fmt.Errorf("AI_BREAKER_COOLDOWN must not be negative")
+	}
+
+	if !isAIProvider(c.AI.Provider) {
+		return fmt.Errorf("AI_PROVIDER must be gemini, groq, openai or ollama")
+	}
+
+	seen := map[string]bool{c.AI.Provider: true}
+	for _, fallback := range c.AI.Fallbacks {
+		if !isAIProvider(fallback.Name) {
+			return fmt.Errorf("AI_FALLBACK_PROVIDERS must contain only gemini, groq, openai or ollama")
+		}
+		if seen[fallback.Name] {
+			return fmt.Errorf("AI_FALLBACK_PROVIDERS must not repeat providers or AI_PROVIDER")
+		}
+		seen[fallback.Name] = true
+	}
+
+	if c.Notifications.Broker != NotificationBrokerMemory && c.Notifications.Broker != NotificationBrokerPostgres {
+		return fmt.Errorf("NOTIFICATIONS_BROKER must be memory or postgres")
+	}
+
+	if c.Mail.Driver != MailDriverLog && c.Mail.Driver != MailDriverSMTP {
+		return fmt.Errorf("MAIL_DRIVER must be log or smtp")
+	}
+
+	if c.Mail.Driver == MailDriverSMTP && c.Mail.SMTPHost == "" {
+		return fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER is smtp")
+	}
+
+	if c.OIDC.IssuerURL != "" && c.OIDC.ClientID == "" {
+		return fmt.Errorf("OIDC_CLIENT_ID is required when OIDC_ISSUER_URL is set")
+	}
+
 	return nil
 }
 
+// Chain возвращает провайдеров AI в порядке опроса: основной, затем резервные.
+func (c AIConfig) Chain() []AIProviderConfig {
+	chain := []AIProviderConfig{{
+		Name:         c.Provider,
+		APIKey:       c.APIKey,
+		BaseURL:      c.BaseURL,
+		Model:        c.Model,
+		Timeout:      c.Timeout,
+		Organization: c.Organization,
+		Headers:      c.Headers,
+		JSONResponse: c.JSONResponse,
+	}}
+	return append(chain, c.Fallbacks...)
+}
+
+// loadAIFallbacks читает резервных провайдеров из AI_FALLBACK_PROVIDERS.
+// Настройки провайдера берутся из переменных с префиксом AI_<ИМЯ>_.
+func loadAIFallbacks(defaultTimeout time.Duration) ([]AIProviderConfig, error) {
+	names := parseCSVEnv("AI_FALLBACK_PROVIDERS")
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	fallbacks := make([]AIProviderConfig, 0, len(names))
+	for _, name := range names {
+		fallback, err := loadAIProvider(name, "AI_"+strings.ToUpper(name)+"_", defaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+
+	return fallbacks, nil
+}
+
+// loadAIProvider читает настройки провайдера из <prefix>API_KEY, BASE_URL, MODEL,
+// TIMEOUT, ORGANIZATION, HEADERS и JSON_RESPONSE.
+func loadAIProvider(name, prefix string, defaultTimeout time.Duration) (AIProviderConfig, error) {
+	defaultBaseURL, defaultModel := aiProviderDefaults(name)
+
+	timeout, err := parseDurationEnv(prefix+"TIMEOUT", defaultTimeout)
+	if err != nil {
+		return AIProviderConfig{}, err
+	}
+
+	headers, err := parseHeadersEnv(prefix + "HEADERS")
+	if err != nil {
+		return AIProviderConfig{}, err
+	}
+
+	jsonResponse, err := parseBoolEnv(prefix+"JSON_RESPONSE", false)
+	if err != nil {
+		return AIProviderConfig{}, err
+	}
+
+	apiKey := getEnv(prefix+"API_KEY", "")
+	if apiKey == "" && name == AIProviderGemini {
+		apiKey = getEnv("GEMINI_API_KEY", "")
+	}
+
+	return AIProviderConfig{
+		Name:         name,
+		APIKey:       apiKey,
+		BaseURL:      getEnv(prefix+"BASE_URL", defaultBaseURL),
+		Model:        getEnv(prefix+"MODEL", defaultModel),
+		Timeout:      timeout,
+		Organization: getEnv(prefix+"ORGANIZATION", ""),
+		Headers:      headers,
+		JSONResponse: jsonResponse,
+	}, nil
+}
+
+// aiProviderDefaults возвращает адрес API и модель провайдера по умолчанию.
+func aiProviderDefaults(name string) (string, string) {
+	switch name {
+	case AIProviderGemini:
+		return "https://generativelanguage.googleapis.com/v1beta", "gemini-1.5-flash"
+	case AIProviderOpenAI:
+		return "https://api.openai.com/v1", "gpt-4o-mini"
+	case AIProviderOllama:
+		return "http://localhost:11434", "llama3.1"
+	default:
+		return "https://api.groq.com/openai/v1", "llama-3.1-8b-instant"
+	}
+}
+
+func isAIProvider(name string) bool {
+	switch name {
+	case AIProviderGemini, AIProviderGroq, AIProviderOpenAI, AIProviderOllama:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseJWTAlgorithm приводит название алгоритма подписи к каноничному виду без учета регистра.
+func parseJWTAlgorithm(value string) string {
+	for _, algorithm := range []string{JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmEdDSA} {
+		if strings.EqualFold(strings.TrimSpace(value), algorithm) {
+			return algorithm
+		}
+	}
+	return value
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -340,6 +762,90 @@ func parseDurationEnv(key string, fallback time.Duration) (time.Duration, error)
 	return parsed, nil
 }
 
+func parseBase64Env(key string) ([]byte, error) {
+	value := strings.TrimSpace(getEnv(key, ""))
+	if value == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be base64 encoded: %w", key, err)
+	}
+
+	return decoded, nil
+}
+
+func parseBoolEnv(key string, fallback bool) (bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+// parseHeadersEnv разбирает заголовки вида "Name=value,Other=value".
+func parseHeadersEnv(key string) (map[string]string, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	headers := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, headerValue, found := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("%s must be a list of Name=value pairs", key)
+		}
+		headers[name] = strings.TrimSpace(headerValue)
+	}
+
+	return headers, nil
+}
+
+// parseCIDRListEnv разбирает список сетей через запятую; отдельный адрес
+// считается сетью из одного адреса.
+func parseCIDRListEnv(key string) ([]*net.IPNet, error) {
+	values := parseCSVEnv(key)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("%s must be a list of IP addresses or CIDRs", key)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a list of IP addresses or CIDRs: %w", key, err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
 func parseCSVEnv(key string) []string {
 	value, ok := os.LookupEnv(key)
 	if !ok {
@@ -2,7 +2,9 @@ package config
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestParseCSVEnv проверяет разбор списка email из ENV.
@@ -24,3 +26,161 @@ func TestParseCSVEnvMissing(t *testing.T) {
 		t.Fatalf("expected nil, got %v", got)
 	}
 }
+
+// TestLoadAIFallbacks проверяет чтение резервных провайдеров AI и их значений по умолчанию.
+func TestLoadAIFallbacks(t *testing.T) {
+	t.Setenv("AI_FALLBACK_PROVIDERS", "Groq, gemini")
+	t.Setenv("AI_GROQ_API_KEY", "groq-key")
+	t.Setenv("AI_GROQ_MODEL", "llama-3.3-70b-versatile")
+	t.Setenv("AI_GROQ_TIMEOUT", "5s")
+	t.Setenv("GEMINI_API_KEY", "gemini-key")
+
+	got, err := loadAIFallbacks(20 * time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []AIProviderConfig{
+		{
+			Name:    AIProviderGroq,
+			APIKey:  "groq-key",
+			BaseURL: "https://api.groq.com/openai/v1",
+			Model:   "llama-3.3-70b-versatile",
+			Timeout: 5 * time.Second,
+		},
+		{
+			Name:    AIProviderGemini,
+			APIKey:  "gemini-key",
+			BaseURL: "https://generativelanguage.googleapis.com/v1beta",
+			Model:   "gemini-1.5-flash",
+			Timeout: 20 * time.Second,
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+// TestParseHeadersEnv проверяет разбор дополнительных заголовков AI-провайдера.
+func TestParseHeadersEnv(t *testing.T) {
+	t.Setenv("AI_HEADERS", " X-Title = Budget Planner ,HTTP-Referer=https://example.com, ")
+
+	got, err := parseHeadersEnv("AI_HEADERS")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"X-Title": "Budget Planner", "HTTP-Referer": "https://example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	t.Setenv("AI_HEADERS", "X-Title")
+	if _, err := parseHeadersEnv("AI_HEADERS"); err == nil {
+		t.Fatalf("expected error for header without value")
+	}
+}
+
+// TestValidateAINegativeSettings проверяет, что отрицательные настройки
+// исправлений и отключения провайдеров AI отклоняются.
+func TestValidateAINegativeSettings(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]func(*Config){
+		"AI_REPAIR_ATTEMPTS":   func(c *Config) { c.AI.RepairAttempts = -2 },
+		"AI_BREAKER_THRESHOLD": func(c *Config) { c.AI.BreakerThreshold = -1 },
+		"AI_BREAKER_COOLDOWN":  func(c *Config) { c.AI.BreakerCooldown = -time.Second },
+	}
+	for name, mutate := range cases {
+		invalid := cfg
+		mutate(&invalid)
+		if err := invalid.validate(); err == nil || !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected %s error, got %v", name, err)
+		}
+	}
+
+	cfg.AI.RepairAttempts = 0
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("expected zero repair attempts to be valid, got %v", err)
+	}
+}
+
+// TestValidateAIProviders проверяет, что основной провайдер не повторяется
+// среди резервных, а общий таймаут AI короче таймаута записи ответа.
+func TestValidateAIProviders(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("AI_PROVIDER", AIProviderGroq)
+	t.Setenv("AI_FALLBACK_PROVIDERS", "groq")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "AI_FALLBACK_PROVIDERS") {
+		t.Fatalf("expected duplicate provider error, got %v", err)
+	}
+
+	t.Setenv("AI_FALLBACK_PROVIDERS", "gemini")
+	t.Setenv("SERVER_WRITE_TIMEOUT", "10s")
+	t.Setenv("AI_REQUEST_TIMEOUT", "10s")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "AI_REQUEST_TIMEOUT") {
+		t.Fatalf("expected request timeout error, got %v", err)
+	}
+
+	t.Setenv("AI_REQUEST_TIMEOUT", "9s")
+	if _, err := Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestParseCIDRListEnv проверяет разбор сетей доверенных прокси.
+func TestParseCIDRListEnv(t *testing.T) {
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.10,::1")
+
+	got, err := parseCIDRListEnv("SERVER_TRUSTED_PROXIES")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"10.0.0.0/8", "192.0.2.10/32", "::1/128"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	t.Setenv("SERVER_TRUSTED_PROXIES", "proxy.local")
+	if _, err := parseCIDRListEnv("SERVER_TRUSTED_PROXIES"); err == nil {
+		t.Fatalf("expected error for host name")
+	}
+}
+
+// TestValidateKeyEncryptionKey проверяет, что асимметричная подпись требует
+// ключ шифрования ключей подписи длиной 32 байта.
+func TestValidateKeyEncryptionKey(t *testing.T) {
+	t.Setenv("JWT_SIGNING_ALG", "EdDSA")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "JWT_KEY_ENCRYPTION_KEY") {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+
+	t.Setenv("JWT_KEY_ENCRYPTION_KEY", "c2hvcnQ=")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "JWT_KEY_ENCRYPTION_KEY") {
+		t.Fatalf("expected short key error, got %v", err)
+	}
+
+	t.Setenv("JWT_KEY_ENCRYPTION_KEY", "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Auth.KeyEncryptionKey) != 32 {
+		t.Fatalf("expected 32-byte key, got %d", len(cfg.Auth.KeyEncryptionKey))
+	}
+}
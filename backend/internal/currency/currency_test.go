@@ -0,0 +1,26 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestNormalize проверяет приведение кода валюты к формату ISO 4217.
+func TestNormalize(t *testing.T) {
+	got, err := Normalize(" usd ")
+	if err != nil {
+		t.Fatalf("expected valid code, got %v", err)
+	}
+	if got != "USD" {
+		t.Fatalf("expected USD, got %s", got)
+	}
+}
+
+// TestNormalizeUnknown проверяет отказ для неизвестных кодов.
+func TestNormalizeUnknown(t *testing.T) {
+	for _, code := range []string{"", "US", "USDT", "XYZ", "руб"} {
+		if _, err := Normalize(code); !errors.Is(err, ErrUnknown) {
+			t.Fatalf("expected ErrUnknown for %q, got %v", code, err)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+type AccountHandler struct {
+	Auth          *AuthHandler
+	Accounts      *repository.AccountRepository
+	Plans         *repository.PlanRepository
+	DeletionGrace time.Duration
+}
+
+// NewAccountHandler создает обработчик выгрузки данных и удаления учетной
+// записи. deletionGrace — срок, в течение которого удаление можно отменить входом.
+func NewAccountHandler(authHandler *AuthHandler, accounts *repository.AccountRepository, plans *repository.PlanRepository, deletionGrace time.Duration) *AccountHandler {
+	return &AccountHandler{
+		Auth:          authHandler,
+		Accounts:      accounts,
+		Plans:         plans,
+		DeletionGrace: deletionGrace,
+	}
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" validate:"max=32"`
+}
+
+type DeleteAccountResponse struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// archiveFile — файл архива выгрузки с содержимым для сериализации в JSON.
+type archiveFile struct {
+	Name    string
+	Content any
+}
+
+// Export выгружает все данные пользователя ZIP-архивом: профиль, собственные
+// планы с категориями, расходами и заметками, траты, анкеты и логи AI-запросов.
+func (h *AccountHandler) Export(c echo.Context) error {
+	user, err := h.Auth.currentUser(c)
+	if err != nil {
+		return currentUserError(c, err)
+	}
+
+	ctx := c.Request().Context()
+
+	plans, err := h.Accounts.ListOwnedPlans(ctx, user.ID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	files := []archiveFile{{Name: "profile.json", Content: user}}
+	for _, plan := range plans {
+		detail, err := buildPlanDetailResponse(ctx, h.Plans, plan)
+		if err != nil {
+			return serverError(c)
+		}
+		files = append(files, archiveFile{Name: "plans/" + plan.ID.String() + ".json", Content: detail})
+	}
+
+	transactions, err := h.Accounts.ListTransactions(ctx, user.ID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	inputs, err := h.Accounts.ListAIInputs(ctx, user.ID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	records, err := h.Accounts.ListAIRequests(ctx, user.ID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	requests := make([]AdminAIRequestResponse, 0, len(records))
+	for _, record := range records {
+		requests = append(requests, toAIRequestResponse(record, true))
+	}
+
+	files = append(files,
+		archiveFile{Name: "transactions.json", Content: transactions},
+		archiveFile{Name: "ai_inputs.json", Content: inputs},
+		archiveFile{Name: "ai_requests.json", Content: requests},
+	)
+
+	archive, err := buildArchive(files, time.Now())
+	if err != nil {
+		return serverError(c)
+	}
+
+	filename := "budget-planner-export-" + time.Now().UTC().Format("2006-01-02") + ".zip"
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\""+filename+"\"")
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
+
+// Delete помечает учетную запись удаленной и завершает все сессии. Данные
+// удаляются окончательно через DeletionGrace; вход до этого срока отменяет
+// удаление. Если у пользователя есть пароль или второй фактор, они проверяются.
+func (h *AccountHandler) Delete(c echo.Context) error {
+	user, err := h.Auth.currentUser(c)
+	if err != nil {
+		return currentUserError(c, err)
+	}
+
+	var req DeleteAccountRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	if user.PasswordHash != "" {
+		if err = auth.ComparePassword(user.PasswordHash, strings.TrimSpace(req.Password)); err != nil {
+			return unauthorized(c)
+		}
+	}
+
+	if user.TOTPEnabledAt != nil {
+		if err = h.Auth.verifySecondFactor(c.Request().Context(), user, req.Code); err != nil {
+			if errors.Is(err, errInvalidSecondFactor) {
+				return unauthorized(c)
+			}
+			return serverError(c)
+		}
+	}
+
+	deletedAt, err := h.Accounts.ScheduleDeletion(c.Request().Context(), user.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "user not found")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusAccepted, DeleteAccountResponse{
+		DeletedAt: deletedAt,
+		PurgeAt:   deletedAt.Add(h.DeletionGrace),
+	})
+}
+
+// buildArchive упаковывает файлы в ZIP, сериализуя содержимое в JSON с отступами.
+func buildArchive(files []archiveFile, modified time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		entry, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     file.Name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
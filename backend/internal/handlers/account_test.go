@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const testDeletionGrace = 30 * 24 * time.Hour
+
+// TestBuildArchive проверяет, что каждый файл выгрузки попадает в архив как JSON.
+func TestBuildArchive(t *testing.T) {
+	files := []archiveFile{
+		{Name: "profile.json", Content: map[string]string{"email": "user@example.com"}},
+		{Name: "plans/plan-1.json", Content: []int{1, 2, 3}},
+	}
+
+	data, err := buildArchive(files, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("build archive: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	if len(reader.File) != len(files) {
+		t.Fatalf("expected %d files, got %d", len(files), len(reader.File))
+	}
+
+	for i, file := range reader.File {
+		if file.Name != files[i].Name {
+			t.Fatalf("expected %s, got %s", files[i].Name, file.Name)
+		}
+
+		entry, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(entry)
+		entry.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+
+		expected, _ := json.Marshal(files[i].Content)
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, content); err != nil {
+			t.Fatalf("%s is not valid JSON: %v", file.Name, err)
+		}
+		if compact.String() != string(expected) {
+			t.Fatalf("unexpected %s content: %s", file.Name, compact.String())
+		}
+	}
+}
+
+// TestDeleteAccountChecksPassword проверяет, что без верного пароля учетная
+// запись не помечается удаленной.
+func TestDeleteAccountChecksPassword(t *testing.T) {
+	authHandler, _, db := newTestAuthHandler(t)
+	handler := NewAccountHandler(authHandler, repository.NewAccountRepository(db), repository.NewPlanRepository(db), testDeletionGrace)
+
+	registered := registerTestUser(t, authHandler, "delete-password@example.com", "Pass1234")
+
+	rec := serveJSON(t, handler.Delete, http.MethodDelete, `{"password":"WrongPass1"}`, asUser(registered.User.ID))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: expected 401, got %d", rec.Code)
+	}
+
+	user, err := authHandler.Users.GetByID(context.Background(), registered.User.ID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if user.DeletedAt != nil {
+		t.Fatal("expected the account to stay active after a wrong password")
+	}
+}
+
+// TestDeleteAccountChecksSecondFactor проверяет, что при включенном TOTP
+// удаление требует действующий код.
+func TestDeleteAccountChecksSecondFactor(t *testing.T) {
+	authHandler, _, db := newTestAuthHandler(t)
+	handler := NewAccountHandler(authHandler, repository.NewAccountRepository(db), repository.NewPlanRepository(db), testDeletionGrace)
+	ctx := context.Background()
+
+	registered := registerTestUser(t, authHandler, "delete-totp@example.com", "Pass1234")
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("generate secret: %v", err)
+	}
+	if err := authHandler.Users.SetPendingTOTP(ctx, registered.User.ID, secret); err != nil {
+		t.Fatalf("set pending totp: %v", err)
+	}
+	if _, err := authHandler.Users.EnableTOTP(ctx, registered.User.ID, 0, nil); err != nil {
+		t.Fatalf("enable totp: %v", err)
+	}
+
+	for _, body := range []string{`{"password":"Pass1234"}`, `{"password":"Pass1234","code":"000000"}`} {
+		rec := serveJSON(t, handler.Delete, http.MethodDelete, body, asUser(registered.User.ID))
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: expected 401, got %d", body, rec.Code)
+		}
+	}
+
+	code, err := auth.TOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf("totp code: %v", err)
+	}
+	rec := serveJSON(t, handler.Delete, http.MethodDelete, `{"password":"Pass1234","code":"`+code+`"}`, asUser(registered.User.ID))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("valid code: expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// TestDeleteAccountRevokesTokensAndLoginCancels проверяет, что удаление
+// отзывает refresh-токены и персональные токены, а вход отменяет удаление.
+func TestDeleteAccountRevokesTokensAndLoginCancels(t *testing.T) {
+	authHandler, _, db := newTestAuthHandler(t)
+	handler := NewAccountHandler(authHandler, repository.NewAccountRepository(db), repository.NewPlanRepository(db), testDeletionGrace)
+	personalTokens := repository.NewPersonalTokenRepository(db)
+	ctx := context.Background()
+
+	registered := registerTestUser(t, authHandler, "delete-tokens@example.com", "Pass1234")
+	if _, err := personalTokens.Create(ctx, models.PersonalAccessToken{
+		UserID:      registered.User.ID,
+		Name:        "cli",
+		TokenPrefix: "bp_test",
+		TokenHash:   "pat-hash",
+		Scopes:      []string{"read"},
+	}); err != nil {
+		t.Fatalf("create personal token: %v", err)
+	}
+
+	rec := serveJSON(t, handler.Delete, http.MethodDelete, `{"password":"Pass1234"}`, asUser(registered.User.ID))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("delete: expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response DeleteAccountResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode delete response: %v", err)
+	}
+	if !response.PurgeAt.Equal(response.DeletedAt.Add(testDeletionGrace)) {
+		t.Fatalf("expected purge_at = deleted_at + grace, got %+v", response)
+	}
+
+	rec = serveJSON(t, authHandler.Refresh, http.MethodPost, refreshRequest(registered.RefreshToken))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after delete: expected 401, got %d", rec.Code)
+	}
+	if _, _, err := personalTokens.AuthenticatePersonalToken(ctx, "pat-hash"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected personal token to be revoked, got %v", err)
+	}
+
+	rec = serveJSON(t, authHandler.Login, http.MethodPost, `{"email":"delete-tokens@example.com","password":"Pass1234"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login after delete: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	user, err := authHandler.Users.GetByID(ctx, registered.User.ID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if user.DeletedAt != nil {
+		t.Fatal("expected login to cancel the deletion")
+	}
+}
@@ -1,34 +1,44 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
 	"example.com/ai-budget-planner/backend/internal/repository"
 )
 
 type AdminHandler struct {
-	Repo *repository.AdminRepository
+	Repo  *repository.AdminRepository
+	Rates *repository.ExchangeRateRepository
+	Roles *repository.RoleRepository
 }
 
 // NewAdminHandler создает обработчик админских эндпоинтов.
-func NewAdminHandler(repo *repository.AdminRepository) *AdminHandler {
-	return &AdminHandler{Repo: repo}
+func NewAdminHandler(repo *repository.AdminRepository, rates *repository.ExchangeRateRepository, roles *repository.RoleRepository) *AdminHandler {
+	return &AdminHandler{Repo: repo, Rates: rates, Roles: roles}
 }
 
 type AdminUserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	Name      *string   `json:"name,omitempty"`
-	CreatedAt string    `json:"created_at"`
-	UpdatedAt string    `json:"updated_at"`
+	ID                  uuid.UUID `json:"id"`
+	Email               string    `json:"email"`
+	Name                *string   `json:"name,omitempty"`
+	Roles               []string  `json:"roles"`
+	FailedLoginAttempts int       `json:"failed_login_attempts"`
+	Locked              bool      `json:"locked"`
+	LockedUntil         *string   `json:"locked_until,omitempty"`
+	DeletedAt           *string   `json:"deleted_at,omitempty"`
+	CreatedAt           string    `json:"created_at"`
+	UpdatedAt           string    `json:"updated_at"`
 }
 
 type AdminUsersResponse struct {
@@ -87,15 +97,28 @@ func (h *AdminHandler) ListUsers(c echo.Context) error {
 		return serverError(c)
 	}
 
+	now := time.Now()
 	response := make([]AdminUserResponse, 0, len(users))
 	for _, user := range users {
-		response = append(response, AdminUserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Name:      user.Name,
-			CreatedAt: user.CreatedAt.Format(timeLayout),
-			UpdatedAt: user.UpdatedAt.Format(timeLayout),
-		})
+		item := AdminUserResponse{
+			ID:                  user.ID,
+			Email:               user.Email,
+			Name:                user.Name,
+			Roles:               user.Roles,
+			FailedLoginAttempts: user.FailedLogins,
+			CreatedAt:           user.CreatedAt.Format(timeLayout),
+			UpdatedAt:           user.UpdatedAt.Format(timeLayout),
+		}
+		if user.LockedUntil != nil && user.LockedUntil.After(now) {
+			lockedUntil := user.LockedUntil.Format(timeLayout)
+			item.Locked = true
+			item.LockedUntil = &lockedUntil
+		}
+		if user.DeletedAt != nil {
+			deletedAt := user.DeletedAt.Format(timeLayout)
+			item.DeletedAt = &deletedAt
+		}
+		response = append(response, item)
 	}
 
 	return c.JSON(http.StatusOK, AdminUsersResponse{
@@ -104,6 +127,23 @@ func (h *AdminHandler) ListUsers(c echo.Context) error {
 	})
 }
 
+// UnlockUser снимает блокировку входа пользователя.
+func (h *AdminHandler) UnlockUser(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid user id")
+	}
+
+	if err := h.Repo.UnlockUser(c.Request().Context(), userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "user not found")
+		}
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 // ListAIRequests возвращает логи AI-запросов с фильтрами.
 func (h *AdminHandler) ListAIRequests(c echo.Context) error {
 	limit, offset, err := parsePagination(c, 50, 200)
@@ -140,6 +180,9 @@ func (h *AdminHandler) ListAIRequests(c echo.Context) error {
 		}
 		includePayloads = parsed
 	}
+	if includePayloads && !hasPermission(c, models.PermissionAIRequestsPayloads) {
+		return forbidden(c)
+	}
 
 	requests, err := h.Repo.ListAIRequests(c.Request().Context(), filter, limit, offset, includePayloads)
 	if err != nil {
@@ -153,28 +196,7 @@ func (h *AdminHandler) ListAIRequests(c echo.Context) error {
 
 	response := make([]AdminAIRequestResponse, 0, len(requests))
 	for _, req := range requests {
-		item := AdminAIRequestResponse{
-			ID:           req.ID,
-			UserID:       req.UserID,
-			RequestType:  req.RequestType,
-			Provider:     req.Provider,
-			Model:        req.Model,
-			Success:      req.Success,
-			ErrorMessage: req.ErrorMessage,
-			CreatedAt:    req.CreatedAt.Format(timeLayout),
-		}
-
-		if includePayloads {
-			item.Prompt = req.Prompt
-			if len(req.RequestPayload) > 0 {
-				item.RequestPayload = json.RawMessage(req.RequestPayload)
-			}
-			if len(req.ResponsePayload) > 0 {
-				item.ResponsePayload = json.RawMessage(req.ResponsePayload)
-			}
-			item.RawResponse = req.RawResponse
-		}
-		response = append(response, item)
+		response = append(response, toAIRequestResponse(req, includePayloads))
 	}
 
 	return c.JSON(http.StatusOK, AdminAIRequestsResponse{
@@ -183,6 +205,34 @@ func (h *AdminHandler) ListAIRequests(c echo.Context) error {
 	})
 }
 
+// toAIRequestResponse преобразует лог AI-запроса в ответ API. Промпт и
+// ответы модели включаются только при includePayloads.
+func toAIRequestResponse(req repository.AIRequestRecord, includePayloads bool) AdminAIRequestResponse {
+	item := AdminAIRequestResponse{
+		ID:           req.ID,
+		UserID:       req.UserID,
+		RequestType:  req.RequestType,
+		Provider:     req.Provider,
+		Model:        req.Model,
+		Success:      req.Success,
+		ErrorMessage: req.ErrorMessage,
+		CreatedAt:    req.CreatedAt.Format(timeLayout),
+	}
+
+	if includePayloads {
+		item.Prompt = req.Prompt
+		if len(req.RequestPayload) > 0 {
+			item.RequestPayload = json.RawMessage(req.RequestPayload)
+		}
+		if len(req.ResponsePayload) > 0 {
+			item.ResponsePayload = json.RawMessage(req.ResponsePayload)
+		}
+		item.RawResponse = req.RawResponse
+	}
+
+	return item
+}
+
 // Usage возвращает агрегированную статистику использования.
 func (h *AdminHandler) Usage(c echo.Context) error {
 	days := 7
@@ -223,17 +273,15 @@ func (h *AdminHandler) Usage(c echo.Context) error {
 	})
 }
 
-// AdminMiddleware ограничивает доступ к админским роутам по email.
-func AdminMiddleware(users *repository.UserRepository, emails []string) echo.MiddlewareFunc {
-	allowed := make(map[string]struct{}, len(emails))
-	for _, email := range emails {
-		trimmed := strings.ToLower(strings.TrimSpace(email))
-		if trimmed == "" {
-			continue
-		}
-		allowed[trimmed] = struct{}{}
-	}
+// PermissionSource загружает права пользователя.
+type PermissionSource interface {
+	PermissionsForUser(ctx context.Context, userID uuid.UUID) ([]models.Permission, error)
+}
 
+// AdminAccess загружает права пользователя из его ролей и пускает в админку
+// только пользователей хотя бы с одним правом. Конкретные права роутов
+// проверяет RequirePermission.
+func AdminAccess(source PermissionSource) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			userID, ok := auth.UserIDFromContext(c)
@@ -241,28 +289,45 @@ func AdminMiddleware(users *repository.UserRepository, emails []string) echo.Mid
 				return unauthorized(c)
 			}
 
-			if len(allowed) == 0 {
-				return forbidden(c)
-			}
-
-			user, err := users.GetByID(c.Request().Context(), userID)
+			permissions, err := source.PermissionsForUser(c.Request().Context(), userID)
 			if err != nil {
-				if errors.Is(err, repository.ErrNotFound) {
-					return forbidden(c)
-				}
 				return serverError(c)
 			}
-
-			email := strings.ToLower(strings.TrimSpace(user.Email))
-			if _, ok := allowed[email]; !ok {
+			if len(permissions) == 0 {
 				return forbidden(c)
 			}
 
+			c.Set(contextPermissionsKey, permissions)
 			return next(c)
 		}
 	}
 }
 
+// RequirePermission пропускает запрос, если у пользователя есть право.
+// Должен стоять после AdminAccess.
+func RequirePermission(permission models.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !hasPermission(c, permission) {
+				return forbidden(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+const contextPermissionsKey = "permissions"
+
+func hasPermission(c echo.Context, permission models.Permission) bool {
+	permissions, _ := c.Get(contextPermissionsKey).([]models.Permission)
+	for _, granted := range permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
 func parsePagination(c echo.Context, defaultLimit, maxLimit int) (int, int, error) {
 	limit := defaultLimit
 	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
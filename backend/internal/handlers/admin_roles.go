@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+// ListRoles возвращает роли с их правами.
+func (h *AdminHandler) ListRoles(c echo.Context) error {
+	roles, err := h.Roles.ListRoles(c.Request().Context())
+	if err != nil {
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]models.Role{"roles": roles})
+}
+
+// ListUserRoles возвращает роли пользователя.
+func (h *AdminHandler) ListUserRoles(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid user id")
+	}
+
+	roles, err := h.Roles.RolesForUser(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "user not found")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]string{"roles": roles})
+}
+
+// GrantRole выдает пользователю роль.
+func (h *AdminHandler) GrantRole(c echo.Context) error {
+	adminID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	userID, role, err := parseUserRole(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	if err := h.Roles.Grant(c.Request().Context(), userID, role, adminID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "user or role not found")
+		}
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RevokeRole забирает у пользователя роль. Последнего пользователя с правом
+// управлять ролями лишить его нельзя.
+func (h *AdminHandler) RevokeRole(c echo.Context) error {
+	userID, role, err := parseUserRole(c)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	if err := h.Roles.Revoke(c.Request().Context(), userID, role); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return notFound(c, "role not granted")
+		case errors.Is(err, repository.ErrConflict):
+			return conflict(c, "cannot revoke the last role manager")
+		default:
+			return serverError(c)
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func parseUserRole(c echo.Context) (uuid.UUID, string, error) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, "", errors.New("invalid user id")
+	}
+
+	role := strings.ToLower(strings.TrimSpace(c.Param("role")))
+	if role == "" || len(role) > 64 {
+		return uuid.Nil, "", errors.New("invalid role")
+	}
+
+	return userID, role, nil
+}
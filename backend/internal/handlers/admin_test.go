@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+type stubPermissions map[uuid.UUID][]models.Permission
+
+func (s stubPermissions) PermissionsForUser(_ context.Context, userID uuid.UUID) ([]models.Permission, error) {
+	return s[userID], nil
+}
+
+// TestAdminPermissions проверяет доступ к админским роутам по правам ролей.
+func TestAdminPermissions(t *testing.T) {
+	support := uuid.New()
+	stranger := uuid.New()
+	source := stubPermissions{support: {models.PermissionUsersRead}}
+
+	e := echo.New()
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	withUser := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, err := uuid.Parse(c.Request().Header.Get("X-User"))
+			if err == nil {
+				c.Set(auth.ContextUserIDKey, userID)
+			}
+			return next(c)
+		}
+	}
+	admin := e.Group("/admin", withUser, AdminAccess(source))
+	admin.GET("/users", ok, RequirePermission(models.PermissionUsersRead))
+	admin.GET("/roles", ok, RequirePermission(models.PermissionRolesManage))
+
+	cases := []struct {
+		path   string
+		userID uuid.UUID
+		want   int
+	}{
+		{"/admin/users", support, http.StatusNoContent},
+		{"/admin/roles", support, http.StatusForbidden},
+		{"/admin/users", stranger, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("X-User", tc.userID.String())
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d", tc.path, tc.want, rec.Code)
+		}
+	}
+}
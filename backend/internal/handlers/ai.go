@@ -15,6 +15,7 @@ import (
 
 	"example.com/ai-budget-planner/backend/internal/ai"
 	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/currency"
 	"example.com/ai-budget-planner/backend/internal/models"
 	"example.com/ai-budget-planner/backend/internal/notifications"
 	"example.com/ai-budget-planner/backend/internal/repository"
@@ -30,18 +31,21 @@ type AIHandler struct {
 	Plans    *repository.PlanRepository
 	Notes    *repository.NoteRepository
 	AIRepo   *repository.AIRepository
+	Users    *repository.UserRepository
 	Notifier *notifications.Hub
 	Provider string
 	Model    string
 }
 
-// NewAIHandler создает обработчик AI-запросов.
-func NewAIHandler(service *ai.Service, plans *repository.PlanRepository, notes *repository.NoteRepository, aiRepo *repository.AIRepository, notifier *notifications.Hub, provider, model string) *AIHandler {
+// NewAIHandler создает обработчик AI-запросов. Валюта и период плана по
+// умолчанию берутся из настроек пользователя в users.
+func NewAIHandler(service *ai.Service, plans *repository.PlanRepository, notes *repository.NoteRepository, aiRepo *repository.AIRepository, users *repository.UserRepository, notifier *notifications.Hub, provider, model string) *AIHandler {
 	return &AIHandler{
 		Service:  service,
 		Plans:    plans,
 		Notes:    notes,
 		AIRepo:   aiRepo,
+		Users:    users,
 		Notifier: notifier,
 		Provider: provider,
 		Model:    model,
@@ -49,8 +53,9 @@ func NewAIHandler(service *ai.Service, plans *repository.PlanRepository, notes *
 }
 
 type GeneratePlanRequest struct {
-	PeriodStart string            `json:"period_start" validate:"required"`
-	PeriodEnd   string            `json:"period_end" validate:"required"`
+	PeriodStart string            `json:"period_start"`
+	PeriodEnd   string            `json:"period_end"`
+	Period      string            `json:"period" validate:"omitempty,oneof=month week"`
 	BudgetCents int64             `json:"budget_cents" validate:"gt=0"`
 	Currency    string            `json:"currency"`
 	UserData    AIUserDataRequest `json:"user_data"`
@@ -87,8 +92,7 @@ type AIDebtItem struct {
 }
 
 type AnalyzeSpendingRequest struct {
-	PlanID   string `json:"plan_id" validate:"required"`
-	Currency string `json:"currency"`
+	PlanID string `json:"plan_id" validate:"required"`
 }
 
 // GeneratePlan создает план бюджета на основе данных пользователя.
@@ -106,21 +110,39 @@ func (h *AIHandler) GeneratePlan(c echo.Context) error {
 		return badRequest(c, "validation failed")
 	}
 
-	periodStart, periodEnd, err := parsePeriod(req.PeriodStart, req.PeriodEnd)
+	user, err := h.Users.GetByID(c.Request().Context(), userID)
 	if err != nil {
-		return badRequest(c, err.Error())
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "user not found")
+		}
+		return serverError(c)
+	}
+
+	var periodStart, periodEnd time.Time
+	if strings.TrimSpace(req.PeriodStart) == "" && strings.TrimSpace(req.PeriodEnd) == "" {
+		periodStart, periodEnd = currentPeriod(user, req.Period, time.Now())
+		req.PeriodStart = periodStart.Format(dateLayout)
+		req.PeriodEnd = periodEnd.Format(dateLayout)
+	} else {
+		periodStart, periodEnd, err = parsePeriod(req.PeriodStart, req.PeriodEnd)
+		if err != nil {
+			return badRequest(c, err.Error())
+		}
 	}
 
-	currency := strings.TrimSpace(req.Currency)
-	if currency == "" {
-		currency = "RUB"
+	planCurrency := user.BaseCurrency
+	if strings.TrimSpace(req.Currency) != "" {
+		planCurrency, err = currency.Normalize(req.Currency)
+		if err != nil {
+			return badRequest(c, "currency must be an ISO 4217 code")
+		}
 	}
 
 	input := ai.GeneratePlanInput{
 		PeriodStart: req.PeriodStart,
 		PeriodEnd:   req.PeriodEnd,
 		BudgetCents: req.BudgetCents,
-		Currency:    currency,
+		Currency:    planCurrency,
 		UserData: ai.UserData{
 			Period:            req.UserData.Period,
 			Income:            toAIIncome(req.UserData.Income),
@@ -137,16 +159,21 @@ func (h *AIHandler) GeneratePlan(c echo.Context) error {
 		return serverError(c)
 	}
 
-	aiResponse, prompt, raw, err := h.Service.GeneratePlan(c.Request().Context(), input)
+	aiResponse, attempts, err := h.Service.GeneratePlan(c.Request().Context(), input)
+	if !h.Service.Enabled() {
+		return h.respondOfflinePlan(c, userID, input, aiResponse, periodStart, periodEnd)
+	}
+
+	prompt, result := h.logRepairAttempts(c.Request().Context(), userID, aiRequestGeneratePlan, inputPayload, attempts)
 	responsePayload := []byte(nil)
 	if err == nil {
 		responsePayload, _ = json.Marshal(aiResponse)
 	}
 
 	if err != nil {
-		h.logAIRequest(c.Request().Context(), userID, aiRequestGeneratePlan, prompt, inputPayload, responsePayload, raw, err)
+		h.logAIRequest(c.Request().Context(), userID, aiRequestGeneratePlan, prompt, inputPayload, responsePayload, result, err)
 
-		plan, fallbackErr := h.createFallbackPlan(c.Request().Context(), userID, periodStart, periodEnd, req.BudgetCents)
+		plan, fallbackErr := h.createFallbackPlan(c.Request().Context(), userID, input, periodStart, periodEnd)
 		if fallbackErr != nil {
 			return serverError(c)
 		}
@@ -163,9 +190,9 @@ func (h *AIHandler) GeneratePlan(c echo.Context) error {
 
 	categories, notes, mapErr := mapAIPlan(aiResponse)
 	if mapErr != nil {
-		h.logAIRequest(c.Request().Context(), userID, aiRequestGeneratePlan, prompt, inputPayload, responsePayload, raw, mapErr)
+		h.logAIRequest(c.Request().Context(), userID, aiRequestGeneratePlan, prompt, inputPayload, responsePayload, result, mapErr)
 
-		plan, fallbackErr := h.createFallbackPlan(c.Request().Context(), userID, periodStart, periodEnd, req.BudgetCents)
+		plan, fallbackErr := h.createFallbackPlan(c.Request().Context(), userID, input, periodStart, periodEnd)
 		if fallbackErr != nil {
 			return serverError(c)
 		}
@@ -180,12 +207,12 @@ func (h *AIHandler) GeneratePlan(c echo.Context) error {
 		return c.JSON(http.StatusCreated, response)
 	}
 
-	plan, err := h.Plans.CreateWithDetails(c.Request().Context(), userID, aiResponse.Plan.Title, req.BudgetCents, periodStart, periodEnd, defaultBackgroundColor, true, categories, notes)
+	plan, err := h.Plans.CreateWithDetails(c.Request().Context(), userID, aiResponse.Plan.Title, req.BudgetCents, &planCurrency, periodStart, periodEnd, defaultBackgroundColor, true, categories, notes)
 	if err != nil {
-		h.logAIRequest(c.Request().Context(), userID, aiRequestGeneratePlan, prompt, inputPayload, responsePayload, raw, err)
+		h.logAIRequest(c.Request().Context(), userID, aiRequestGeneratePlan, prompt, inputPayload, responsePayload, result, err)
 
 		if errors.Is(err, repository.ErrBudgetExceeded) || errors.Is(err, repository.ErrInvalid) {
-			plan, fallbackErr := h.createFallbackPlan(c.Request().Context(), userID, periodStart, periodEnd, req.BudgetCents)
+			plan, fallbackErr := h.createFallbackPlan(c.Request().Context(), userID, input, periodStart, periodEnd)
 			if fallbackErr != nil {
 				return serverError(c)
 			}
@@ -202,7 +229,7 @@ func (h *AIHandler) GeneratePlan(c echo.Context) error {
 		return serverError(c)
 	}
 
-	h.logAIRequest(c.Request().Context(), userID, aiRequestGeneratePlan, prompt, inputPayload, responsePayload, raw, nil)
+	h.logAIRequest(c.Request().Context(), userID, aiRequestGeneratePlan, prompt, inputPayload, responsePayload, result, nil)
 	logPlanSource("ai", plan.ID, userID)
 
 	response, err := buildPlanDetailResponse(c.Request().Context(), h.Plans, plan)
@@ -242,9 +269,13 @@ func (h *AIHandler) AnalyzeSpending(c echo.Context) error {
 		return serverError(c)
 	}
 
-	currency := strings.TrimSpace(req.Currency)
-	if currency == "" {
-		currency = "RUB"
+	// Советы перезаписывают AI-заметки плана, поэтому зрителям анализ недоступен.
+	role, err := h.Plans.GetRole(c.Request().Context(), userID, plan.ID)
+	if err != nil {
+		return serverError(c)
+	}
+	if role == models.PlanRoleViewer {
+		return forbidden(c)
 	}
 
 	categories, err := h.Plans.ListCategories(c.Request().Context(), plan.ID)
@@ -262,6 +293,11 @@ func (h *AIHandler) AnalyzeSpending(c echo.Context) error {
 		return serverError(c)
 	}
 
+	itemSpent, err := h.Plans.ListItemSpentCents(c.Request().Context(), categoryIDs)
+	if err != nil {
+		return serverError(c)
+	}
+
 	categoryIndex := make(map[uuid.UUID]int, len(categories))
 	categorySnapshots := make([]ai.CategorySnapshot, 0, len(categories))
 	for _, category := range categories {
@@ -283,24 +319,29 @@ func (h *AIHandler) AnalyzeSpending(c echo.Context) error {
 			AmountCents: item.AmountCents,
 			Priority:    string(item.PriorityColor),
 			IsCompleted: item.IsCompleted,
+			SpentCents:  itemSpent[item.ID],
 		})
 	}
 
 	input := ai.AnalyzeSpendingInput{
 		PlanTitle:   plan.Title,
 		BudgetCents: plan.BudgetCents,
-		Currency:    currency,
+		Currency:    plan.Currency,
 		Categories:  categorySnapshots,
 	}
 
 	inputPayload, _ := json.Marshal(input)
-	aiResponse, prompt, raw, err := h.Service.AnalyzeSpending(c.Request().Context(), input)
+	aiResponse, attempts, err := h.Service.AnalyzeSpending(c.Request().Context(), input)
+	prompt, result := h.logRepairAttempts(c.Request().Context(), userID, aiRequestAnalyzeSpending, inputPayload, attempts)
 	responsePayload := []byte(nil)
 	if err == nil {
 		responsePayload, _ = json.Marshal(aiResponse)
 	}
 
-	h.logAIRequest(c.Request().Context(), userID, aiRequestAnalyzeSpending, prompt, inputPayload, responsePayload, raw, err)
+	// Без настроенных провайдеров запроса к модели не было, логировать нечего.
+	if !errors.Is(err, ai.ErrNotConfigured) {
+		h.logAIRequest(c.Request().Context(), userID, aiRequestAnalyzeSpending, prompt, inputPayload, responsePayload, result, err)
+	}
 
 	advices := aiResponse.Advices
 	if err != nil {
@@ -401,16 +442,39 @@ func (h *AIHandler) storeInputData(ctx context.Context, userID uuid.UUID, req Ge
 	return h.AIRepo.SaveInputData(ctx, userID, &period, income, mandatory, optional, assets, debts, notesPtr)
 }
 
-func (h *AIHandler) logAIRequest(ctx context.Context, userID uuid.UUID, requestType string, prompt string, requestPayload, responsePayload []byte, raw []byte, err error) {
+// logRepairAttempts сохраняет в логи AI-запросов отклоненные ответы, после
+// которых модель просили исправиться, и возвращает промпт и ответ последней
+// попытки: ее обработчик логирует сам вместе с итоговым результатом.
+func (h *AIHandler) logRepairAttempts(ctx context.Context, userID uuid.UUID, requestType string, requestPayload []byte, attempts []ai.Attempt) (string, ai.ChatResult) {
+	if len(attempts) == 0 {
+		return "", ai.ChatResult{}
+	}
+
+	for _, attempt := range attempts[:len(attempts)-1] {
+		h.logAIRequest(ctx, userID, requestType, attempt.Prompt, requestPayload, nil, attempt.Result, attempt.Err)
+	}
+
+	last := attempts[len(attempts)-1]
+	return last.Prompt, last.Result
+}
+
+// logAIRequest сохраняет лог AI-запроса. Провайдер и модель берутся из ответа,
+// а если запрос не дошел ни до одного провайдера — из настроек обработчика.
+func (h *AIHandler) logAIRequest(ctx context.Context, userID uuid.UUID, requestType string, prompt string, requestPayload, responsePayload []byte, result ai.ChatResult, err error) {
+	provider, model := result.Provider, result.Model
+	if provider == "" {
+		provider, model = h.Provider, h.Model
+	}
+
 	log := repository.AIRequestLog{
 		UserID:          userID,
 		RequestType:     requestType,
-		Provider:        h.Provider,
-		Model:           h.Model,
+		Provider:        provider,
+		Model:           model,
 		Prompt:          prompt,
 		RequestPayload:  requestPayload,
 		ResponsePayload: responsePayload,
-		RawResponse:     string(raw),
+		RawResponse:     string(result.Raw),
 		Success:         err == nil,
 	}
 	if err != nil {
@@ -421,9 +485,46 @@ func (h *AIHandler) logAIRequest(ctx context.Context, userID uuid.UUID, requestT
 	_ = h.AIRepo.LogRequest(ctx, log)
 }
 
-func (h *AIHandler) createFallbackPlan(ctx context.Context, userID uuid.UUID, periodStart, periodEnd time.Time, budgetCents int64) (models.BudgetPlan, error) {
+// respondOfflinePlan сохраняет план, составленный без модели, когда провайдеры
+// AI не настроены. Запросов к модели не было, поэтому в ai_requests ничего не пишется.
+func (h *AIHandler) respondOfflinePlan(c echo.Context, userID uuid.UUID, input ai.GeneratePlanInput, offline ai.PlanResponse, periodStart, periodEnd time.Time) error {
+	categories, notes, err := mapAIPlan(offline)
+	if err != nil {
+		return serverError(c)
+	}
+
+	plan, err := h.Plans.CreateWithDetails(c.Request().Context(), userID, offline.Plan.Title, input.BudgetCents, &input.Currency, periodStart, periodEnd, defaultBackgroundColor, false, categories, notes)
+	if err != nil {
+		return serverError(c)
+	}
+	logPlanSource("offline", plan.ID, userID)
+
+	response, err := buildPlanDetailResponse(c.Request().Context(), h.Plans, plan)
+	if err != nil {
+		return serverError(c)
+	}
+
+	publishBudgetUpdate(h.Notifier, userID, plan.ID, response.Plan.SpentCents, response.Plan.RemainingCents)
+	return c.JSON(http.StatusCreated, response)
+}
+
+// createFallbackPlan создает план без AI, когда модель не ответила или ответ
+// не удалось применить: сначала по правилу 50/30/20 из анкеты (ai.OfflinePlan),
+// а если и это не удалось — пустой шаблон.
+func (h *AIHandler) createFallbackPlan(ctx context.Context, userID uuid.UUID, input ai.GeneratePlanInput, periodStart, periodEnd time.Time) (models.BudgetPlan, error) {
+	offline := ai.OfflinePlan(input)
+	categories, notes, err := mapAIPlan(offline)
+	if err == nil {
+		var plan models.BudgetPlan
+		plan, err = h.Plans.CreateWithDetails(ctx, userID, offline.Plan.Title, input.BudgetCents, &input.Currency, periodStart, periodEnd, defaultBackgroundColor, false, categories, notes)
+		if err == nil {
+			return plan, nil
+		}
+	}
+	slog.Warn("offline plan failed", slog.String("user_id", userID.String()), slog.String("error", err.Error()))
+
 	title := fmt.Sprintf("Бюджетный план %s - %s", periodStart.Format(dateLayout), periodEnd.Format(dateLayout))
-	plan, err := h.Plans.Create(ctx, userID, title, budgetCents, periodStart, periodEnd, defaultBackgroundColor, false)
+	plan, err := h.Plans.Create(ctx, userID, title, input.BudgetCents, &input.Currency, periodStart, periodEnd, defaultBackgroundColor, false)
 	if err != nil {
 		return plan, err
 	}
@@ -557,6 +658,8 @@ func logPlanSource(source string, planID, userID uuid.UUID) {
 	switch source {
 	case "fallback":
 		slog.Warn("ai plan fallback used", slog.String("plan_id", planID.String()), slog.String("user_id", userID.String()))
+	case "offline":
+		slog.Info("offline plan generated", slog.String("plan_id", planID.String()), slog.String("user_id", userID.String()))
 	default:
 		slog.Info("ai plan generated", slog.String("plan_id", planID.String()), slog.String("user_id", userID.String()))
 	}
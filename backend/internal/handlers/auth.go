@@ -1,8 +1,8 @@
 package handlers
 
 import (
-	"context"
 	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -11,6 +11,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/mailer"
 	"example.com/ai-budget-planner/backend/internal/models"
 	"example.com/ai-budget-planner/backend/internal/repository"
 )
@@ -18,15 +19,24 @@ import (
 type AuthHandler struct {
 	Users        *repository.UserRepository
 	Tokens       *repository.RefreshTokenRepository
+	UserTokens   *repository.UserTokenRepository
 	TokenManager *auth.TokenManager
+	Mailer       mailer.Mailer
+	AppURL       string
+	Lockout      auth.LoginLockout
 }
 
-// NewAuthHandler создает обработчик авторизации.
-func NewAuthHandler(users *repository.UserRepository, tokens *repository.RefreshTokenRepository, manager *auth.TokenManager) *AuthHandler {
+// NewAuthHandler создает обработчик авторизации. appURL — адрес фронтенда
+// для ссылок в письмах, lockout — политика блокировки входа после неудач.
+func NewAuthHandler(users *repository.UserRepository, tokens *repository.RefreshTokenRepository, userTokens *repository.UserTokenRepository, manager *auth.TokenManager, mail mailer.Mailer, appURL string, lockout auth.LoginLockout) *AuthHandler {
 	return &AuthHandler{
 		Users:        users,
 		Tokens:       tokens,
+		UserTokens:   userTokens,
 		TokenManager: manager,
+		Mailer:       mail,
+		AppURL:       appURL,
+		Lockout:      lockout,
 	}
 }
 
@@ -50,9 +60,16 @@ type LogoutRequest struct {
 }
 
 type AuthUser struct {
-	ID    uuid.UUID `json:"id"`
-	Email string    `json:"email"`
-	Name  *string   `json:"name,omitempty"`
+	ID            uuid.UUID        `json:"id"`
+	Email         string           `json:"email"`
+	Name          *string          `json:"name,omitempty"`
+	BaseCurrency  string           `json:"base_currency"`
+	Locale        string           `json:"locale"`
+	TimeZone      string           `json:"time_zone"`
+	WeekStart     models.WeekStart `json:"week_start"`
+	PendingEmail  *string          `json:"pending_email,omitempty"`
+	EmailVerified bool             `json:"email_verified"`
+	MFAEnabled    bool             `json:"mfa_enabled"`
 }
 
 type AuthResponse struct {
@@ -92,7 +109,9 @@ func (h *AuthHandler) Register(c echo.Context) error {
 		return serverError(c)
 	}
 
-	response, err := h.issueTokens(c.Request().Context(), user)
+	h.sendVerificationEmail(c.Request().Context(), user)
+
+	response, err := h.issueTokens(c, user)
 	if err != nil {
 		return serverError(c)
 	}
@@ -100,7 +119,9 @@ func (h *AuthHandler) Register(c echo.Context) error {
 	return c.JSON(http.StatusCreated, response)
 }
 
-// Login выполняет вход и выдает токены.
+// Login выполняет вход и выдает токены. Если у пользователя включен второй
+// фактор, вместо токенов возвращается MFA-челлендж для LoginMFA. Неудачные
+// попытки считаются по учетной записи; пока вход закрыт, пароль не проверяется.
 func (h *AuthHandler) Login(c echo.Context) error {
 	var req LoginRequest
 	if err := c.Bind(&req); err != nil {
@@ -121,11 +142,30 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return serverError(c)
 	}
 
+	if retryAfter := loginRetryAfter(user, time.Now()); retryAfter > 0 {
+		return tooManyLoginAttempts(c, retryAfter)
+	}
+
 	if err = auth.ComparePassword(user.PasswordHash, password); err != nil {
+		h.recordLoginFailure(c.Request().Context(), user)
 		return unauthorized(c)
 	}
 
-	response, err := h.issueTokens(c.Request().Context(), user)
+	// При включенном втором факторе счетчик сбрасывается только после кода,
+	// иначе верный пароль позволял бы перебирать коды без блокировки.
+	if user.TOTPEnabledAt != nil {
+		mfaToken, expiresAt, err := h.TokenManager.NewMFAToken(user.ID)
+		if err != nil {
+			return serverError(c)
+		}
+		return c.JSON(http.StatusOK, MFAChallengeResponse{MFARequired: true, MFAToken: mfaToken, ExpiresAt: expiresAt})
+	}
+
+	if err := h.resetLoginFailures(c.Request().Context(), user); err != nil {
+		return serverError(c)
+	}
+
+	response, err := h.issueTokens(c, user)
 	if err != nil {
 		return serverError(c)
 	}
@@ -166,15 +206,22 @@ func (h *AuthHandler) Refresh(c echo.Context) error {
 		return serverError(c)
 	}
 
-	if storedToken.RevokedAt != nil || time.Now().After(storedToken.ExpiresAt) {
+	if storedToken.UserID != userID {
 		return unauthorized(c)
 	}
 
-	if storedToken.UserID != userID {
+	if !auth.CompareTokenHash(storedToken.TokenHash, req.RefreshToken) {
 		return unauthorized(c)
 	}
 
-	if !auth.CompareTokenHash(storedToken.TokenHash, req.RefreshToken) {
+	// Уже замененный токен предъявлен повторно: им пользуется кто-то еще,
+	// поэтому отзываем все токены, выпущенные по цепочке замен.
+	if storedToken.ReplacedBy != nil {
+		h.revokeReusedFamily(c, storedToken)
+		return unauthorized(c)
+	}
+
+	if storedToken.RevokedAt != nil || time.Now().After(storedToken.ExpiresAt) {
 		return unauthorized(c)
 	}
 
@@ -198,9 +245,12 @@ func (h *AuthHandler) Refresh(c echo.Context) error {
 		TokenHash: auth.HashToken(tokenPair.RefreshToken),
 		ExpiresAt: tokenPair.RefreshExpiresAt,
 	}
+	newToken.UserAgent, newToken.IPAddress = sessionClient(c)
 
 	if err := h.Tokens.Rotate(c.Request().Context(), storedToken.ID, newToken); err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
+			// Токен заменили параллельным запросом между проверкой и ротацией.
+			h.revokeReusedFamily(c, storedToken)
 			return unauthorized(c)
 		}
 		return serverError(c)
@@ -213,6 +263,26 @@ func (h *AuthHandler) Refresh(c echo.Context) error {
 	})
 }
 
+// revokeReusedFamily отзывает цепочку токенов, начиная с повторно предъявленного,
+// и пишет в лог событие безопасности.
+func (h *AuthHandler) revokeReusedFamily(c echo.Context, token models.RefreshToken) {
+	userAgent, ip := sessionClient(c)
+	attrs := []any{
+		slog.String("user_id", token.UserID.String()),
+		slog.String("token_id", token.ID.String()),
+		slog.String("ip", derefString(ip)),
+		slog.String("user_agent", derefString(userAgent)),
+	}
+
+	revoked, err := h.Tokens.RevokeFamily(c.Request().Context(), token.ID)
+	if err != nil {
+		slog.Error("refresh token family revoke failed", append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+
+	slog.Warn("security: refresh token reuse detected", append(attrs, slog.Int64("revoked", revoked))...)
+}
+
 // Logout отзывает refresh-токен.
 func (h *AuthHandler) Logout(c echo.Context) error {
 	var req LogoutRequest
@@ -261,7 +331,16 @@ func (h *AuthHandler) Me(c echo.Context) error {
 	return c.JSON(http.StatusOK, UserResponse{User: toAuthUser(user)})
 }
 
-func (h *AuthHandler) issueTokens(ctx context.Context, user models.User) (AuthResponse, error) {
+// issueTokens выдает пару токенов и создает сессию. Вход в учетную запись,
+// помеченную удаленной, отменяет удаление.
+func (h *AuthHandler) issueTokens(c echo.Context, user models.User) (AuthResponse, error) {
+	if user.DeletedAt != nil {
+		if err := h.Users.CancelDeletion(c.Request().Context(), user.ID); err != nil {
+			return AuthResponse{}, err
+		}
+		slog.Info("account deletion cancelled by login", slog.String("user_id", user.ID.String()))
+	}
+
 	refreshID := uuid.New()
 	pair, err := h.TokenManager.NewTokenPair(user.ID, refreshID)
 	if err != nil {
@@ -274,8 +353,9 @@ func (h *AuthHandler) issueTokens(ctx context.Context, user models.User) (AuthRe
 		TokenHash: auth.HashToken(pair.RefreshToken),
 		ExpiresAt: pair.RefreshExpiresAt,
 	}
+	refreshToken.UserAgent, refreshToken.IPAddress = sessionClient(c)
 
-	if err := h.Tokens.Create(ctx, refreshToken); err != nil {
+	if err := h.Tokens.Create(c.Request().Context(), refreshToken); err != nil {
 		return AuthResponse{}, err
 	}
 
@@ -288,9 +368,16 @@ func (h *AuthHandler) issueTokens(ctx context.Context, user models.User) (AuthRe
 
 func toAuthUser(user models.User) AuthUser {
 	return AuthUser{
-		ID:    user.ID,
-		Email: user.Email,
-		Name:  user.Name,
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		BaseCurrency:  user.BaseCurrency,
+		Locale:        user.Locale,
+		TimeZone:      user.TimeZone,
+		WeekStart:     user.WeekStart,
+		PendingEmail:  user.PendingEmail,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		MFAEnabled:    user.TOTPEnabledAt != nil,
 	}
 }
 
@@ -330,3 +417,10 @@ func forbidden(c echo.Context) error {
 func serverError(c echo.Context) error {
 	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 }
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
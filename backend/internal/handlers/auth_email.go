@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/mailer"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const (
+	userTokenBytes       = 32
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 48 * time.Hour
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ForgotPassword отправляет письмо со ссылкой для сброса пароля. Ответ не
+// зависит от того, зарегистрирован ли email.
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var req ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	user, err := h.Users.GetByEmail(c.Request().Context(), email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.NoContent(http.StatusAccepted)
+		}
+		return serverError(c)
+	}
+
+	link, err := h.issueUserToken(c.Request().Context(), user, models.UserTokenPasswordReset, passwordResetTTL, "/reset-password")
+	if err != nil {
+		return serverError(c)
+	}
+
+	h.sendMail(c.Request().Context(), mailer.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Чтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует 1 час. Если вы не запрашивали сброс, просто проигнорируйте это письмо.\n", link),
+	})
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ResetPassword задает новый пароль по токену из письма и завершает все сессии пользователя.
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var req ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	passwordHash, err := auth.HashPassword(strings.TrimSpace(req.Password))
+	if err != nil {
+		return serverError(c)
+	}
+
+	if _, err = h.Users.ResetPassword(c.Request().Context(), auth.HashToken(strings.TrimSpace(req.Token)), passwordHash); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return badRequest(c, "invalid or expired token")
+		}
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// VerifyEmail подтверждает email по токену из письма.
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var req VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	user, err := h.Users.VerifyEmail(c.Request().Context(), auth.HashToken(strings.TrimSpace(req.Token)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return badRequest(c, "invalid or expired token")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, UserResponse{User: toAuthUser(user)})
+}
+
+// ResendVerification повторно отправляет письмо подтверждения текущему пользователю.
+func (h *AuthHandler) ResendVerification(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	user, err := h.Users.GetByID(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "user not found")
+		}
+		return serverError(c)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return conflict(c, "email already verified")
+	}
+
+	h.sendVerificationEmail(c.Request().Context(), user)
+	return c.NoContent(http.StatusAccepted)
+}
+
+// sendVerificationEmail выпускает токен подтверждения и отправляет письмо.
+// Ошибки только логируются: регистрация не должна падать из-за почты.
+func (h *AuthHandler) sendVerificationEmail(ctx context.Context, user models.User) {
+	link, err := h.issueUserToken(ctx, user, models.UserTokenEmailVerification, emailVerificationTTL, "/verify-email")
+	if err != nil {
+		slog.Warn("verification token issue failed",
+			slog.String("user_id", user.ID.String()),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	h.sendMail(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение email",
+		Body: fmt.Sprintf("Чтобы подтвердить адрес, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует 48 часов.\n", link),
+	})
+}
+
+// issueUserToken сохраняет хэш нового одноразового токена и возвращает ссылку на фронтенд с ним.
+func (h *AuthHandler) issueUserToken(ctx context.Context, user models.User, purpose models.UserTokenPurpose, ttl time.Duration, path string) (string, error) {
+	token, err := auth.GenerateToken(userTokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	if err := h.UserTokens.Create(ctx, user.ID, purpose, auth.HashToken(token), time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+
+	return h.AppURL + path + "?token=" + url.QueryEscape(token), nil
+}
+
+func (h *AuthHandler) sendMail(ctx context.Context, msg mailer.Message) {
+	if err := h.Mailer.Send(ctx, msg); err != nil {
+		slog.Warn("email send failed",
+			slog.String("subject", msg.Subject),
+			slog.String("error", err.Error()),
+		)
+	}
+}
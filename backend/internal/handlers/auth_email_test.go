@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"example.com/ai-budget-planner/backend/internal/mailer"
+)
+
+var mailLinkPattern = regexp.MustCompile(`https?://\S+`)
+
+// mailToken находит последнее письмо с темой subject и достает токен из ссылки в нем.
+func mailToken(t *testing.T, mail *mailer.MemoryMailer, to, subject string) string {
+	t.Helper()
+
+	messages := mail.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].To != to || messages[i].Subject != subject {
+			continue
+		}
+
+		link, err := url.Parse(mailLinkPattern.FindString(messages[i].Body))
+		if err != nil {
+			t.Fatalf("parse link from %q: %v", messages[i].Body, err)
+		}
+		if token := link.Query().Get("token"); token != "" {
+			return token
+		}
+		t.Fatalf("no token in %q", messages[i].Body)
+	}
+
+	t.Fatalf("no %q email sent to %s", subject, to)
+	return ""
+}
+
+// TestPasswordResetFlow проверяет, что ссылка сброса приходит письмом,
+// токен меняет пароль только один раз и старый пароль перестает подходить.
+func TestPasswordResetFlow(t *testing.T) {
+	handler, mail, _ := newTestAuthHandler(t)
+	registerTestUser(t, handler, "reset@example.com", "Pass1234")
+
+	rec := serveJSON(t, handler.ForgotPassword, http.MethodPost, `{"email":"Reset@Example.com"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("forgot password: expected 202, got %d", rec.Code)
+	}
+	token := mailToken(t, mail, "reset@example.com", "Сброс пароля")
+
+	body := `{"token":"` + token + `","password":"NewPass123"}`
+	rec = serveJSON(t, handler.ResetPassword, http.MethodPost, body)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("reset password: expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveJSON(t, handler.ResetPassword, http.MethodPost, `{"token":"`+token+`","password":"OtherPass123"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("reused reset token: expected 400, got %d", rec.Code)
+	}
+
+	rec = serveJSON(t, handler.Login, http.MethodPost, `{"email":"reset@example.com","password":"Pass1234"}`)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("login with old password: expected 401, got %d", rec.Code)
+	}
+	rec = serveJSON(t, handler.Login, http.MethodPost, `{"email":"reset@example.com","password":"NewPass123"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login with new password: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// TestForgotPasswordUnknownEmail проверяет, что для неизвестного адреса ответ
+// тот же, что для существующего, и письмо не отправляется.
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	handler, mail, _ := newTestAuthHandler(t)
+	registerTestUser(t, handler, "known@example.com", "Pass1234")
+	sent := len(mail.Messages())
+
+	known := serveJSON(t, handler.ForgotPassword, http.MethodPost, `{"email":"known@example.com"}`)
+	unknown := serveJSON(t, handler.ForgotPassword, http.MethodPost, `{"email":"unknown@example.com"}`)
+
+	if known.Code != unknown.Code || known.Body.String() != unknown.Body.String() {
+		t.Fatalf("expected identical responses, got %d %q and %d %q",
+			known.Code, known.Body.String(), unknown.Code, unknown.Body.String())
+	}
+	if got := len(mail.Messages()); got != sent+1 {
+		t.Fatalf("expected only the known address to get an email, got %d new", got-sent)
+	}
+	for _, message := range mail.Messages() {
+		if message.To == "unknown@example.com" {
+			t.Fatal("expected no email for an unknown address")
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// loginFailureWindow — через сколько после последней неудачи счетчик начинается заново.
+const loginFailureWindow = 24 * time.Hour
+
+// loginRetryAfter возвращает, сколько осталось до конца блокировки входа.
+func loginRetryAfter(user models.User, now time.Time) time.Duration {
+	if user.LockedUntil == nil || !user.LockedUntil.After(now) {
+		return 0
+	}
+	return user.LockedUntil.Sub(now)
+}
+
+// tooManyLoginAttempts отвечает 429 с заголовком Retry-After.
+func tooManyLoginAttempts(c echo.Context, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "too many failed login attempts, try again later"})
+}
+
+// recordLoginFailure учитывает неудачную попытку входа и закрывает вход на
+// время из политики блокировки. Ошибки записи только логируются, чтобы
+// ответ не отличался от обычного неверного пароля.
+func (h *AuthHandler) recordLoginFailure(ctx context.Context, user models.User) {
+	now := time.Now()
+
+	failures, err := h.Users.RecordLoginFailure(ctx, user.ID, now.Add(-loginFailureWindow))
+	if err != nil {
+		slog.Error("login failure record failed", slog.String("user_id", user.ID.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	delay := h.Lockout.Delay(failures)
+	if delay == 0 {
+		return
+	}
+
+	if err := h.Users.LockLogin(ctx, user.ID, now.Add(delay)); err != nil {
+		slog.Error("login lock failed", slog.String("user_id", user.ID.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	if h.Lockout.Locked(failures) {
+		slog.Warn("security: account locked after failed logins",
+			slog.String("user_id", user.ID.String()),
+			slog.Int("failures", failures),
+			slog.Duration("locked_for", delay),
+		)
+	}
+}
+
+// resetLoginFailures сбрасывает счетчик после успешного входа, если он не пуст.
+func (h *AuthHandler) resetLoginFailures(ctx context.Context, user models.User) error {
+	if user.FailedLogins == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	return h.Users.ResetLoginFailures(ctx, user.ID)
+}
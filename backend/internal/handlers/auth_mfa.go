@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const totpIssuer = "Budget Planner"
+
+var (
+	errInvalidSecondFactor = errors.New("invalid second factor")
+	errUnauthenticated     = errors.New("unauthenticated")
+)
+
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type DisableTOTPRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+type TOTPSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	TOTPEnabled       bool `json:"totp_enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// LoginMFA завершает вход: обменивает MFA-челлендж и код TOTP (или код
+// восстановления) на пару токенов.
+func (h *AuthHandler) LoginMFA(c echo.Context) error {
+	var req LoginMFARequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	claims, err := h.TokenManager.ParseMFAToken(req.MFAToken)
+	if err != nil {
+		return unauthorized(c)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return unauthorized(c)
+	}
+
+	user, err := h.Users.GetByID(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return unauthorized(c)
+		}
+		return serverError(c)
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return unauthorized(c)
+	}
+
+	if retryAfter := loginRetryAfter(user, time.Now()); retryAfter > 0 {
+		return tooManyLoginAttempts(c, retryAfter)
+	}
+
+	if err = h.verifySecondFactor(c.Request().Context(), user, req.Code); err != nil {
+		if errors.Is(err, errInvalidSecondFactor) {
+			h.recordLoginFailure(c.Request().Context(), user)
+			return unauthorized(c)
+		}
+		return serverError(c)
+	}
+
+	if err := h.resetLoginFailures(c.Request().Context(), user); err != nil {
+		return serverError(c)
+	}
+
+	response, err := h.issueTokens(c, user)
+	if err != nil {
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// MFAStatus возвращает состояние второго фактора текущего пользователя.
+func (h *AuthHandler) MFAStatus(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return currentUserError(c, err)
+	}
+
+	response := MFAStatusResponse{TOTPEnabled: user.TOTPEnabledAt != nil}
+	if response.TOTPEnabled {
+		response.RecoveryCodesLeft, err = h.Users.CountRecoveryCodes(c.Request().Context(), user.ID)
+		if err != nil {
+			return serverError(c)
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// SetupTOTP выпускает новый секрет TOTP. Второй фактор включается только
+// после подтверждения кодом в EnableTOTP.
+func (h *AuthHandler) SetupTOTP(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return currentUserError(c, err)
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return serverError(c)
+	}
+
+	if err = h.Users.SetPendingTOTP(c.Request().Context(), user.ID, secret); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return conflict(c, "two-factor authentication already enabled")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, TOTPSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(totpIssuer, user.Email, secret),
+	})
+}
+
+// EnableTOTP подтверждает секрет кодом из приложения, включает второй фактор
+// и один раз возвращает коды восстановления.
+func (h *AuthHandler) EnableTOTP(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return currentUserError(c, err)
+	}
+
+	var req MFACodeRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return conflict(c, "two-factor authentication already enabled")
+	}
+	if user.TOTPSecret == nil {
+		return badRequest(c, "totp setup required")
+	}
+
+	counter, ok := auth.ValidateTOTP(*user.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		return badRequest(c, "invalid code")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return serverError(c)
+	}
+
+	if _, err = h.Users.EnableTOTP(c.Request().Context(), user.ID, counter, hashes); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return conflict(c, "two-factor authentication already enabled")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP выключает второй фактор после проверки пароля и кода.
+func (h *AuthHandler) DisableTOTP(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return currentUserError(c, err)
+	}
+
+	var req DisableTOTPRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return badRequest(c, "two-factor authentication is not enabled")
+	}
+
+	if err = auth.ComparePassword(user.PasswordHash, strings.TrimSpace(req.Password)); err != nil {
+		return unauthorized(c)
+	}
+
+	if err = h.verifySecondFactor(c.Request().Context(), user, req.Code); err != nil {
+		if errors.Is(err, errInvalidSecondFactor) {
+			return unauthorized(c)
+		}
+		return serverError(c)
+	}
+
+	if err = h.Users.DisableTOTP(c.Request().Context(), user.ID); err != nil {
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes заменяет коды восстановления новыми после проверки кода TOTP.
+func (h *AuthHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return currentUserError(c, err)
+	}
+
+	var req MFACodeRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return badRequest(c, "two-factor authentication is not enabled")
+	}
+
+	if err = h.verifySecondFactor(c.Request().Context(), user, req.Code); err != nil {
+		if errors.Is(err, errInvalidSecondFactor) {
+			return unauthorized(c)
+		}
+		return serverError(c)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return serverError(c)
+	}
+
+	if err = h.Users.ReplaceRecoveryCodes(c.Request().Context(), user.ID, hashes); err != nil {
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// verifySecondFactor принимает код TOTP (каждый интервал — один раз) или
+// неиспользованный код восстановления.
+func (h *AuthHandler) verifySecondFactor(ctx context.Context, user models.User, code string) error {
+	if user.TOTPSecret == nil {
+		return errInvalidSecondFactor
+	}
+
+	if counter, ok := auth.ValidateTOTP(*user.TOTPSecret, code, time.Now()); ok {
+		if err := h.Users.UseTOTPCounter(ctx, user.ID, counter); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				return errInvalidSecondFactor
+			}
+			return err
+		}
+		return nil
+	}
+
+	normalized := auth.NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return errInvalidSecondFactor
+	}
+
+	if err := h.Users.ConsumeRecoveryCode(ctx, user.ID, auth.HashToken(normalized)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return errInvalidSecondFactor
+		}
+		return err
+	}
+
+	return nil
+}
+
+// currentUser загружает пользователя из контекста запроса. Ошибку нужно
+// передать в currentUserError.
+func (h *AuthHandler) currentUser(c echo.Context) (models.User, error) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return models.User{}, errUnauthenticated
+	}
+
+	return h.Users.GetByID(c.Request().Context(), userID)
+}
+
+// currentUserError пишет ответ для ошибки currentUser.
+func currentUserError(c echo.Context, err error) error {
+	if errors.Is(err, errUnauthenticated) {
+		return unauthorized(c)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return notFound(c, "user not found")
+	}
+	return serverError(c)
+}
+
+// newRecoveryCodes генерирует коды восстановления и их хэши для хранения.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	}
+
+	return codes, hashes, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/oidc"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+// oidcStateTTL ограничивает время на вход у провайдера.
+const oidcStateTTL = 10 * time.Minute
+
+type OIDCHandler struct {
+	Auth     *AuthHandler
+	Provider *oidc.Provider
+	Logins   *repository.OIDCRepository
+}
+
+// NewOIDCHandler создает обработчик входа через OpenID Connect. Токены
+// выдаются так же, как при входе по паролю.
+func NewOIDCHandler(authHandler *AuthHandler, provider *oidc.Provider, logins *repository.OIDCRepository) *OIDCHandler {
+	return &OIDCHandler{
+		Auth:     authHandler,
+		Provider: provider,
+		Logins:   logins,
+	}
+}
+
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required,max=2048"`
+	State string `json:"state" validate:"required,max=256"`
+}
+
+// Authorize начинает вход: сохраняет state, nonce и PKCE code_verifier и
+// возвращает адрес страницы входа провайдера.
+func (h *OIDCHandler) Authorize(c echo.Context) error {
+	state, err := auth.GenerateToken(32)
+	if err != nil {
+		return serverError(c)
+	}
+	nonce, err := auth.GenerateToken(32)
+	if err != nil {
+		return serverError(c)
+	}
+	codeVerifier, err := auth.GenerateToken(32)
+	if err != nil {
+		return serverError(c)
+	}
+
+	expiresAt := time.Now().Add(oidcStateTTL)
+	if err := h.Logins.CreateState(c.Request().Context(), auth.HashToken(state), codeVerifier, nonce, expiresAt); err != nil {
+		return serverError(c)
+	}
+
+	authURL, err := h.Provider.AuthCodeURL(c.Request().Context(), state, nonce, codeVerifier)
+	if err != nil {
+		slog.Error("oidc discovery failed", slog.String("error", err.Error()))
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": "identity provider unavailable"})
+	}
+
+	return c.JSON(http.StatusOK, OIDCAuthorizeResponse{AuthorizationURL: authURL, ExpiresAt: expiresAt})
+}
+
+// Callback завершает вход: обменивает код провайдера на ID-токен, находит или
+// создает пользователя по подтвержденному email и выдает пару токенов. Если у
+// пользователя включен второй фактор, возвращается MFA-челлендж.
+func (h *OIDCHandler) Callback(c echo.Context) error {
+	var req OIDCCallbackRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	ctx := c.Request().Context()
+
+	codeVerifier, nonce, err := h.Logins.ConsumeState(ctx, auth.HashToken(req.State))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return badRequest(c, "invalid or expired state")
+		}
+		return serverError(c)
+	}
+
+	identity, err := h.Provider.Exchange(ctx, req.Code, codeVerifier, nonce)
+	if err != nil {
+		slog.Warn("oidc code exchange failed", slog.String("error", err.Error()))
+		return unauthorized(c)
+	}
+
+	user, err := h.Logins.LoginIdentity(ctx, identity.Issuer, identity.Subject, identity.Email, identity.EmailVerified, normalizeName(&identity.Name))
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "email is not verified by identity provider"})
+		}
+		return serverError(c)
+	}
+
+	if user.TOTPEnabledAt != nil {
+		mfaToken, expiresAt, err := h.Auth.TokenManager.NewMFAToken(user.ID)
+		if err != nil {
+			return serverError(c)
+		}
+		return c.JSON(http.StatusOK, MFAChallengeResponse{MFARequired: true, MFAToken: mfaToken, ExpiresAt: expiresAt})
+	}
+
+	response, err := h.Auth.issueTokens(c, user)
+	if err != nil {
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
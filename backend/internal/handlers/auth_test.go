@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func refreshTokenID(t *testing.T, handler *AuthHandler, token string) uuid.UUID {
+	t.Helper()
+
+	claims, err := handler.TokenManager.ParseRefreshToken(token)
+	if err != nil {
+		t.Fatalf("parse refresh token: %v", err)
+	}
+	return uuid.MustParse(claims.ID)
+}
+
+func refreshRequest(token string) string {
+	return `{"refresh_token":"` + token + `"}`
+}
+
+// TestRefreshReuseRevokesFamily проверяет, что повторно предъявленный
+// замененный токен отзывает и токен, выпущенный ему на смену.
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	handler, _, _ := newTestAuthHandler(t)
+	ctx := context.Background()
+
+	first := registerTestUser(t, handler, "reuse@example.com", "Pass1234")
+
+	rec := serveJSON(t, handler.Refresh, http.MethodPost, refreshRequest(first.RefreshToken))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var second AuthResponse
+	decodeJSON(t, rec.Body.Bytes(), &second)
+
+	rec = serveJSON(t, handler.Refresh, http.MethodPost, refreshRequest(first.RefreshToken))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("reuse: expected 401, got %d", rec.Code)
+	}
+
+	stored, err := handler.Tokens.GetByID(ctx, refreshTokenID(t, handler, second.RefreshToken))
+	if err != nil {
+		t.Fatalf("get rotated token: %v", err)
+	}
+	if stored.RevokedAt == nil {
+		t.Fatal("expected the rotated token to be revoked after reuse")
+	}
+
+	rec = serveJSON(t, handler.Refresh, http.MethodPost, refreshRequest(second.RefreshToken))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh with revoked family: expected 401, got %d", rec.Code)
+	}
+}
+
+// TestRefreshRotateRace проверяет ветку, где токен отозвали параллельным
+// запросом между проверкой и ротацией: Rotate возвращает ErrNotFound,
+// ответ 401, а токен остается отозванным.
+func TestRefreshRotateRace(t *testing.T) {
+	handler, _, db := newTestAuthHandler(t)
+	ctx := context.Background()
+
+	first := registerTestUser(t, handler, "race@example.com", "Pass1234")
+
+	// Триггер отзывает остальные токены пользователя при вставке нового,
+	// как если бы параллельный Refresh успел раньше.
+	for _, statement := range []string{
+		`CREATE FUNCTION revoke_sibling_tokens() RETURNS trigger AS $$
+		 BEGIN
+		     UPDATE refresh_tokens SET revoked_at = NOW()
+		     WHERE user_id = NEW.user_id AND id <> NEW.id AND revoked_at IS NULL;
+		     RETURN NEW;
+		 END
+		 $$ LANGUAGE plpgsql`,
+		`CREATE TRIGGER refresh_tokens_race AFTER INSERT ON refresh_tokens
+		 FOR EACH ROW EXECUTE FUNCTION revoke_sibling_tokens()`,
+	} {
+		if _, err := db.Exec(ctx, statement); err != nil {
+			t.Fatalf("install race trigger: %v", err)
+		}
+	}
+
+	rec := serveJSON(t, handler.Refresh, http.MethodPost, refreshRequest(first.RefreshToken))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 when rotation loses the race, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	stored, err := handler.Tokens.GetByID(ctx, refreshTokenID(t, handler, first.RefreshToken))
+	if err != nil {
+		t.Fatalf("get token: %v", err)
+	}
+	if stored.RevokedAt == nil {
+		t.Fatal("expected the raced token family to be revoked")
+	}
+}
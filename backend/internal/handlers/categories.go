@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/notifications"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+type CategoryHandler struct {
+	Categories *repository.CategoryRepository
+	Plans      *repository.PlanRepository
+	Notifier   *notifications.Hub
+}
+
+// NewCategoryHandler создает обработчик категорий расходов.
+func NewCategoryHandler(categories *repository.CategoryRepository, plans *repository.PlanRepository, notifier *notifications.Hub) *CategoryHandler {
+	return &CategoryHandler{Categories: categories, Plans: plans, Notifier: notifier}
+}
+
+type CreateCategoryRequest struct {
+	Title        string              `json:"title" validate:"required,max=100"`
+	CategoryType models.CategoryType `json:"category_type" validate:"required,oneof=mandatory optional"`
+}
+
+type UpdateCategoryRequest struct {
+	Title        *string              `json:"title" validate:"omitempty,max=100"`
+	CategoryType *models.CategoryType `json:"category_type" validate:"omitempty,oneof=mandatory optional"`
+}
+
+// Create добавляет категорию в план.
+func (h *CategoryHandler) Create(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("planId"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	var req CreateCategoryRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return badRequest(c, "title is required")
+	}
+
+	category, err := h.Categories.Create(c.Request().Context(), userID, planID, title, req.CategoryType)
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "plan not found")
+		}
+		return serverError(c)
+	}
+
+	notifyPlanBudget(c.Request().Context(), h.Notifier, h.Plans, userID, planID)
+	return c.JSON(http.StatusCreated, toCategoryResponse(category, []ItemResponse{}))
+}
+
+// Update переименовывает категорию или меняет ее тип.
+func (h *CategoryHandler) Update(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		return badRequest(c, "invalid category id")
+	}
+
+	var req UpdateCategoryRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	if req.Title == nil && req.CategoryType == nil {
+		return badRequest(c, "nothing to update")
+	}
+
+	var title *string
+	if req.Title != nil {
+		trimmed := strings.TrimSpace(*req.Title)
+		if trimmed == "" {
+			return badRequest(c, "title is required")
+		}
+		title = &trimmed
+	}
+
+	category, err := h.Categories.Update(c.Request().Context(), userID, categoryID, title, req.CategoryType)
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "category not found")
+		}
+		return serverError(c)
+	}
+
+	items, err := h.Plans.ListItemsByCategoryIDs(c.Request().Context(), []uuid.UUID{category.ID})
+	if err != nil {
+		return serverError(c)
+	}
+
+	spent, err := h.Plans.ListItemSpentCents(c.Request().Context(), []uuid.UUID{category.ID})
+	if err != nil {
+		return serverError(c)
+	}
+
+	itemResponses := make([]ItemResponse, 0, len(items))
+	for _, item := range items {
+		itemResponses = append(itemResponses, toItemResponse(item, spent[item.ID]))
+	}
+
+	notifyPlanBudget(c.Request().Context(), h.Notifier, h.Plans, userID, category.PlanID)
+	return c.JSON(http.StatusOK, toCategoryResponse(category, itemResponses))
+}
+
+// Delete удаляет категорию вместе с расходами или переносит расходы
+// в категорию, указанную в query-параметре move_to.
+func (h *CategoryHandler) Delete(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		return badRequest(c, "invalid category id")
+	}
+
+	var moveTo *uuid.UUID
+	if raw := strings.TrimSpace(c.QueryParam("move_to")); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return badRequest(c, "invalid move_to")
+		}
+		moveTo = &parsed
+	}
+
+	planID, err := h.Categories.GetPlanIDByCategoryID(c.Request().Context(), userID, categoryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "category not found")
+		}
+		return serverError(c)
+	}
+
+	if err := h.Categories.Delete(c.Request().Context(), userID, categoryID, moveTo); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "category not found")
+		}
+		if errors.Is(err, repository.ErrInvalid) {
+			return badRequest(c, "invalid move_to category")
+		}
+		return serverError(c)
+	}
+
+	notifyPlanBudget(c.Request().Context(), h.Notifier, h.Plans, userID, planID)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toCategoryResponse(category models.ExpenseCategory, items []ItemResponse) CategoryResponse {
+	return CategoryResponse{
+		ID:           category.ID,
+		Title:        category.Title,
+		CategoryType: category.CategoryType,
+		SortOrder:    category.SortOrder,
+		Items:        items,
+	}
+}
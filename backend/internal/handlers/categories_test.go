@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestCategoryRequestValidation проверяет ответы 400 и 401 на неверные
+// запросы: до хранилища такие запросы не доходят.
+func TestCategoryRequestValidation(t *testing.T) {
+	handler := NewCategoryHandler(nil, nil, nil)
+	user := asUser(uuid.New())
+	planID := uuid.NewString()
+	categoryID := uuid.NewString()
+
+	cases := []struct {
+		name    string
+		handler echo.HandlerFunc
+		method  string
+		body    string
+		prepare []func(echo.Context)
+		want    int
+	}{
+		{"create without user", handler.Create, http.MethodPost, `{"title":"Еда","category_type":"mandatory"}`, []func(echo.Context){withParam("planId", planID)}, http.StatusUnauthorized},
+		{"create invalid plan id", handler.Create, http.MethodPost, `{"title":"Еда","category_type":"mandatory"}`, []func(echo.Context){user, withParam("planId", "bad")}, http.StatusBadRequest},
+		{"create blank title", handler.Create, http.MethodPost, `{"title":"   ","category_type":"mandatory"}`, []func(echo.Context){user, withParam("planId", planID)}, http.StatusBadRequest},
+		{"create long title", handler.Create, http.MethodPost, `{"title":"` + strings.Repeat("а", 101) + `","category_type":"mandatory"}`, []func(echo.Context){user, withParam("planId", planID)}, http.StatusBadRequest},
+		{"create unknown type", handler.Create, http.MethodPost, `{"title":"Еда","category_type":"other"}`, []func(echo.Context){user, withParam("planId", planID)}, http.StatusBadRequest},
+		{"create invalid json", handler.Create, http.MethodPost, `{"title":`, []func(echo.Context){user, withParam("planId", planID)}, http.StatusBadRequest},
+		{"update invalid id", handler.Update, http.MethodPut, `{"title":"Еда"}`, []func(echo.Context){user, withParam("categoryId", "bad")}, http.StatusBadRequest},
+		{"update nothing", handler.Update, http.MethodPut, `{}`, []func(echo.Context){user, withParam("categoryId", categoryID)}, http.StatusBadRequest},
+		{"update blank title", handler.Update, http.MethodPut, `{"title":" "}`, []func(echo.Context){user, withParam("categoryId", categoryID)}, http.StatusBadRequest},
+		{"update unknown type", handler.Update, http.MethodPut, `{"category_type":"other"}`, []func(echo.Context){user, withParam("categoryId", categoryID)}, http.StatusBadRequest},
+		{"delete invalid id", handler.Delete, http.MethodDelete, ``, []func(echo.Context){user, withParam("categoryId", "bad")}, http.StatusBadRequest},
+		{"delete invalid move_to", handler.Delete, http.MethodDelete, ``, []func(echo.Context){user, withParam("categoryId", categoryID), withQuery("move_to=bad")}, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		rec := serveJSON(t, tc.handler, tc.method, tc.body, tc.prepare...)
+		if rec.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// TestCategoryCRUD проверяет создание, изменение и удаление категорий с
+// переносом расходов, а также ошибки доступа и неверный move_to.
+func TestCategoryCRUD(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	plans := repository.NewPlanRepository(db)
+	items := repository.NewItemRepository(db)
+	handler := NewCategoryHandler(repository.NewCategoryRepository(db), plans, nil)
+	users := repository.NewUserRepository(db)
+
+	owner, err := users.Create(ctx, "categories@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+	stranger, err := users.Create(ctx, "stranger@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create stranger: %v", err)
+	}
+
+	now := time.Now()
+	plan, err := plans.Create(ctx, owner.ID, "План", 100000, nil, now, now.AddDate(0, 1, 0), "#FFFFFF", false)
+	if err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	otherPlan, err := plans.Create(ctx, owner.ID, "Другой план", 100000, nil, now, now.AddDate(0, 1, 0), "#FFFFFF", false)
+	if err != nil {
+		t.Fatalf("create other plan: %v", err)
+	}
+
+	create := func(userID, planID uuid.UUID, title string) (CategoryResponse, int) {
+		t.Helper()
+
+		rec := serveJSON(t, handler.Create, http.MethodPost, `{"title":"`+title+`","category_type":"mandatory"}`,
+			asUser(userID), withParam("planId", planID.String()))
+		var response CategoryResponse
+		if rec.Code == http.StatusCreated {
+			decodeJSON(t, rec.Body.Bytes(), &response)
+		}
+		return response, rec.Code
+	}
+
+	if _, code := create(stranger.ID, plan.ID, "Чужая"); code != http.StatusNotFound {
+		t.Fatalf("create in a foreign plan: expected 404, got %d", code)
+	}
+
+	food, code := create(owner.ID, plan.ID, "  Еда  ")
+	if code != http.StatusCreated || food.Title != "Еда" {
+		t.Fatalf("create: expected 201 with trimmed title, got %d %+v", code, food)
+	}
+	home, _ := create(owner.ID, plan.ID, "Дом")
+	foreign, _ := create(owner.ID, otherPlan.ID, "Другая")
+
+	if _, err := items.Create(ctx, owner.ID, plan.ID, food.ID, "Продукты", 5000, models.PriorityColorRed, false); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	rec := serveJSON(t, handler.Update, http.MethodPut, `{"category_type":"optional"}`, asUser(owner.ID), withParam("categoryId", food.ID.String()))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated CategoryResponse
+	decodeJSON(t, rec.Body.Bytes(), &updated)
+	if updated.CategoryType != models.CategoryTypeOptional || updated.Title != "Еда" || len(updated.Items) != 1 {
+		t.Fatalf("unexpected updated category: %+v", updated)
+	}
+
+	rec = serveJSON(t, handler.Update, http.MethodPut, `{"title":"Чужая"}`, asUser(stranger.ID), withParam("categoryId", food.ID.String()))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("update a foreign category: expected 404, got %d", rec.Code)
+	}
+
+	for _, target := range []uuid.UUID{foreign.ID, food.ID, uuid.New()} {
+		rec = serveJSON(t, handler.Delete, http.MethodDelete, ``, asUser(owner.ID),
+			withParam("categoryId", food.ID.String()), withQuery("move_to="+target.String()))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("delete with move_to %s: expected 400, got %d", target, rec.Code)
+		}
+	}
+
+	rec = serveJSON(t, handler.Delete, http.MethodDelete, ``, asUser(owner.ID),
+		withParam("categoryId", food.ID.String()), withQuery("move_to="+home.ID.String()))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete with move_to: expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	moved, err := plans.ListItemsByCategoryIDs(ctx, []uuid.UUID{home.ID})
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	if len(moved) != 1 || moved[0].Title != "Продукты" {
+		t.Fatalf("expected the item to move to the target category, got %+v", moved)
+	}
+
+	rec = serveJSON(t, handler.Delete, http.MethodDelete, ``, asUser(owner.ID), withParam("categoryId", food.ID.String()))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("delete twice: expected 404, got %d", rec.Code)
+	}
+}
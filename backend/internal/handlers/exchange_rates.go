@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/currency"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const maxImportedRates = 1000
+
+type ExchangeRateInput struct {
+	FromCurrency  string  `json:"from_currency" validate:"required"`
+	ToCurrency    string  `json:"to_currency" validate:"required"`
+	Rate          float64 `json:"rate" validate:"gt=0"`
+	EffectiveDate string  `json:"effective_date" validate:"required"`
+}
+
+type ImportExchangeRatesRequest struct {
+	Rates []ExchangeRateInput `json:"rates" validate:"required,min=1,dive"`
+}
+
+type ExchangeRateResponse struct {
+	FromCurrency  string  `json:"from_currency"`
+	ToCurrency    string  `json:"to_currency"`
+	Rate          float64 `json:"rate"`
+	EffectiveDate string  `json:"effective_date"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// ImportExchangeRates загружает курсы валют для пересчета статистики.
+func (h *AdminHandler) ImportExchangeRates(c echo.Context) error {
+	var req ImportExchangeRatesRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	if len(req.Rates) > maxImportedRates {
+		return badRequest(c, "too many rates")
+	}
+
+	rates := make([]models.ExchangeRate, 0, len(req.Rates))
+	for _, input := range req.Rates {
+		from, err := currency.Normalize(input.FromCurrency)
+		if err != nil {
+			return badRequest(c, "invalid from_currency")
+		}
+
+		to, err := currency.Normalize(input.ToCurrency)
+		if err != nil {
+			return badRequest(c, "invalid to_currency")
+		}
+
+		if from == to {
+			return badRequest(c, "from_currency and to_currency must differ")
+		}
+
+		effectiveDate, err := time.Parse(dateLayout, strings.TrimSpace(input.EffectiveDate))
+		if err != nil {
+			return badRequest(c, "invalid effective_date format")
+		}
+
+		rates = append(rates, models.ExchangeRate{
+			FromCurrency:  from,
+			ToCurrency:    to,
+			Rate:          input.Rate,
+			EffectiveDate: effectiveDate,
+		})
+	}
+
+	if err := h.Rates.Upsert(c.Request().Context(), rates); err != nil {
+		if errors.Is(err, repository.ErrInvalid) {
+			return badRequest(c, "invalid rates")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string]int{"imported": len(rates)})
+}
+
+// ListExchangeRates возвращает сохраненные курсы валют.
+func (h *AdminHandler) ListExchangeRates(c echo.Context) error {
+	limit, offset, err := parsePagination(c, 100, 500)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	var code *string
+	if raw := strings.TrimSpace(c.QueryParam("currency")); raw != "" {
+		normalized, err := currency.Normalize(raw)
+		if err != nil {
+			return badRequest(c, "invalid currency")
+		}
+		code = &normalized
+	}
+
+	rates, err := h.Rates.List(c.Request().Context(), code, limit, offset)
+	if err != nil {
+		return serverError(c)
+	}
+
+	response := make([]ExchangeRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		response = append(response, ExchangeRateResponse{
+			FromCurrency:  rate.FromCurrency,
+			ToCurrency:    rate.ToCurrency,
+			Rate:          rate.Rate,
+			EffectiveDate: rate.EffectiveDate.Format(dateLayout),
+			CreatedAt:     rate.CreatedAt.Format(timeLayout),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string][]ExchangeRateResponse{"rates": response})
+}
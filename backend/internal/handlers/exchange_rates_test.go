@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestImportExchangeRatesValidation проверяет ответы 400 на неверные курсы:
+// до хранилища такие запросы не доходят.
+func TestImportExchangeRatesValidation(t *testing.T) {
+	handler := NewAdminHandler(nil, nil, nil)
+
+	for name, body := range map[string]string{
+		"empty":          `{"rates":[]}`,
+		"unknown code":   `{"rates":[{"from_currency":"XXX","to_currency":"RUB","rate":1,"effective_date":"2024-01-01"}]}`,
+		"same currency":  `{"rates":[{"from_currency":"usd","to_currency":"USD","rate":1,"effective_date":"2024-01-01"}]}`,
+		"zero rate":      `{"rates":[{"from_currency":"USD","to_currency":"RUB","rate":0,"effective_date":"2024-01-01"}]}`,
+		"invalid date":   `{"rates":[{"from_currency":"USD","to_currency":"RUB","rate":90,"effective_date":"01.01.2024"}]}`,
+		"invalid json":   `{"rates":`,
+		"missing fields": `{"rates":[{"rate":90}]}`,
+	} {
+		rec := serveJSON(t, handler.ImportExchangeRates, http.MethodPost, body)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", name, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// TestImportExchangeRates проверяет, что импорт приводит коды валют к
+// верхнему регистру и перезаписывает курс на ту же дату.
+func TestImportExchangeRates(t *testing.T) {
+	db := testdb.New(t)
+	handler := NewAdminHandler(nil, repository.NewExchangeRateRepository(db), nil)
+
+	for _, body := range []string{
+		`{"rates":[{"from_currency":"usd","to_currency":"rub","rate":80,"effective_date":"2024-01-01"},
+		           {"from_currency":"EUR","to_currency":"RUB","rate":95.5,"effective_date":"2024-01-01"}]}`,
+		`{"rates":[{"from_currency":"USD","to_currency":"RUB","rate":90,"effective_date":"2024-01-01"}]}`,
+	} {
+		rec := serveJSON(t, handler.ImportExchangeRates, http.MethodPost, body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("import: expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := serveJSON(t, handler.ListExchangeRates, http.MethodGet, ``, withQuery("currency=usd"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response map[string][]ExchangeRateResponse
+	decodeJSON(t, rec.Body.Bytes(), &response)
+	rates := response["rates"]
+	if len(rates) != 1 || rates[0].FromCurrency != "USD" || rates[0].ToCurrency != "RUB" || rates[0].Rate != 90 || rates[0].EffectiveDate != "2024-01-01" {
+		t.Fatalf("unexpected rates: %+v", rates)
+	}
+
+	stored, err := repository.NewExchangeRateRepository(db).List(context.Background(), nil, 10, 0)
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("expected two stored rates, got %+v, %v", stored, err)
+	}
+}
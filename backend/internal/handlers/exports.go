@@ -124,6 +124,7 @@ func writeItemsCSV(writer *csv.Writer, response PlanDetailResponse) error {
 		"priority_color",
 		"is_completed",
 		"sort_order",
+		"spent_cents",
 	}
 	if err := writer.Write(header); err != nil {
 		return err
@@ -143,6 +144,7 @@ func writeItemsCSV(writer *csv.Writer, response PlanDetailResponse) error {
 				string(item.PriorityColor),
 				formatBool(item.IsCompleted),
 				formatInt(item.SortOrder),
+				formatInt64(item.SpentCents),
 			}
 			if err := writer.Write(record); err != nil {
 				return err
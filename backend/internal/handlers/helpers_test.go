@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/mailer"
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+type structValidator struct {
+	validate *validator.Validate
+}
+
+func (v structValidator) Validate(i interface{}) error {
+	return v.validate.Struct(i)
+}
+
+// serveJSON вызывает обработчик с JSON-телом; prepare настраивает контекст,
+// например кладет в него ID пользователя.
+func serveJSON(t *testing.T, handler echo.HandlerFunc, method, body string, prepare ...func(echo.Context)) *httptest.ResponseRecorder {
+	t.Helper()
+
+	e := echo.New()
+	e.Validator = structValidator{validate: validator.New()}
+
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	for _, fn := range prepare {
+		fn(c)
+	}
+
+	if err := handler(c); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+// newTestAuthHandler создает AuthHandler на тестовой базе; письма остаются в mail.
+func newTestAuthHandler(t *testing.T) (handler *AuthHandler, mail *mailer.MemoryMailer, db *pgxpool.Pool) {
+	t.Helper()
+
+	db = testdb.New(t)
+	mail = mailer.NewMemoryMailer()
+	handler = NewAuthHandler(
+		repository.NewUserRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		repository.NewUserTokenRepository(db),
+		auth.NewTokenManager("secret", "test", time.Minute, time.Hour),
+		mail,
+		"https://app.example.com",
+		auth.LoginLockout{Threshold: 5, Duration: time.Minute},
+	)
+	return handler, mail, db
+}
+
+// registerTestUser регистрирует пользователя через обработчик и возвращает выданные токены.
+func registerTestUser(t *testing.T, handler *AuthHandler, email, password string) AuthResponse {
+	t.Helper()
+
+	rec := serveJSON(t, handler.Register, http.MethodPost, `{"email":"`+email+`","password":"`+password+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response AuthResponse
+	decodeJSON(t, rec.Body.Bytes(), &response)
+	return response
+}
+
+func decodeJSON(t *testing.T, data []byte, target any) {
+	t.Helper()
+
+	if err := json.Unmarshal(data, target); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+}
+
+func asUser(userID uuid.UUID) func(echo.Context) {
+	return func(c echo.Context) {
+		c.Set(auth.ContextUserIDKey, userID)
+	}
+}
+
+func withParam(name, value string) func(echo.Context) {
+	return func(c echo.Context) {
+		c.SetParamNames(append(c.ParamNames(), name)...)
+		c.SetParamValues(append(c.ParamValues(), value)...)
+	}
+}
+
+func withQuery(query string) func(echo.Context) {
+	return func(c echo.Context) {
+		c.Request().URL.RawQuery = query
+	}
+}
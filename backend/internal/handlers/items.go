@@ -89,6 +89,9 @@ func (h *ItemHandler) Create(c echo.Context) error {
 
 	item, err := h.Items.Create(c.Request().Context(), userID, planID, categoryID, title, req.AmountCents, req.PriorityColor, isCompleted)
 	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "plan or category not found")
 		}
@@ -98,8 +101,13 @@ func (h *ItemHandler) Create(c echo.Context) error {
 		return serverError(c)
 	}
 
+	response, err := h.itemResponse(c.Request().Context(), item)
+	if err != nil {
+		return serverError(c)
+	}
+
 	h.notifyBudgetUpdate(c.Request().Context(), userID, planID)
-	return c.JSON(http.StatusCreated, toItemResponse(item))
+	return c.JSON(http.StatusCreated, response)
 }
 
 // Update обновляет данные расхода.
@@ -137,6 +145,9 @@ func (h *ItemHandler) Update(c echo.Context) error {
 
 	item, err := h.Items.Update(c.Request().Context(), userID, itemID, title, req.AmountCents, req.PriorityColor)
 	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "item not found")
 		}
@@ -146,8 +157,13 @@ func (h *ItemHandler) Update(c echo.Context) error {
 		return serverError(c)
 	}
 
+	response, err := h.itemResponse(c.Request().Context(), item)
+	if err != nil {
+		return serverError(c)
+	}
+
 	h.notifyBudgetUpdate(c.Request().Context(), userID, planID)
-	return c.JSON(http.StatusOK, toItemResponse(item))
+	return c.JSON(http.StatusOK, response)
 }
 
 // Delete удаляет расход.
@@ -171,6 +187,9 @@ func (h *ItemHandler) Delete(c echo.Context) error {
 	}
 
 	if err := h.Items.Delete(c.Request().Context(), userID, itemID); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "item not found")
 		}
@@ -208,14 +227,22 @@ func (h *ItemHandler) Toggle(c echo.Context) error {
 
 	item, err := h.Items.Toggle(c.Request().Context(), userID, itemID, req.IsCompleted)
 	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "item not found")
 		}
 		return serverError(c)
 	}
 
+	response, err := h.itemResponse(c.Request().Context(), item)
+	if err != nil {
+		return serverError(c)
+	}
+
 	h.notifyBudgetUpdate(c.Request().Context(), userID, planID)
-	return c.JSON(http.StatusOK, toItemResponse(item))
+	return c.JSON(http.StatusOK, response)
 }
 
 // Reorder меняет порядок расходов в категории.
@@ -244,6 +271,9 @@ func (h *ItemHandler) Reorder(c echo.Context) error {
 	}
 
 	if err := h.Items.Reorder(c.Request().Context(), userID, itemID, itemIDs); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "item not found")
 		}
@@ -278,40 +308,45 @@ func (h *ItemHandler) UpdateColor(c echo.Context) error {
 
 	item, err := h.Items.UpdateColor(c.Request().Context(), userID, itemID, req.PriorityColor)
 	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "item not found")
 		}
 		return serverError(c)
 	}
 
-	return c.JSON(http.StatusOK, toItemResponse(item))
+	response, err := h.itemResponse(c.Request().Context(), item)
+	if err != nil {
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, response)
 }
 
 func (h *ItemHandler) notifyBudgetUpdate(ctx context.Context, userID, planID uuid.UUID) {
-	if h.Notifier == nil || h.Plans == nil {
-		return
-	}
-
-	plan, err := h.Plans.GetByID(ctx, userID, planID)
-	if err != nil {
-		return
-	}
+	notifyPlanBudget(ctx, h.Notifier, h.Plans, userID, planID)
+}
 
-	spent, err := h.Plans.GetSpentCents(ctx, plan.ID)
+func (h *ItemHandler) itemResponse(ctx context.Context, item models.ExpenseItem) (ItemResponse, error) {
+	spent, err := h.Items.GetSpentCents(ctx, item.ID)
 	if err != nil {
-		return
+		return ItemResponse{}, err
 	}
 
-	publishBudgetUpdate(h.Notifier, userID, plan.ID, spent, plan.BudgetCents-spent)
+	return toItemResponse(item, spent), nil
 }
 
-func toItemResponse(item models.ExpenseItem) ItemResponse {
+func toItemResponse(item models.ExpenseItem, spentCents int64) ItemResponse {
 	return ItemResponse{
-		ID:            item.ID,
-		Title:         item.Title,
-		AmountCents:   item.AmountCents,
-		PriorityColor: item.PriorityColor,
-		IsCompleted:   item.IsCompleted,
-		SortOrder:     item.SortOrder,
+		ID:             item.ID,
+		Title:          item.Title,
+		AmountCents:    item.AmountCents,
+		PriorityColor:  item.PriorityColor,
+		IsCompleted:    item.IsCompleted,
+		SortOrder:      item.SortOrder,
+		SpentCents:     spentCents,
+		RemainingCents: item.AmountCents - spentCents,
 	}
 }
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+)
+
+// jwksMaxAge — сколько секунд клиенты могут кэшировать JWKS.
+const jwksMaxAge = "300"
+
+type JWKSHandler struct {
+	Keys *auth.KeySet
+}
+
+// NewJWKSHandler создает обработчик публикации открытых ключей. При подписи
+// секретом HS256 keys равен nil и набор ключей пуст.
+func NewJWKSHandler(keys *auth.KeySet) *JWKSHandler {
+	return &JWKSHandler{Keys: keys}
+}
+
+// Get возвращает открытые ключи проверки подписи JWT в формате JWKS.
+func (h *JWKSHandler) Get(c echo.Context) error {
+	set := auth.JWKS{Keys: []auth.JWK{}}
+	if h.Keys != nil {
+		set = h.Keys.JWKS(time.Now())
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age="+jwksMaxAge)
+	return c.JSON(http.StatusOK, set)
+}
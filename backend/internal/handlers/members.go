@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const (
+	inviteTokenBytes = 32
+	inviteTTL        = 7 * 24 * time.Hour
+)
+
+type MemberHandler struct {
+	Members *repository.MemberRepository
+}
+
+// NewMemberHandler создает обработчик участников совместных планов.
+func NewMemberHandler(members *repository.MemberRepository) *MemberHandler {
+	return &MemberHandler{Members: members}
+}
+
+type UpdateMemberRequest struct {
+	Role models.PlanRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type InviteRequest struct {
+	Email string          `json:"email" validate:"required,email"`
+	Role  models.PlanRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type AcceptInviteRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type MemberResponse struct {
+	UserID    uuid.UUID       `json:"user_id"`
+	Email     string          `json:"email"`
+	Name      *string         `json:"name,omitempty"`
+	Role      models.PlanRole `json:"role"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type InviteResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	PlanID    uuid.UUID       `json:"plan_id"`
+	Email     string          `json:"email"`
+	Role      models.PlanRole `json:"role"`
+	Token     string          `json:"token"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// List возвращает участников плана.
+func (h *MemberHandler) List(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	members, err := h.Members.List(c.Request().Context(), userID, planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "plan not found")
+		}
+		return serverError(c)
+	}
+
+	response := make([]MemberResponse, 0, len(members))
+	for _, member := range members {
+		response = append(response, toMemberResponse(member))
+	}
+
+	return c.JSON(http.StatusOK, map[string][]MemberResponse{"members": response})
+}
+
+// UpdateRole меняет роль участника плана.
+func (h *MemberHandler) UpdateRole(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		return badRequest(c, "invalid user id")
+	}
+
+	var req UpdateMemberRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	member, err := h.Members.UpdateRole(c.Request().Context(), userID, planID, memberID, req.Role)
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "member not found")
+		}
+		if errors.Is(err, repository.ErrInvalid) {
+			return badRequest(c, "invalid role")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, toMemberResponse(member))
+}
+
+// Remove исключает участника из плана или выводит из него текущего пользователя.
+func (h *MemberHandler) Remove(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	memberID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		return badRequest(c, "invalid user id")
+	}
+
+	if err := h.Members.Remove(c.Request().Context(), userID, planID, memberID); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "member not found")
+		}
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Invite создает приглашение в план. Токен возвращается только в этом ответе.
+func (h *MemberHandler) Invite(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	var req InviteRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	token, err := auth.GenerateToken(inviteTokenBytes)
+	if err != nil {
+		return serverError(c)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	invite, err := h.Members.CreateInvite(c.Request().Context(), userID, planID, email, req.Role, auth.HashToken(token), time.Now().Add(inviteTTL))
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "plan not found")
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return conflict(c, "user is already a member")
+		}
+		if errors.Is(err, repository.ErrInvalid) {
+			return badRequest(c, "invalid role")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusCreated, InviteResponse{
+		ID:        invite.ID,
+		PlanID:    invite.PlanID,
+		Email:     invite.Email,
+		Role:      invite.Role,
+		Token:     token,
+		ExpiresAt: invite.ExpiresAt,
+	})
+}
+
+// AcceptInvite добавляет текущего пользователя в план по токену приглашения.
+func (h *MemberHandler) AcceptInvite(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	var req AcceptInviteRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	member, err := h.Members.AcceptInvite(c.Request().Context(), userID, auth.HashToken(strings.TrimSpace(req.Token)))
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "invite not found or expired")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"plan_id": member.PlanID,
+		"member":  toMemberResponse(member),
+	})
+}
+
+func toMemberResponse(member models.PlanMember) MemberResponse {
+	return MemberResponse{
+		UserID:    member.UserID,
+		Email:     member.Email,
+		Name:      member.Name,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+	}
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// sharedPlan — план владельца с редактором и наблюдателем, принятыми по
+// приглашениям, и по одной категории, расходу и транзакции в нем.
+type sharedPlan struct {
+	db           *pgxpool.Pool
+	users        *repository.UserRepository
+	plans        *PlanHandler
+	members      *MemberHandler
+	categories   *CategoryHandler
+	items        *ItemHandler
+	transactions *TransactionHandler
+
+	owner, editor, viewer, outsider uuid.UUID
+
+	planID, categoryID, itemID, transactionID uuid.UUID
+}
+
+func newSharedPlan(t *testing.T) *sharedPlan {
+	t.Helper()
+
+	db := testdb.New(t)
+	ctx := context.Background()
+	plans := repository.NewPlanRepository(db)
+	items := repository.NewItemRepository(db)
+	transactions := repository.NewTransactionRepository(db)
+	categories := repository.NewCategoryRepository(db)
+
+	s := &sharedPlan{
+		db:           db,
+		users:        repository.NewUserRepository(db),
+		plans:        NewPlanHandler(plans, repository.NewUserRepository(db), nil),
+		members:      NewMemberHandler(repository.NewMemberRepository(db)),
+		categories:   NewCategoryHandler(categories, plans, nil),
+		items:        NewItemHandler(items, plans, nil),
+		transactions: NewTransactionHandler(transactions, items, plans, nil),
+	}
+
+	for _, user := range []struct {
+		id    *uuid.UUID
+		email string
+	}{
+		{&s.owner, "owner@example.com"},
+		{&s.editor, "editor@example.com"},
+		{&s.viewer, "viewer@example.com"},
+		{&s.outsider, "outsider@example.com"},
+	} {
+		created, err := s.users.Create(ctx, user.email, "hash", nil)
+		if err != nil {
+			t.Fatalf("create %s: %v", user.email, err)
+		}
+		*user.id = created.ID
+	}
+
+	now := time.Now()
+	plan, err := plans.Create(ctx, s.owner, "Общий план", 100000, nil, now, now.AddDate(0, 1, 0), "#FFFFFF", false)
+	if err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	category, err := categories.Create(ctx, s.owner, plan.ID, "Еда", models.CategoryTypeMandatory)
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	item, err := items.Create(ctx, s.owner, plan.ID, category.ID, "Продукты", 5000, models.PriorityColorRed, false)
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	transaction, err := transactions.Create(ctx, s.owner, item.ID, 1000, now, nil, nil)
+	if err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+	s.planID, s.categoryID, s.itemID, s.transactionID = plan.ID, category.ID, item.ID, transaction.ID
+
+	s.join(t, s.editor, "editor@example.com", models.PlanRoleEditor)
+	s.join(t, s.viewer, "viewer@example.com", models.PlanRoleViewer)
+	return s
+}
+
+// invite создает приглашение от имени владельца и возвращает его токен.
+func (s *sharedPlan) invite(t *testing.T, email string, role models.PlanRole) string {
+	t.Helper()
+
+	rec := serveJSON(t, s.members.Invite, http.MethodPost, `{"email":"`+email+`","role":"`+string(role)+`"}`,
+		asUser(s.owner), withParam("id", s.planID.String()))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("invite %s: expected 201, got %d: %s", email, rec.Code, rec.Body.String())
+	}
+	var response InviteResponse
+	decodeJSON(t, rec.Body.Bytes(), &response)
+	return response.Token
+}
+
+func (s *sharedPlan) accept(t *testing.T, userID uuid.UUID, token string) int {
+	t.Helper()
+
+	return serveJSON(t, s.members.AcceptInvite, http.MethodPost, `{"token":"`+token+`"}`, asUser(userID)).Code
+}
+
+func (s *sharedPlan) join(t *testing.T, userID uuid.UUID, email string, role models.PlanRole) {
+	t.Helper()
+
+	if code := s.accept(t, userID, s.invite(t, email, role)); code != http.StatusOK {
+		t.Fatalf("accept invite for %s: expected 200, got %d", email, code)
+	}
+}
+
+type planRequest struct {
+	name    string
+	handler echo.HandlerFunc
+	method  string
+	body    string
+	params  []func(echo.Context)
+}
+
+// expect выполняет запросы от имени userID и проверяет код ответа.
+func (s *sharedPlan) expect(t *testing.T, userID uuid.UUID, want int, requests ...planRequest) {
+	t.Helper()
+
+	for _, request := range requests {
+		rec := serveJSON(t, request.handler, request.method, request.body, append([]func(echo.Context){asUser(userID)}, request.params...)...)
+		if rec.Code != want {
+			t.Fatalf("%s: expected %d, got %d: %s", request.name, want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func (s *sharedPlan) planParam() func(echo.Context) {
+	return withParam("planId", s.planID.String())
+}
+
+func (s *sharedPlan) idParam() func(echo.Context) {
+	return withParam("id", s.planID.String())
+}
+
+func (s *sharedPlan) categoryParam() func(echo.Context) {
+	return withParam("categoryId", s.categoryID.String())
+}
+
+func (s *sharedPlan) itemParam() func(echo.Context) {
+	return withParam("itemId", s.itemID.String())
+}
+
+func (s *sharedPlan) transactionParam() func(echo.Context) {
+	return withParam("transactionId", s.transactionID.String())
+}
+
+// writes — изменения категорий, расходов и транзакций плана; удаления идут
+// последними, чтобы у редактора проходил весь список.
+func (s *sharedPlan) writes() []planRequest {
+	return []planRequest{
+		{"create category", s.categories.Create, http.MethodPost, `{"title":"Новая","category_type":"optional"}`, []func(echo.Context){s.planParam()}},
+		{"update category", s.categories.Update, http.MethodPut, `{"title":"Продукты и быт"}`, []func(echo.Context){s.categoryParam()}},
+		{"create item", s.items.Create, http.MethodPost, `{"title":"Кофе","amount_cents":300,"priority_color":"green"}`, []func(echo.Context){s.planParam(), s.categoryParam()}},
+		{"update item", s.items.Update, http.MethodPut, `{"title":"Продукты","amount_cents":6000,"priority_color":"red"}`, []func(echo.Context){s.itemParam()}},
+		{"toggle item", s.items.Toggle, http.MethodPatch, `{"is_completed":true}`, []func(echo.Context){s.itemParam()}},
+		{"create transaction", s.transactions.Create, http.MethodPost, `{"amount_cents":500}`, []func(echo.Context){s.itemParam()}},
+		{"update transaction", s.transactions.Update, http.MethodPut, `{"amount_cents":1500}`, []func(echo.Context){s.transactionParam()}},
+		{"delete transaction", s.transactions.Delete, http.MethodDelete, ``, []func(echo.Context){s.transactionParam()}},
+		{"delete item", s.items.Delete, http.MethodDelete, ``, []func(echo.Context){s.itemParam()}},
+		{"delete category", s.categories.Delete, http.MethodDelete, ``, []func(echo.Context){s.categoryParam()}},
+	}
+}
+
+// reads — просмотр плана, его участников и транзакций расхода.
+func (s *sharedPlan) reads() []planRequest {
+	return []planRequest{
+		{"get plan", s.plans.Get, http.MethodGet, ``, []func(echo.Context){s.idParam()}},
+		{"list members", s.members.List, http.MethodGet, ``, []func(echo.Context){s.idParam()}},
+		{"list transactions", s.transactions.List, http.MethodGet, ``, []func(echo.Context){s.itemParam()}},
+	}
+}
+
+// TestPlanRoleMatrix проверяет права ролей: наблюдатель только читает план,
+// редактор меняет его содержимое, но не участников и не сам план, а
+// посторонний не видит план вовсе.
+func TestPlanRoleMatrix(t *testing.T) {
+	s := newSharedPlan(t)
+
+	s.expect(t, s.viewer, http.StatusOK, s.reads()...)
+	s.expect(t, s.viewer, http.StatusForbidden, s.writes()...)
+
+	s.expect(t, s.outsider, http.StatusNotFound, s.reads()...)
+	s.expect(t, s.outsider, http.StatusNotFound, s.writes()...)
+
+	memberParam := withParam("userId", s.viewer.String())
+	s.expect(t, s.editor, http.StatusForbidden,
+		planRequest{"change role", s.members.UpdateRole, http.MethodPut, `{"role":"editor"}`, []func(echo.Context){s.idParam(), memberParam}},
+		planRequest{"remove member", s.members.Remove, http.MethodDelete, ``, []func(echo.Context){s.idParam(), memberParam}},
+		planRequest{"invite", s.members.Invite, http.MethodPost, `{"email":"new@example.com","role":"viewer"}`, []func(echo.Context){s.idParam()}},
+		planRequest{"delete plan", s.plans.Delete, http.MethodDelete, ``, []func(echo.Context){s.idParam()}},
+	)
+	s.expect(t, s.viewer, http.StatusForbidden,
+		planRequest{"delete plan", s.plans.Delete, http.MethodDelete, ``, []func(echo.Context){s.idParam()}},
+	)
+
+	s.expect(t, s.editor, http.StatusOK, s.reads()...)
+	for _, request := range s.writes() {
+		rec := serveJSON(t, request.handler, request.method, request.body, append([]func(echo.Context){asUser(s.editor)}, request.params...)...)
+		if rec.Code < 200 || rec.Code >= 300 {
+			t.Fatalf("editor %s: expected success, got %d: %s", request.name, rec.Code, rec.Body.String())
+		}
+	}
+
+	s.expect(t, s.owner, http.StatusNoContent,
+		planRequest{"delete plan", s.plans.Delete, http.MethodDelete, ``, []func(echo.Context){s.idParam()}},
+	)
+}
+
+// TestInviteAcceptance проверяет, что приглашение принимает только
+// пользователь с указанным email, один раз и до истечения срока.
+func TestInviteAcceptance(t *testing.T) {
+	s := newSharedPlan(t)
+	ctx := context.Background()
+
+	invitee, err := s.users.Create(ctx, "invitee@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create invitee: %v", err)
+	}
+
+	token := s.invite(t, "Invitee@Example.com", models.PlanRoleViewer)
+	if code := s.accept(t, s.outsider, token); code != http.StatusForbidden {
+		t.Fatalf("accept with another email: expected 403, got %d", code)
+	}
+	if code := s.accept(t, invitee.ID, token); code != http.StatusOK {
+		t.Fatalf("accept: expected 200, got %d", code)
+	}
+	if code := s.accept(t, invitee.ID, token); code != http.StatusNotFound {
+		t.Fatalf("accept twice: expected 404, got %d", code)
+	}
+	s.expect(t, invitee.ID, http.StatusOK, s.reads()...)
+	s.expect(t, invitee.ID, http.StatusForbidden, s.writes()[0])
+
+	token = s.invite(t, "outsider@example.com", models.PlanRoleEditor)
+	if _, err := s.db.Exec(ctx, `UPDATE plan_invites SET expires_at = NOW() - INTERVAL '1 minute' WHERE plan_id = $1 AND email = 'outsider@example.com'`, s.planID); err != nil {
+		t.Fatalf("expire invite: %v", err)
+	}
+	if code := s.accept(t, s.outsider, token); code != http.StatusNotFound {
+		t.Fatalf("accept an expired invite: expected 404, got %d", code)
+	}
+	if code := s.accept(t, s.outsider, "unknown-token"); code != http.StatusNotFound {
+		t.Fatalf("accept an unknown token: expected 404, got %d", code)
+	}
+	s.expect(t, s.outsider, http.StatusNotFound, s.reads()...)
+}
+
+// TestRemovedMemberLosesAccess проверяет, что исключенный владельцем и
+// вышедший сам участник теряют доступ к плану, а владелец выйти не может.
+func TestRemovedMemberLosesAccess(t *testing.T) {
+	s := newSharedPlan(t)
+
+	s.expect(t, s.owner, http.StatusNoContent,
+		planRequest{"remove viewer", s.members.Remove, http.MethodDelete, ``, []func(echo.Context){s.idParam(), withParam("userId", s.viewer.String())}},
+	)
+	s.expect(t, s.editor, http.StatusNoContent,
+		planRequest{"leave", s.members.Remove, http.MethodDelete, ``, []func(echo.Context){s.idParam(), withParam("userId", s.editor.String())}},
+	)
+
+	for _, userID := range []uuid.UUID{s.viewer, s.editor} {
+		s.expect(t, userID, http.StatusNotFound, s.reads()...)
+		s.expect(t, userID, http.StatusNotFound, s.writes()...)
+	}
+
+	s.expect(t, s.owner, http.StatusForbidden,
+		planRequest{"remove owner", s.members.Remove, http.MethodDelete, ``, []func(echo.Context){s.idParam(), withParam("userId", s.owner.String())}},
+	)
+}
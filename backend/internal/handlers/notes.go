@@ -86,6 +86,9 @@ func (h *NoteHandler) Create(c echo.Context) error {
 
 	note, err := h.Notes.Create(c.Request().Context(), userID, planID, content, req.NoteType)
 	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "plan not found")
 		}
@@ -122,6 +125,9 @@ func (h *NoteHandler) Update(c echo.Context) error {
 
 	note, err := h.Notes.Update(c.Request().Context(), userID, noteID, content, req.NoteType)
 	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "note not found")
 		}
@@ -144,6 +150,9 @@ func (h *NoteHandler) Delete(c echo.Context) error {
 	}
 
 	if err := h.Notes.Delete(c.Request().Context(), userID, noteID); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "note not found")
 		}
@@ -179,6 +188,9 @@ func (h *NoteHandler) Reorder(c echo.Context) error {
 	}
 
 	if err := h.Notes.Reorder(c.Request().Context(), userID, noteID, noteIDs); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "note not found")
 		}
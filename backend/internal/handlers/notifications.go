@@ -1,14 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"example.com/ai-budget-planner/backend/internal/auth"
 	"example.com/ai-budget-planner/backend/internal/notifications"
+	"example.com/ai-budget-planner/backend/internal/repository"
 )
 
 type NotificationHandler struct {
@@ -20,7 +24,10 @@ func NewNotificationHandler(hub *notifications.Hub) *NotificationHandler {
 	return &NotificationHandler{Hub: hub}
 }
 
-// Stream открывает SSE-поток событий для пользователя.
+// Stream открывает SSE-поток событий для пользователя. Если клиент передал
+// Last-Event-ID, сначала досылаются события, пропущенные во время переподключения.
+// В поле id событий передается курсор с уже полученными ID, поэтому при
+// следующем переподключении они не досылаются повторно.
 func (h *NotificationHandler) Stream(c echo.Context) error {
 	userID, ok := auth.UserIDFromContext(c)
 	if !ok {
@@ -37,13 +44,35 @@ func (h *NotificationHandler) Stream(c echo.Context) error {
 		return serverError(c)
 	}
 
+	ctx := c.Request().Context()
+
+	// Подписка оформляется до чтения журнала, чтобы не потерять события,
+	// опубликованные во время повторной доставки; дубликаты отсекаются по курсору.
 	ch, unsubscribe := h.Hub.Subscribe(userID)
 	defer unsubscribe()
 
-	_ = writeSSE(c, notifications.Event{Type: "connected", Data: map[string]string{"user_id": userID.String()}})
+	_ = writeSSE(c, notifications.Event{Type: "connected", Data: map[string]string{"user_id": userID.String()}}, nil)
+
+	// Живые события и события из журнала приходят не строго по возрастанию ID,
+	// поэтому отсекаются только те, что клиент уже получил.
+	cursor := notifications.ParseCursor(lastEventID(c))
+	if lastID := cursor.LastID(); lastID > 0 {
+		missed, err := h.Hub.Replay(ctx, userID, lastID)
+		if err != nil {
+			slog.Warn("notification replay failed", slog.String("user_id", userID.String()), slog.String("error", err.Error()))
+		}
+		for _, event := range missed {
+			if cursor.Delivered(event.ID) {
+				cursor.Add(event)
+				continue
+			}
+			if err := writeSSE(c, event, cursor); err != nil {
+				return nil
+			}
+		}
+	}
 	flusher.Flush()
 
-	ctx := c.Request().Context()
 	for {
 		select {
 		case <-ctx.Done():
@@ -52,7 +81,10 @@ func (h *NotificationHandler) Stream(c echo.Context) error {
 			if !ok {
 				return nil
 			}
-			if err := writeSSE(c, event); err != nil {
+			if event.ID != 0 && cursor.Delivered(event.ID) {
+				continue
+			}
+			if err := writeSSE(c, event, cursor); err != nil {
 				return nil
 			}
 			flusher.Flush()
@@ -60,12 +92,30 @@ func (h *NotificationHandler) Stream(c echo.Context) error {
 	}
 }
 
-func writeSSE(c echo.Context, event notifications.Event) error {
+// lastEventID читает курсор последнего полученного события из заголовка
+// Last-Event-ID или query-параметра last_event_id (для клиентов без заголовков).
+func lastEventID(c echo.Context) string {
+	if value := c.Request().Header.Get("Last-Event-ID"); strings.TrimSpace(value) != "" {
+		return value
+	}
+
+	return c.QueryParam("last_event_id")
+}
+
+// writeSSE пишет событие в поток. Событие с ID отмечается в cursor, и
+// обновленный курсор уходит клиенту в поле id.
+func writeSSE(c echo.Context, event notifications.Event, cursor *notifications.Cursor) error {
 	payload, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
 
+	if event.ID != 0 && cursor != nil {
+		cursor.Add(event)
+		if _, err := c.Response().Write([]byte("id: " + cursor.String() + "\n")); err != nil {
+			return err
+		}
+	}
 	if _, err := c.Response().Write([]byte("event: " + event.Type + "\n")); err != nil {
 		return err
 	}
@@ -76,6 +126,55 @@ func writeSSE(c echo.Context, event notifications.Event) error {
 	return nil
 }
 
+// notifyPlanBudget пересчитывает траты плана и публикует событие budget_updated его участникам.
+func notifyPlanBudget(ctx context.Context, hub *notifications.Hub, plans *repository.PlanRepository, userID, planID uuid.UUID) {
+	if hub == nil || plans == nil {
+		return
+	}
+
+	plan, err := plans.GetByID(ctx, userID, planID)
+	if err != nil {
+		return
+	}
+
+	spent, err := plans.GetSpentCents(ctx, plan.ID)
+	if err != nil {
+		return
+	}
+
+	publishPlanBudgetUpdate(ctx, hub, plans, userID, plan.ID, spent, plan.BudgetCents-spent)
+}
+
+// publishPlanBudgetUpdate рассылает budget_updated всем участникам плана и проверяет
+// правила оповещений: каждое впервые сработавшее правило приходит событием budget_alert.
+// Если список участников получить не удалось, события получает только userID.
+func publishPlanBudgetUpdate(ctx context.Context, hub *notifications.Hub, plans *repository.PlanRepository, userID, planID uuid.UUID, spentCents, remainingCents int64) {
+	if hub == nil {
+		return
+	}
+
+	recipients, err := plans.ListMemberIDs(ctx, planID)
+	if err != nil || len(recipients) == 0 {
+		recipients = []uuid.UUID{userID}
+	}
+
+	for _, recipient := range recipients {
+		publishBudgetUpdate(hub, recipient, planID, spentCents, remainingCents)
+	}
+
+	alerts, err := plans.EvaluateAlerts(ctx, planID)
+	if err != nil {
+		slog.Warn("budget alerts evaluation failed", slog.String("plan_id", planID.String()), slog.String("error", err.Error()))
+		return
+	}
+
+	for _, alert := range alerts {
+		for _, recipient := range recipients {
+			hub.Publish(recipient, notifications.Event{Type: "budget_alert", Data: alert})
+		}
+	}
+}
+
 func publishBudgetUpdate(hub *notifications.Hub, userID uuid.UUID, planID uuid.UUID, spentCents int64, remainingCents int64) {
 	if hub == nil {
 		return
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/notifications"
+)
+
+// journalStore — журнал событий в памяти. ID идут с шагом 10, как у общей
+// последовательности, где между событиями пользователя есть чужие.
+type journalStore struct {
+	mu     sync.Mutex
+	nextID int64
+	events map[uuid.UUID][]notifications.Event
+}
+
+func newJournalStore() *journalStore {
+	return &journalStore{events: make(map[uuid.UUID][]notifications.Event)}
+}
+
+func (s *journalStore) Append(_ context.Context, userID uuid.UUID, event notifications.Event) (notifications.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID += 10
+	event.ID = s.nextID
+	s.events[userID] = append(s.events[userID], event)
+	return event, nil
+}
+
+// insertLate добавляет событие с заданным ID, как если бы его транзакция
+// закоммитилась позже событий с большими ID.
+func (s *journalStore) insertLate(userID uuid.UUID, event notifications.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.Timestamp = time.Now().UTC()
+	s.events[userID] = append(s.events[userID], event)
+	sort.Slice(s.events[userID], func(i, j int) bool { return s.events[userID][i].ID < s.events[userID][j].ID })
+}
+
+func (s *journalStore) ListAfter(_ context.Context, userID uuid.UUID, afterID int64, overlap time.Duration, limit int) ([]notifications.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var since time.Time
+	found := false
+	for _, event := range s.events[userID] {
+		if event.ID == afterID {
+			since, found = event.Timestamp.Add(-overlap), true
+		}
+	}
+
+	out := make([]notifications.Event, 0)
+	for _, event := range s.events[userID] {
+		inWindow := found && event.ID < afterID && !event.Timestamp.Before(since)
+		if (event.ID > afterID || inWindow) && len(out) < limit {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+// streamOnce подключается к потоку с Last-Event-ID и сразу отключается: поток
+// успевает дослать пропущенные события. Возвращает ID досланных событий и
+// последний курсор из поля id.
+func streamOnce(t *testing.T, handler *NotificationHandler, userID uuid.UUID, lastEventID string) ([]int64, string) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := serveJSON(t, handler.Stream, http.MethodGet, ``, asUser(userID), func(c echo.Context) {
+		c.SetRequest(c.Request().WithContext(ctx))
+		c.Request().Header.Set("Last-Event-ID", lastEventID)
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stream: expected 200, got %d", rec.Code)
+	}
+
+	ids := make([]int64, 0)
+	cursor := ""
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "id: "); ok {
+			cursor = value
+		}
+		if value, ok := strings.CutPrefix(line, "data: "); ok {
+			var event notifications.Event
+			if err := json.Unmarshal([]byte(value), &event); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			if event.ID != 0 {
+				ids = append(ids, event.ID)
+			}
+		}
+	}
+	return ids, cursor
+}
+
+// TestStreamSkipsDeliveredReplay проверяет, что при переподключении окно
+// перед Last-Event-ID не досылается повторно: приходит только событие,
+// закоммиченное позже уже полученных.
+func TestStreamSkipsDeliveredReplay(t *testing.T) {
+	store := newJournalStore()
+	hub := notifications.NewHub(notifications.WithStore(store))
+	handler := NewNotificationHandler(hub)
+	userID := uuid.New()
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(userID, notifications.Event{Type: "budget_updated"})
+	}
+
+	ids, cursor := streamOnce(t, handler, userID, "10")
+	if !reflect.DeepEqual(ids, []int64{20, 30}) || cursor != "30:10,20" {
+		t.Fatalf("first reconnect: expected 20, 30 and cursor 30:10,20, got %v and %q", ids, cursor)
+	}
+
+	store.insertLate(userID, notifications.Event{ID: 25, Type: "budget_updated"})
+
+	ids, cursor = streamOnce(t, handler, userID, cursor)
+	if !reflect.DeepEqual(ids, []int64{25}) || cursor != "30:10,20,25" {
+		t.Fatalf("second reconnect: expected only the late event and cursor 30:10,20,25, got %v and %q", ids, cursor)
+	}
+
+	ids, cursor = streamOnce(t, handler, userID, cursor)
+	if len(ids) != 0 || cursor != "" {
+		t.Fatalf("third reconnect: expected nothing to replay, got %v and %q", ids, cursor)
+	}
+
+	// Простой числовой Last-Event-ID по-прежнему досылает все окно.
+	ids, _ = streamOnce(t, handler, userID, "30")
+	if !reflect.DeepEqual(ids, []int64{10, 20, 25}) {
+		t.Fatalf("plain last event id: expected the whole window, got %v", ids)
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const (
+	maxPersonalTokensPerUser = 25
+	// personalTokenPrefixLength — сколько символов токена показывается в списке для узнавания.
+	personalTokenPrefixLength = 12
+)
+
+type PersonalTokenHandler struct {
+	Tokens *repository.PersonalTokenRepository
+}
+
+// NewPersonalTokenHandler создает обработчик персональных токенов доступа.
+func NewPersonalTokenHandler(tokens *repository.PersonalTokenRepository) *PersonalTokenHandler {
+	return &PersonalTokenHandler{Tokens: tokens}
+}
+
+type CreatePersonalTokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,max=3,dive,oneof=read write ai"`
+	ExpiresInDays *int     `json:"expires_in_days" validate:"omitempty,min=1,max=3650"`
+}
+
+type PersonalTokenResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Token       string     `json:"token,omitempty"`
+}
+
+// Create выпускает персональный токен и один раз возвращает его значение.
+func (h *PersonalTokenHandler) Create(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	var req CreatePersonalTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return badRequest(c, "name is required")
+	}
+
+	count, err := h.Tokens.CountActive(c.Request().Context(), userID)
+	if err != nil {
+		return serverError(c)
+	}
+	if count >= maxPersonalTokensPerUser {
+		return conflict(c, "personal token limit reached")
+	}
+
+	token, err := auth.GeneratePersonalToken()
+	if err != nil {
+		return serverError(c)
+	}
+
+	record := models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: token[:personalTokenPrefixLength],
+		TokenHash:   auth.HashToken(token),
+		Scopes:      uniqueStrings(req.Scopes),
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		record.ExpiresAt = &expiresAt
+	}
+
+	created, err := h.Tokens.Create(c.Request().Context(), record)
+	if err != nil {
+		return serverError(c)
+	}
+
+	response := toPersonalTokenResponse(created)
+	response.Token = token
+	return c.JSON(http.StatusCreated, response)
+}
+
+// List возвращает действующие персональные токены пользователя без их значений.
+func (h *PersonalTokenHandler) List(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	tokens, err := h.Tokens.ListByUser(c.Request().Context(), userID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	response := make([]PersonalTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, toPersonalTokenResponse(token))
+	}
+
+	return c.JSON(http.StatusOK, map[string][]PersonalTokenResponse{"tokens": response})
+}
+
+// Revoke отзывает персональный токен.
+func (h *PersonalTokenHandler) Revoke(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	tokenID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid token id")
+	}
+
+	if err := h.Tokens.Revoke(c.Request().Context(), userID, tokenID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "token not found")
+		}
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toPersonalTokenResponse(token models.PersonalAccessToken) PersonalTokenResponse {
+	return PersonalTokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.Scopes,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	return out
+}
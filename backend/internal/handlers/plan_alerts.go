@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+type AlertRuleRequest struct {
+	RuleType         models.AlertRuleType `json:"rule_type" validate:"required,oneof=plan_total category_share"`
+	ThresholdPercent int                  `json:"threshold_percent" validate:"min=1,max=1000"`
+	CategoryID       *string              `json:"category_id"`
+}
+
+type AlertRulesRequest struct {
+	Rules []AlertRuleRequest `json:"rules" validate:"max=20,dive"`
+}
+
+type AlertRuleResponse struct {
+	ID               uuid.UUID            `json:"id"`
+	RuleType         models.AlertRuleType `json:"rule_type"`
+	ThresholdPercent int                  `json:"threshold_percent"`
+	CategoryID       *uuid.UUID           `json:"category_id,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+}
+
+// ListAlertRules возвращает правила оповещений плана.
+func (h *PlanHandler) ListAlertRules(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	rules, err := h.Plans.ListAlertRules(c.Request().Context(), userID, planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "plan not found")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]AlertRuleResponse{"rules": toAlertRuleResponses(rules)})
+}
+
+// ReplaceAlertRules заменяет правила оповещений плана.
+func (h *PlanHandler) ReplaceAlertRules(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	var req AlertRulesRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	inputs := make([]repository.AlertRuleInput, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		input := repository.AlertRuleInput{RuleType: rule.RuleType, ThresholdPercent: rule.ThresholdPercent}
+		if rule.CategoryID != nil {
+			categoryID, err := uuid.Parse(*rule.CategoryID)
+			if err != nil {
+				return badRequest(c, "invalid category id")
+			}
+			input.CategoryID = &categoryID
+		}
+		inputs = append(inputs, input)
+	}
+
+	rules, err := h.Plans.ReplaceAlertRules(c.Request().Context(), userID, planID, inputs)
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "plan not found")
+		}
+		if errors.Is(err, repository.ErrInvalid) {
+			return badRequest(c, "invalid alert rules")
+		}
+		return serverError(c)
+	}
+
+	// Новые правила сразу проверяются по текущим тратам.
+	notifyPlanBudget(c.Request().Context(), h.Notifier, h.Plans, userID, planID)
+	return c.JSON(http.StatusOK, map[string][]AlertRuleResponse{"rules": toAlertRuleResponses(rules)})
+}
+
+func toAlertRuleResponses(rules []models.AlertRule) []AlertRuleResponse {
+	response := make([]AlertRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, AlertRuleResponse{
+			ID:               rule.ID,
+			RuleType:         rule.RuleType,
+			ThresholdPercent: rule.ThresholdPercent,
+			CategoryID:       rule.CategoryID,
+			CreatedAt:        rule.CreatedAt,
+		})
+	}
+	return response
+}
@@ -30,6 +30,11 @@ func buildPlanDetailResponse(ctx context.Context, plans *repository.PlanReposito
 		return PlanDetailResponse{}, err
 	}
 
+	itemSpent, err := plans.ListItemSpentCents(ctx, categoryIDs)
+	if err != nil {
+		return PlanDetailResponse{}, err
+	}
+
 	notes, err := plans.ListNotes(ctx, plan.ID)
 	if err != nil {
 		return PlanDetailResponse{}, err
@@ -54,14 +59,7 @@ func buildPlanDetailResponse(ctx context.Context, plans *repository.PlanReposito
 		if !ok {
 			continue
 		}
-		categoryResponses[index].Items = append(categoryResponses[index].Items, ItemResponse{
-			ID:            item.ID,
-			Title:         item.Title,
-			AmountCents:   item.AmountCents,
-			PriorityColor: item.PriorityColor,
-			IsCompleted:   item.IsCompleted,
-			SortOrder:     item.SortOrder,
-		})
+		categoryResponses[index].Items = append(categoryResponses[index].Items, toItemResponse(item, itemSpent[item.ID]))
 	}
 
 	noteResponses := make([]NoteResponse, 0, len(notes))
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+type RecurrenceRequest struct {
+	Recurrence   models.Recurrence `json:"recurrence" validate:"required,oneof=none monthly biweekly custom"`
+	IntervalDays *int              `json:"interval_days" validate:"omitempty,min=1,max=366"`
+	CarryOver    bool              `json:"carry_over"`
+}
+
+// UpdateRecurrence задает правило повторения плана.
+func (h *PlanHandler) UpdateRecurrence(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	var req RecurrenceRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	if req.Recurrence == models.RecurrenceCustom && req.IntervalDays == nil {
+		return badRequest(c, "interval_days is required for custom recurrence")
+	}
+
+	plan, err := h.Plans.UpdateRecurrence(c.Request().Context(), userID, planID, req.Recurrence, req.IntervalDays, req.CarryOver)
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "plan not found")
+		}
+		return serverError(c)
+	}
+
+	spent, err := h.Plans.GetSpentCents(c.Request().Context(), plan.ID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, toPlanResponse(plan, spent))
+}
+
+// Rollover сразу создает план следующего периода, не дожидаясь планировщика.
+func (h *PlanHandler) Rollover(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid plan id")
+	}
+
+	role, err := h.Plans.GetRole(c.Request().Context(), userID, planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "plan not found")
+		}
+		return serverError(c)
+	}
+	if role == models.PlanRoleViewer {
+		return forbidden(c)
+	}
+
+	plan, err := h.Plans.Rollover(c.Request().Context(), planID)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return conflict(c, "next plan already exists")
+		}
+		if errors.Is(err, repository.ErrInvalid) {
+			return badRequest(c, "plan is not recurring")
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "plan not found")
+		}
+		return serverError(c)
+	}
+
+	spent, err := h.Plans.GetSpentCents(c.Request().Context(), plan.ID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	response := toPlanResponse(plan, spent)
+	publishPlanBudgetUpdate(c.Request().Context(), h.Notifier, h.Plans, userID, plan.ID, spent, response.RemainingCents)
+	return c.JSON(http.StatusCreated, response)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/currency"
 	"example.com/ai-budget-planner/backend/internal/models"
 	"example.com/ai-budget-planner/backend/internal/notifications"
 	"example.com/ai-budget-planner/backend/internal/repository"
@@ -22,19 +23,23 @@ const (
 
 type PlanHandler struct {
 	Plans    *repository.PlanRepository
+	Users    *repository.UserRepository
 	Notifier *notifications.Hub
 }
 
-// NewPlanHandler создает обработчик планов бюджета.
-func NewPlanHandler(plans *repository.PlanRepository, notifier *notifications.Hub) *PlanHandler {
-	return &PlanHandler{Plans: plans, Notifier: notifier}
+// NewPlanHandler создает обработчик планов бюджета. Настройки пользователя
+// из users задают период нового плана, если даты не переданы.
+func NewPlanHandler(plans *repository.PlanRepository, users *repository.UserRepository, notifier *notifications.Hub) *PlanHandler {
+	return &PlanHandler{Plans: plans, Users: users, Notifier: notifier}
 }
 
 type PlanRequest struct {
 	Title           string  `json:"title" validate:"required,max=200"`
 	BudgetCents     int64   `json:"budget_cents" validate:"gt=0"`
-	PeriodStart     string  `json:"period_start" validate:"required"`
-	PeriodEnd       string  `json:"period_end" validate:"required"`
+	Currency        *string `json:"currency"`
+	PeriodStart     string  `json:"period_start"`
+	PeriodEnd       string  `json:"period_end"`
+	Period          string  `json:"period" validate:"omitempty,oneof=month week"`
 	BackgroundColor *string `json:"background_color"`
 	IsAIGenerated   *bool   `json:"is_ai_generated"`
 }
@@ -44,17 +49,23 @@ type ReorderRequest struct {
 }
 
 type PlanResponse struct {
-	ID              uuid.UUID `json:"id"`
-	Title           string    `json:"title"`
-	BudgetCents     int64     `json:"budget_cents"`
-	PeriodStart     string    `json:"period_start"`
-	PeriodEnd       string    `json:"period_end"`
-	BackgroundColor string    `json:"background_color"`
-	IsAIGenerated   bool      `json:"is_ai_generated"`
-	SpentCents      int64     `json:"spent_cents"`
-	RemainingCents  int64     `json:"remaining_cents"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID                     uuid.UUID         `json:"id"`
+	Title                  string            `json:"title"`
+	BudgetCents            int64             `json:"budget_cents"`
+	Currency               string            `json:"currency"`
+	PeriodStart            string            `json:"period_start"`
+	PeriodEnd              string            `json:"period_end"`
+	BackgroundColor        string            `json:"background_color"`
+	IsAIGenerated          bool              `json:"is_ai_generated"`
+	Recurrence             models.Recurrence `json:"recurrence"`
+	RecurrenceIntervalDays *int              `json:"recurrence_interval_days,omitempty"`
+	CarryOver              bool              `json:"carry_over"`
+	NextPlanID             *uuid.UUID        `json:"next_plan_id,omitempty"`
+	Role                   models.PlanRole   `json:"role,omitempty"`
+	SpentCents             int64             `json:"spent_cents"`
+	RemainingCents         int64             `json:"remaining_cents"`
+	CreatedAt              time.Time         `json:"created_at"`
+	UpdatedAt              time.Time         `json:"updated_at"`
 }
 
 type CategoryResponse struct {
@@ -66,12 +77,14 @@ type CategoryResponse struct {
 }
 
 type ItemResponse struct {
-	ID            uuid.UUID            `json:"id"`
-	Title         string               `json:"title"`
-	AmountCents   int64                `json:"amount_cents"`
-	PriorityColor models.PriorityColor `json:"priority_color"`
-	IsCompleted   bool                 `json:"is_completed"`
-	SortOrder     int                  `json:"sort_order"`
+	ID             uuid.UUID            `json:"id"`
+	Title          string               `json:"title"`
+	AmountCents    int64                `json:"amount_cents"`
+	PriorityColor  models.PriorityColor `json:"priority_color"`
+	IsCompleted    bool                 `json:"is_completed"`
+	SortOrder      int                  `json:"sort_order"`
+	SpentCents     int64                `json:"spent_cents"`
+	RemainingCents int64                `json:"remaining_cents"`
 }
 
 type NoteResponse struct {
@@ -103,7 +116,9 @@ func (h *PlanHandler) List(c echo.Context) error {
 
 	response := make([]PlanResponse, 0, len(plans))
 	for _, plan := range plans {
-		response = append(response, toPlanResponse(plan.Plan, plan.SpentCents))
+		item := toPlanResponse(plan.Plan, plan.SpentCents)
+		item.Role = plan.Role
+		response = append(response, item)
 	}
 
 	return c.JSON(http.StatusOK, map[string][]PlanResponse{"plans": response})
@@ -123,7 +138,9 @@ func (h *PlanHandler) Archive(c echo.Context) error {
 
 	response := make([]PlanResponse, 0, len(plans))
 	for _, plan := range plans {
-		response = append(response, toPlanResponse(plan.Plan, plan.SpentCents))
+		item := toPlanResponse(plan.Plan, plan.SpentCents)
+		item.Role = plan.Role
+		response = append(response, item)
 	}
 
 	return c.JSON(http.StatusOK, map[string][]PlanResponse{"plans": response})
@@ -149,9 +166,23 @@ func (h *PlanHandler) Create(c echo.Context) error {
 		return badRequest(c, "title is required")
 	}
 
-	periodStart, periodEnd, err := parsePeriod(req.PeriodStart, req.PeriodEnd)
-	if err != nil {
-		return badRequest(c, err.Error())
+	var periodStart, periodEnd time.Time
+	var err error
+	if strings.TrimSpace(req.PeriodStart) == "" && strings.TrimSpace(req.PeriodEnd) == "" {
+		var user models.User
+		user, err = h.Users.GetByID(c.Request().Context(), userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return notFound(c, "user not found")
+			}
+			return serverError(c)
+		}
+		periodStart, periodEnd = currentPeriod(user, req.Period, time.Now())
+	} else {
+		periodStart, periodEnd, err = parsePeriod(req.PeriodStart, req.PeriodEnd)
+		if err != nil {
+			return badRequest(c, err.Error())
+		}
 	}
 
 	backgroundColor := defaultBackgroundColor
@@ -169,7 +200,12 @@ func (h *PlanHandler) Create(c echo.Context) error {
 		isAIGenerated = *req.IsAIGenerated
 	}
 
-	plan, err := h.Plans.Create(c.Request().Context(), userID, title, req.BudgetCents, periodStart, periodEnd, backgroundColor, isAIGenerated)
+	planCurrency, err := normalizeCurrency(req.Currency)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	plan, err := h.Plans.Create(c.Request().Context(), userID, title, req.BudgetCents, planCurrency, periodStart, periodEnd, backgroundColor, isAIGenerated)
 	if err != nil {
 		return serverError(c)
 	}
@@ -247,8 +283,16 @@ func (h *PlanHandler) Update(c echo.Context) error {
 		backgroundColor = &value
 	}
 
-	plan, err := h.Plans.Update(c.Request().Context(), userID, planID, title, req.BudgetCents, periodStart, periodEnd, backgroundColor, req.IsAIGenerated)
+	planCurrency, err := normalizeCurrency(req.Currency)
 	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	plan, err := h.Plans.Update(c.Request().Context(), userID, planID, title, req.BudgetCents, planCurrency, periodStart, periodEnd, backgroundColor, req.IsAIGenerated)
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "plan not found")
 		}
@@ -261,7 +305,7 @@ func (h *PlanHandler) Update(c echo.Context) error {
 	}
 
 	response := toPlanResponse(plan, spent)
-	publishBudgetUpdate(h.Notifier, userID, plan.ID, spent, response.RemainingCents)
+	publishPlanBudgetUpdate(c.Request().Context(), h.Notifier, h.Plans, userID, plan.ID, spent, response.RemainingCents)
 	return c.JSON(http.StatusOK, response)
 }
 
@@ -278,6 +322,9 @@ func (h *PlanHandler) Delete(c echo.Context) error {
 	}
 
 	if err := h.Plans.Delete(c.Request().Context(), userID, planID); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "plan not found")
 		}
@@ -313,6 +360,9 @@ func (h *PlanHandler) ReorderCategories(c echo.Context) error {
 	}
 
 	if err := h.Plans.ReorderCategories(c.Request().Context(), userID, planID, categoryIDs); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			return notFound(c, "plan or categories not found")
 		}
@@ -385,6 +435,20 @@ func validateHexColor(value string) (string, error) {
 	return trimmed, nil
 }
 
+// normalizeCurrency проверяет необязательный код валюты по ISO 4217.
+func normalizeCurrency(value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	code, err := currency.Normalize(*value)
+	if err != nil {
+		return nil, errors.New("currency must be an ISO 4217 code")
+	}
+
+	return &code, nil
+}
+
 func isHexColor(value string) bool {
 	if len(value) != 7 || value[0] != '#' {
 		return false
@@ -423,16 +487,21 @@ func parseUUIDs(values []string) ([]uuid.UUID, error) {
 
 func toPlanResponse(plan models.BudgetPlan, spentCents int64) PlanResponse {
 	return PlanResponse{
-		ID:              plan.ID,
-		Title:           plan.Title,
-		BudgetCents:     plan.BudgetCents,
-		PeriodStart:     plan.PeriodStart.Format(dateLayout),
-		PeriodEnd:       plan.PeriodEnd.Format(dateLayout),
-		BackgroundColor: plan.BackgroundColor,
-		IsAIGenerated:   plan.IsAIGenerated,
-		SpentCents:      spentCents,
-		RemainingCents:  plan.BudgetCents - spentCents,
-		CreatedAt:       plan.CreatedAt,
-		UpdatedAt:       plan.UpdatedAt,
+		ID:                     plan.ID,
+		Title:                  plan.Title,
+		BudgetCents:            plan.BudgetCents,
+		Currency:               plan.Currency,
+		PeriodStart:            plan.PeriodStart.Format(dateLayout),
+		PeriodEnd:              plan.PeriodEnd.Format(dateLayout),
+		BackgroundColor:        plan.BackgroundColor,
+		IsAIGenerated:          plan.IsAIGenerated,
+		Recurrence:             plan.Recurrence,
+		RecurrenceIntervalDays: plan.RecurrenceDays,
+		CarryOver:              plan.CarryOver,
+		NextPlanID:             plan.NextPlanID,
+		SpentCents:             spentCents,
+		RemainingCents:         plan.BudgetCents - spentCents,
+		CreatedAt:              plan.CreatedAt,
+		UpdatedAt:              plan.UpdatedAt,
 	}
 }
@@ -1,6 +1,13 @@
 package handlers
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
 
 // TestParsePeriodValid проверяет корректный разбор периода.
 func TestParsePeriodValid(t *testing.T) {
@@ -42,3 +49,66 @@ func TestValidateHexColor(t *testing.T) {
 		t.Fatal("expected error for invalid hex")
 	}
 }
+
+// TestPlanCurrencyInResponses проверяет, что создание, получение и список
+// планов возвращают валюту плана, а без явной валюты — базовую валюту
+// пользователя.
+func TestPlanCurrencyInResponses(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	users := repository.NewUserRepository(db)
+	handler := NewPlanHandler(repository.NewPlanRepository(db), users, nil)
+
+	user, err := users.Create(ctx, "currency@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if _, err := db.Exec(ctx, `UPDATE users SET base_currency = 'EUR' WHERE id = $1`, user.ID); err != nil {
+		t.Fatalf("set base currency: %v", err)
+	}
+
+	create := func(body string) PlanResponse {
+		t.Helper()
+
+		rec := serveJSON(t, handler.Create, http.MethodPost, body, asUser(user.ID))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var response PlanResponse
+		decodeJSON(t, rec.Body.Bytes(), &response)
+		return response
+	}
+
+	usd := create(`{"title":"Поездка","budget_cents":10000,"currency":"usd","period_start":"2024-01-01","period_end":"2024-01-31"}`)
+	if usd.Currency != "USD" {
+		t.Fatalf("create: expected USD, got %q", usd.Currency)
+	}
+	base := create(`{"title":"Дом","budget_cents":10000,"period_start":"2024-01-01","period_end":"2024-01-31"}`)
+	if base.Currency != "EUR" {
+		t.Fatalf("create without currency: expected base currency EUR, got %q", base.Currency)
+	}
+
+	rec := serveJSON(t, handler.Get, http.MethodGet, ``, asUser(user.ID), withParam("id", usd.ID.String()))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var detail PlanDetailResponse
+	decodeJSON(t, rec.Body.Bytes(), &detail)
+	if detail.Plan.Currency != "USD" {
+		t.Fatalf("get: expected USD, got %q", detail.Plan.Currency)
+	}
+
+	rec = serveJSON(t, handler.List, http.MethodGet, ``, asUser(user.ID))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list map[string][]PlanResponse
+	decodeJSON(t, rec.Body.Bytes(), &list)
+	currencies := make(map[string]string)
+	for _, plan := range list["plans"] {
+		currencies[plan.ID.String()] = plan.Currency
+	}
+	if currencies[usd.ID.String()] != "USD" || currencies[base.ID.String()] != "EUR" {
+		t.Fatalf("list: unexpected currencies %v", currencies)
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/currency"
+	"example.com/ai-budget-planner/backend/internal/mailer"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const (
+	emailChangeTTL = 24 * time.Hour
+
+	periodWeek = "week"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// UpdateProfileRequest — частичное изменение профиля: незаданные поля не меняются.
+// Смена email и пароля требует текущего пароля, если он у пользователя есть.
+type UpdateProfileRequest struct {
+	Name            *string `json:"name" validate:"omitempty,max=100"`
+	Email           *string `json:"email" validate:"omitempty,email,max=255"`
+	CurrentPassword string  `json:"current_password"`
+	NewPassword     *string `json:"new_password" validate:"omitempty,min=8"`
+	BaseCurrency    *string `json:"base_currency"`
+	Locale          *string `json:"locale"`
+	TimeZone        *string `json:"time_zone"`
+	WeekStart       *string `json:"week_start" validate:"omitempty,oneof=monday sunday"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// UpdateProfile меняет имя, настройки, email и пароль текущего пользователя.
+// Новый email начинает действовать после перехода по ссылке из письма на него;
+// смена пароля завершает все сессии, кроме текущей.
+func (h *AuthHandler) UpdateProfile(c echo.Context) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return currentUserError(c, err)
+	}
+
+	var req UpdateProfileRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	update, err := buildProfileUpdate(req)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	var newEmail *string
+	emailChanged := false
+	if req.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*req.Email))
+		switch {
+		case email != user.Email:
+			newEmail = &email
+			emailChanged = true
+		case user.PendingEmail != nil:
+			// Текущий адрес отменяет начатую смену email.
+			emailChanged = true
+		}
+	}
+
+	if (emailChanged || req.NewPassword != nil) && user.PasswordHash != "" {
+		if err = auth.ComparePassword(user.PasswordHash, strings.TrimSpace(req.CurrentPassword)); err != nil {
+			return unauthorized(c)
+		}
+	}
+
+	if emailChanged {
+		update.ChangeEmail = true
+		update.PendingEmail = newEmail
+	}
+
+	if req.NewPassword != nil {
+		passwordHash, err := auth.HashPassword(strings.TrimSpace(*req.NewPassword))
+		if err != nil {
+			return serverError(c)
+		}
+		update.PasswordHash = &passwordHash
+		update.KeepSessionID, _ = auth.SessionIDFromContext(c)
+	}
+
+	user, err = h.Users.UpdateProfile(c.Request().Context(), user.ID, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "user not found")
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return conflict(c, "email already in use")
+		}
+		return serverError(c)
+	}
+
+	// Письма уходят после фиксации изменений: ссылка не должна вести к
+	// смене, которая не сохранилась.
+	if newEmail != nil {
+		h.sendEmailChange(c, user, *newEmail)
+	}
+
+	return c.JSON(http.StatusOK, UserResponse{User: toAuthUser(user)})
+}
+
+// ConfirmEmailChange применяет новый email по токену из письма.
+func (h *AuthHandler) ConfirmEmailChange(c echo.Context) error {
+	var req ConfirmEmailChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	user, err := h.Users.ConfirmEmailChange(c.Request().Context(), auth.HashToken(strings.TrimSpace(req.Token)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return badRequest(c, "invalid or expired token")
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return conflict(c, "email already in use")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, UserResponse{User: toAuthUser(user)})
+}
+
+// sendEmailChange отправляет ссылку подтверждения на новый адрес и
+// предупреждение на текущий.
+func (h *AuthHandler) sendEmailChange(c echo.Context, user models.User, newEmail string) {
+	ctx := c.Request().Context()
+
+	link, err := h.issueUserToken(ctx, user, models.UserTokenEmailChange, emailChangeTTL, "/confirm-email")
+	if err != nil {
+		slog.Warn("email change token issue failed",
+			slog.String("user_id", user.ID.String()),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	h.sendMail(ctx, mailer.Message{
+		To:      newEmail,
+		Subject: "Подтверждение нового email",
+		Body: fmt.Sprintf("Чтобы сменить адрес учетной записи на этот, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует 24 часа.\n", link),
+	})
+
+	h.sendMail(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Смена email",
+		Body: fmt.Sprintf("Запрошена смена адреса учетной записи на %s. Адрес сменится после подтверждения.\n\n"+
+			"Если это были не вы, смените пароль и завершите все сессии.\n", newEmail),
+	})
+}
+
+// buildProfileUpdate проверяет и нормализует имя и настройки из запроса.
+func buildProfileUpdate(req UpdateProfileRequest) (repository.ProfileUpdate, error) {
+	var update repository.ProfileUpdate
+
+	if req.Name != nil {
+		if name := normalizeName(req.Name); name != nil {
+			update.Name = name
+		} else {
+			update.ClearName = true
+		}
+	}
+
+	if req.BaseCurrency != nil {
+		code, err := currency.Normalize(*req.BaseCurrency)
+		if err != nil {
+			return update, errors.New("base_currency must be an ISO 4217 code")
+		}
+		update.BaseCurrency = &code
+	}
+
+	if req.Locale != nil {
+		locale, err := normalizeLocale(*req.Locale)
+		if err != nil {
+			return update, err
+		}
+		update.Locale = &locale
+	}
+
+	if req.TimeZone != nil {
+		timeZone := strings.TrimSpace(*req.TimeZone)
+		if _, err := loadTimeZone(timeZone); err != nil {
+			return update, err
+		}
+		update.TimeZone = &timeZone
+	}
+
+	if req.WeekStart != nil {
+		weekStart := models.WeekStart(*req.WeekStart)
+		update.WeekStart = &weekStart
+	}
+
+	return update, nil
+}
+
+// normalizeLocale приводит тег языка вида ru или en-US к каноничному регистру.
+func normalizeLocale(value string) (string, error) {
+	language, region, _ := strings.Cut(strings.TrimSpace(value), "-")
+	locale := strings.ToLower(language)
+	if region != "" {
+		locale += "-" + strings.ToUpper(region)
+	}
+
+	if !localePattern.MatchString(locale) {
+		return "", errors.New("locale must be a language tag like ru or en-US")
+	}
+
+	return locale, nil
+}
+
+// loadTimeZone загружает часовой пояс IANA, например Europe/Moscow.
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("time_zone must be an IANA time zone")
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.New("time_zone must be an IANA time zone")
+	}
+
+	return location, nil
+}
+
+// currentPeriod возвращает текущий месяц или неделю (period) в часовом поясе
+// пользователя; неделя начинается с дня из его настроек.
+func currentPeriod(user models.User, period string, now time.Time) (time.Time, time.Time) {
+	location, err := loadTimeZone(user.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	year, month, day := now.In(location).Date()
+
+	if period == periodWeek {
+		firstDay := time.Monday
+		if user.WeekStart == models.WeekStartSunday {
+			firstDay = time.Sunday
+		}
+		today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		offset := (int(today.Weekday()) - int(firstDay) + 7) % 7
+		start := today.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 6)
+	}
+
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, -1)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// TestCurrentPeriod проверяет период по умолчанию с учетом часового пояса и начала недели.
+func TestCurrentPeriod(t *testing.T) {
+	// В UTC еще 31 мая, в Москве уже 1 июня (суббота).
+	now := time.Date(2024, 5, 31, 22, 30, 0, 0, time.UTC)
+	user := models.User{TimeZone: "Europe/Moscow", WeekStart: models.WeekStartMonday}
+
+	cases := []struct {
+		name      string
+		user      models.User
+		period    string
+		wantStart string
+		wantEnd   string
+	}{
+		{name: "month in user zone", user: user, period: "", wantStart: "2024-06-01", wantEnd: "2024-06-30"},
+		{name: "month in utc", user: models.User{TimeZone: "UTC"}, period: "month", wantStart: "2024-05-01", wantEnd: "2024-05-31"},
+		{name: "week from monday", user: user, period: "week", wantStart: "2024-05-27", wantEnd: "2024-06-02"},
+		{name: "week from sunday", user: models.User{TimeZone: "Europe/Moscow", WeekStart: models.WeekStartSunday}, period: "week", wantStart: "2024-05-26", wantEnd: "2024-06-01"},
+	}
+
+	for _, tc := range cases {
+		start, end := currentPeriod(tc.user, tc.period, now)
+		if start.Format(dateLayout) != tc.wantStart || end.Format(dateLayout) != tc.wantEnd {
+			t.Fatalf("%s: got %s - %s, want %s - %s", tc.name, start.Format(dateLayout), end.Format(dateLayout), tc.wantStart, tc.wantEnd)
+		}
+	}
+}
+
+// TestNormalizeLocale проверяет приведение и отклонение тегов языка.
+func TestNormalizeLocale(t *testing.T) {
+	if locale, err := normalizeLocale("EN-us"); err != nil || locale != "en-US" {
+		t.Fatalf("expected en-US, got %q (%v)", locale, err)
+	}
+
+	for _, value := range []string{"", "english", "en_US", "en-USA"} {
+		if _, err := normalizeLocale(value); err == nil {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const maxUserAgentLength = 512
+
+type SessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent *string   `json:"user_agent,omitempty"`
+	IPAddress *string   `json:"ip_address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Current   bool      `json:"current"`
+}
+
+// ListSessions возвращает действующие сессии (refresh-токены) текущего пользователя.
+func (h *AuthHandler) ListSessions(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	tokens, err := h.Tokens.ListActiveByUser(c.Request().Context(), userID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	currentID, _ := auth.SessionIDFromContext(c)
+	response := make([]SessionResponse, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, SessionResponse{
+			ID:        token.ID,
+			UserAgent: token.UserAgent,
+			IPAddress: token.IPAddress,
+			CreatedAt: token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+			Current:   token.ID == currentID,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string][]SessionResponse{"sessions": response})
+}
+
+// RevokeSession завершает одну сессию текущего пользователя.
+func (h *AuthHandler) RevokeSession(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid session id")
+	}
+
+	if err := h.Tokens.RevokeForUser(c.Request().Context(), userID, sessionID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "session not found")
+		}
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RevokeAllSessions завершает все сессии текущего пользователя ("выйти везде").
+func (h *AuthHandler) RevokeAllSessions(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	if _, err := h.Tokens.RevokeAllForUser(c.Request().Context(), userID); err != nil {
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// sessionClient возвращает User-Agent и IP клиента для сохранения вместе с refresh-токеном.
+func sessionClient(c echo.Context) (*string, *string) {
+	var userAgent, ip *string
+
+	if value := strings.TrimSpace(c.Request().UserAgent()); value != "" {
+		if len(value) > maxUserAgentLength {
+			value = strings.ToValidUTF8(value[:maxUserAgentLength], "")
+		}
+		userAgent = &value
+	}
+
+	if value := c.RealIP(); value != "" {
+		ip = &value
+	}
+
+	return userAgent, ip
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+)
+
+// withClient задает User-Agent и адрес соединения запроса.
+func withClient(userAgent, remoteAddr string) func(echo.Context) {
+	return func(c echo.Context) {
+		c.Request().Header.Set("User-Agent", userAgent)
+		c.Request().RemoteAddr = remoteAddr
+	}
+}
+
+// asSession кладет в контекст пользователя и сессию его access-токена.
+func asSession(userID, sessionID uuid.UUID) func(echo.Context) {
+	return func(c echo.Context) {
+		c.Set(auth.ContextUserIDKey, userID)
+		c.Set(auth.ContextSessionIDKey, sessionID)
+	}
+}
+
+func loginTestUser(t *testing.T, handler *AuthHandler, email, password string, prepare ...func(echo.Context)) AuthResponse {
+	t.Helper()
+
+	rec := serveJSON(t, handler.Login, http.MethodPost, `{"email":"`+email+`","password":"`+password+`"}`, prepare...)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response AuthResponse
+	decodeJSON(t, rec.Body.Bytes(), &response)
+	return response
+}
+
+// TestListSessionsShowsClient проверяет, что список сессий возвращает
+// User-Agent и IP, запомненные при выдаче токена, и отмечает текущую сессию.
+func TestListSessionsShowsClient(t *testing.T) {
+	handler, _, _ := newTestAuthHandler(t)
+
+	registered := registerTestUser(t, handler, "sessions@example.com", "Pass1234")
+	laptop := loginTestUser(t, handler, "sessions@example.com", "Pass1234", withClient("Firefox/128.0", "203.0.113.7:51234"))
+	laptopID := refreshTokenID(t, handler, laptop.RefreshToken)
+
+	rec := serveJSON(t, handler.ListSessions, http.MethodGet, ``, asSession(registered.User.ID, laptopID))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list sessions: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response map[string][]SessionResponse
+	decodeJSON(t, rec.Body.Bytes(), &response)
+
+	sessions := make(map[uuid.UUID]SessionResponse)
+	for _, session := range response["sessions"] {
+		sessions[session.ID] = session
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected two sessions, got %+v", response["sessions"])
+	}
+
+	session, ok := sessions[laptopID]
+	if !ok || !session.Current {
+		t.Fatalf("expected the login session to be listed as current, got %+v", response["sessions"])
+	}
+	if session.UserAgent == nil || *session.UserAgent != "Firefox/128.0" {
+		t.Fatalf("expected the login user agent, got %v", session.UserAgent)
+	}
+	if session.IPAddress == nil || *session.IPAddress != "203.0.113.7" {
+		t.Fatalf("expected the login address, got %v", session.IPAddress)
+	}
+
+	first := sessions[refreshTokenID(t, handler, registered.RefreshToken)]
+	if first.Current || first.UserAgent != nil {
+		t.Fatalf("expected the register session without user agent, got %+v", first)
+	}
+}
+
+// TestRevokeSessionOfAnotherUser проверяет, что чужую сессию нельзя
+// завершить: ответ 404, а сессия продолжает работать.
+func TestRevokeSessionOfAnotherUser(t *testing.T) {
+	handler, _, _ := newTestAuthHandler(t)
+	ctx := context.Background()
+
+	alice := registerTestUser(t, handler, "alice@example.com", "Pass1234")
+	bob := registerTestUser(t, handler, "bob@example.com", "Pass1234")
+	bobSession := refreshTokenID(t, handler, bob.RefreshToken)
+
+	rec := serveJSON(t, handler.RevokeSession, http.MethodDelete, ``, asUser(alice.User.ID), withParam("id", bobSession.String()))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("revoke a foreign session: expected 404, got %d", rec.Code)
+	}
+
+	stored, err := handler.Tokens.GetByID(ctx, bobSession)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if stored.RevokedAt != nil {
+		t.Fatal("expected the foreign session to stay active")
+	}
+	if rec := serveJSON(t, handler.Refresh, http.MethodPost, refreshRequest(bob.RefreshToken)); rec.Code != http.StatusOK {
+		t.Fatalf("refresh the foreign session: expected 200, got %d", rec.Code)
+	}
+
+	aliceSession := refreshTokenID(t, handler, alice.RefreshToken)
+	rec = serveJSON(t, handler.RevokeSession, http.MethodDelete, ``, asUser(alice.User.ID), withParam("id", aliceSession.String()))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke own session: expected 204, got %d", rec.Code)
+	}
+	if rec := serveJSON(t, handler.Refresh, http.MethodPost, refreshRequest(alice.RefreshToken)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh a revoked session: expected 401, got %d", rec.Code)
+	}
+}
+
+// TestRevokeAllSessions проверяет, что после "выйти везде" ни один
+// refresh-токен пользователя не обновляется, а сессии другого пользователя
+// не затронуты.
+func TestRevokeAllSessions(t *testing.T) {
+	handler, _, _ := newTestAuthHandler(t)
+
+	first := registerTestUser(t, handler, "everywhere@example.com", "Pass1234")
+	second := loginTestUser(t, handler, "everywhere@example.com", "Pass1234")
+	third := loginTestUser(t, handler, "everywhere@example.com", "Pass1234")
+	other := registerTestUser(t, handler, "other@example.com", "Pass1234")
+
+	rec := serveJSON(t, handler.RevokeAllSessions, http.MethodDelete, ``, asUser(first.User.ID))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke all: expected 204, got %d", rec.Code)
+	}
+
+	for _, session := range []AuthResponse{first, second, third} {
+		rec := serveJSON(t, handler.Refresh, http.MethodPost, refreshRequest(session.RefreshToken))
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("refresh after revoke all: expected 401, got %d", rec.Code)
+		}
+	}
+	if rec := serveJSON(t, handler.Refresh, http.MethodPost, refreshRequest(other.RefreshToken)); rec.Code != http.StatusOK {
+		t.Fatalf("refresh another user's session: expected 200, got %d", rec.Code)
+	}
+
+	rec = serveJSON(t, handler.ListSessions, http.MethodGet, ``, asUser(first.User.ID))
+	var response map[string][]SessionResponse
+	decodeJSON(t, rec.Body.Bytes(), &response)
+	if len(response["sessions"]) != 0 {
+		t.Fatalf("expected no active sessions, got %+v", response["sessions"])
+	}
+}
@@ -22,12 +22,14 @@ func NewStatsHandler(stats *repository.StatsRepository) *StatsHandler {
 }
 
 type OverviewResponse struct {
-	TotalPlans       int   `json:"total_plans"`
-	ActivePlans      int   `json:"active_plans"`
-	ArchivedPlans    int   `json:"archived_plans"`
-	TotalBudgetCents int64 `json:"total_budget_cents"`
-	TotalSpentCents  int64 `json:"total_spent_cents"`
-	RemainingCents   int64 `json:"remaining_cents"`
+	TotalPlans       int    `json:"total_plans"`
+	ActivePlans      int    `json:"active_plans"`
+	ArchivedPlans    int    `json:"archived_plans"`
+	TotalBudgetCents int64  `json:"total_budget_cents"`
+	TotalSpentCents  int64  `json:"total_spent_cents"`
+	RemainingCents   int64  `json:"remaining_cents"`
+	Currency         string `json:"currency"`
+	UnconvertedPlans int    `json:"unconverted_plans"`
 }
 
 type CategorySpendingResponse struct {
@@ -39,17 +41,20 @@ type CategorySpendingCategory struct {
 	CategoryID   uuid.UUID `json:"category_id"`
 	Title        string    `json:"title"`
 	CategoryType string    `json:"category_type"`
+	PlannedCents int64     `json:"planned_cents"`
 	SpentCents   int64     `json:"spent_cents"`
 }
 
 type MonthlyComparisonResponse struct {
-	Months []MonthlyComparisonItem `json:"months"`
+	Currency string                  `json:"currency"`
+	Months   []MonthlyComparisonItem `json:"months"`
 }
 
 type MonthlyComparisonItem struct {
-	Month       string `json:"month"`
-	BudgetCents int64  `json:"budget_cents"`
-	SpentCents  int64  `json:"spent_cents"`
+	Month            string `json:"month"`
+	BudgetCents      int64  `json:"budget_cents"`
+	SpentCents       int64  `json:"spent_cents"`
+	UnconvertedPlans int    `json:"unconverted_plans"`
 }
 
 // Overview возвращает сводную статистику по планам.
@@ -64,6 +69,11 @@ func (h *StatsHandler) Overview(c echo.Context) error {
 		return serverError(c)
 	}
 
+	baseCurrency, err := h.Stats.BaseCurrency(c.Request().Context(), userID)
+	if err != nil {
+		return serverError(c)
+	}
+
 	return c.JSON(http.StatusOK, OverviewResponse{
 		TotalPlans:       stats.TotalPlans,
 		ActivePlans:      stats.ActivePlans,
@@ -71,6 +81,8 @@ func (h *StatsHandler) Overview(c echo.Context) error {
 		TotalBudgetCents: stats.TotalBudgetCents,
 		TotalSpentCents:  stats.TotalSpentCents,
 		RemainingCents:   stats.TotalBudgetCents - stats.TotalSpentCents,
+		Currency:         baseCurrency,
+		UnconvertedPlans: stats.UnconvertedPlans,
 	})
 }
 
@@ -105,6 +117,7 @@ func (h *StatsHandler) SpendingByCategory(c echo.Context) error {
 			CategoryID:   item.CategoryID,
 			Title:        item.Title,
 			CategoryType: string(item.CategoryType),
+			PlannedCents: item.PlannedCents,
 			SpentCents:   item.SpentCents,
 		})
 	}
@@ -142,14 +155,20 @@ func (h *StatsHandler) MonthlyComparison(c echo.Context) error {
 		return serverError(c)
 	}
 
+	baseCurrency, err := h.Stats.BaseCurrency(c.Request().Context(), userID)
+	if err != nil {
+		return serverError(c)
+	}
+
 	response := make([]MonthlyComparisonItem, 0, len(items))
 	for _, item := range items {
 		response = append(response, MonthlyComparisonItem{
-			Month:       item.Month.Format("2006-01"),
-			BudgetCents: item.BudgetCents,
-			SpentCents:  item.SpentCents,
+			Month:            item.Month.Format("2006-01"),
+			BudgetCents:      item.BudgetCents,
+			SpentCents:       item.SpentCents,
+			UnconvertedPlans: item.UnconvertedPlans,
 		})
 	}
 
-	return c.JSON(http.StatusOK, MonthlyComparisonResponse{Months: response})
+	return c.JSON(http.StatusOK, MonthlyComparisonResponse{Currency: baseCurrency, Months: response})
 }
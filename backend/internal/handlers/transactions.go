@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/notifications"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+type TransactionHandler struct {
+	Transactions *repository.TransactionRepository
+	Items        *repository.ItemRepository
+	Plans        *repository.PlanRepository
+	Notifier     *notifications.Hub
+}
+
+// NewTransactionHandler создает обработчик фактических транзакций.
+func NewTransactionHandler(transactions *repository.TransactionRepository, items *repository.ItemRepository, plans *repository.PlanRepository, notifier *notifications.Hub) *TransactionHandler {
+	return &TransactionHandler{Transactions: transactions, Items: items, Plans: plans, Notifier: notifier}
+}
+
+type TransactionRequest struct {
+	AmountCents int64   `json:"amount_cents" validate:"gt=0"`
+	OccurredOn  string  `json:"occurred_on"`
+	Merchant    *string `json:"merchant" validate:"omitempty,max=200"`
+	Memo        *string `json:"memo" validate:"omitempty,max=1000"`
+}
+
+type TransactionResponse struct {
+	ID          uuid.UUID `json:"id"`
+	ItemID      uuid.UUID `json:"item_id"`
+	AmountCents int64     `json:"amount_cents"`
+	OccurredOn  string    `json:"occurred_on"`
+	Merchant    *string   `json:"merchant,omitempty"`
+	Memo        *string   `json:"memo,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ItemLedgerResponse struct {
+	ItemID         uuid.UUID             `json:"item_id"`
+	PlannedCents   int64                 `json:"planned_cents"`
+	SpentCents     int64                 `json:"spent_cents"`
+	RemainingCents int64                 `json:"remaining_cents"`
+	Transactions   []TransactionResponse `json:"transactions"`
+}
+
+// List возвращает транзакции расхода и сравнение с плановой суммой.
+func (h *TransactionHandler) List(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		return badRequest(c, "invalid item id")
+	}
+
+	item, err := h.Items.GetByID(c.Request().Context(), userID, itemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "item not found")
+		}
+		return serverError(c)
+	}
+
+	transactions, err := h.Transactions.ListByItem(c.Request().Context(), userID, itemID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	spent, err := h.Items.GetSpentCents(c.Request().Context(), itemID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	response := make([]TransactionResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		response = append(response, toTransactionResponse(transaction))
+	}
+
+	return c.JSON(http.StatusOK, ItemLedgerResponse{
+		ItemID:         item.ID,
+		PlannedCents:   item.AmountCents,
+		SpentCents:     spent,
+		RemainingCents: item.AmountCents - spent,
+		Transactions:   response,
+	})
+}
+
+// Create записывает фактическую оплату по расходу.
+func (h *TransactionHandler) Create(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		return badRequest(c, "invalid item id")
+	}
+
+	planID, err := h.Items.GetPlanIDByItemID(c.Request().Context(), userID, itemID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "item not found")
+		}
+		return serverError(c)
+	}
+
+	var req TransactionRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	occurredOn, err := parseOccurredOn(req.OccurredOn)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	transaction, err := h.Transactions.Create(c.Request().Context(), userID, itemID, req.AmountCents, occurredOn, normalizeOptional(req.Merchant), normalizeOptional(req.Memo))
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "item not found")
+		}
+		return serverError(c)
+	}
+
+	notifyPlanBudget(c.Request().Context(), h.Notifier, h.Plans, userID, planID)
+	return c.JSON(http.StatusCreated, toTransactionResponse(transaction))
+}
+
+// Update изменяет транзакцию.
+func (h *TransactionHandler) Update(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return badRequest(c, "invalid transaction id")
+	}
+
+	planID, _, err := h.Transactions.GetPlanIDByTransactionID(c.Request().Context(), userID, transactionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "transaction not found")
+		}
+		return serverError(c)
+	}
+
+	var req TransactionRequest
+	if err = c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err = c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	occurredOn, err := parseOccurredOn(req.OccurredOn)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	transaction, err := h.Transactions.Update(c.Request().Context(), userID, transactionID, req.AmountCents, occurredOn, normalizeOptional(req.Merchant), normalizeOptional(req.Memo))
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "transaction not found")
+		}
+		return serverError(c)
+	}
+
+	notifyPlanBudget(c.Request().Context(), h.Notifier, h.Plans, userID, planID)
+	return c.JSON(http.StatusOK, toTransactionResponse(transaction))
+}
+
+// Delete удаляет транзакцию.
+func (h *TransactionHandler) Delete(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		return badRequest(c, "invalid transaction id")
+	}
+
+	planID, _, err := h.Transactions.GetPlanIDByTransactionID(c.Request().Context(), userID, transactionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "transaction not found")
+		}
+		return serverError(c)
+	}
+
+	if err := h.Transactions.Delete(c.Request().Context(), userID, transactionID); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			return forbidden(c)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "transaction not found")
+		}
+		return serverError(c)
+	}
+
+	notifyPlanBudget(c.Request().Context(), h.Notifier, h.Plans, userID, planID)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func parseOccurredOn(value string) (time.Time, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		now := time.Now().UTC()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	parsed, err := time.Parse(dateLayout, trimmed)
+	if err != nil {
+		return time.Time{}, errors.New("invalid occurred_on format")
+	}
+
+	return parsed, nil
+}
+
+func normalizeOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+
+	return &trimmed
+}
+
+func toTransactionResponse(transaction models.Transaction) TransactionResponse {
+	return TransactionResponse{
+		ID:          transaction.ID,
+		ItemID:      transaction.ItemID,
+		AmountCents: transaction.AmountCents,
+		OccurredOn:  transaction.OccurredOn.Format(dateLayout),
+		Merchant:    transaction.Merchant,
+		Memo:        transaction.Memo,
+		CreatedAt:   transaction.CreatedAt,
+		UpdatedAt:   transaction.UpdatedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// TestParseOccurredOn проверяет дату транзакции: по умолчанию сегодня в UTC,
+// иначе строго YYYY-MM-DD.
+func TestParseOccurredOn(t *testing.T) {
+	parsed, err := parseOccurredOn(" 2024-03-15 ")
+	if err != nil || parsed.Format(dateLayout) != "2024-03-15" {
+		t.Fatalf("expected 2024-03-15, got %v (%v)", parsed, err)
+	}
+
+	today, err := parseOccurredOn("")
+	if err != nil || today.Format(dateLayout) != time.Now().UTC().Format(dateLayout) {
+		t.Fatalf("expected today, got %v (%v)", today, err)
+	}
+
+	for _, value := range []string{"15.03.2024", "2024-13-01", "2024-03-15T10:00:00Z"} {
+		if _, err := parseOccurredOn(value); err == nil {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
+
+// TestTransactionRequestValidation проверяет ответы на неверные идентификаторы
+// и запросы без пользователя: до хранилища такие запросы не доходят.
+func TestTransactionRequestValidation(t *testing.T) {
+	handler := NewTransactionHandler(nil, nil, nil, nil)
+	user := asUser(uuid.New())
+
+	cases := []struct {
+		name    string
+		handler echo.HandlerFunc
+		method  string
+		body    string
+		prepare []func(echo.Context)
+		want    int
+	}{
+		{"list without user", handler.List, http.MethodGet, ``, []func(echo.Context){withParam("itemId", uuid.NewString())}, http.StatusUnauthorized},
+		{"list invalid item id", handler.List, http.MethodGet, ``, []func(echo.Context){user, withParam("itemId", "bad")}, http.StatusBadRequest},
+		{"create invalid item id", handler.Create, http.MethodPost, `{"amount_cents":100}`, []func(echo.Context){user, withParam("itemId", "bad")}, http.StatusBadRequest},
+		{"update invalid id", handler.Update, http.MethodPut, `{"amount_cents":100}`, []func(echo.Context){user, withParam("transactionId", "bad")}, http.StatusBadRequest},
+		{"delete invalid id", handler.Delete, http.MethodDelete, ``, []func(echo.Context){user, withParam("transactionId", "bad")}, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		rec := serveJSON(t, tc.handler, tc.method, tc.body, tc.prepare...)
+		if rec.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.want, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/webhooks"
+)
+
+const (
+	webhookSecretBytes   = 32
+	maxWebhooksPerUser   = 10
+	webhookSecretPrefix  = "whsec_"
+	deliveryDefaultLimit = 50
+	deliveryMaxLimit     = 200
+)
+
+type WebhookHandler struct {
+	Webhooks *repository.WebhookRepository
+}
+
+// NewWebhookHandler создает обработчик исходящих вебхуков.
+func NewWebhookHandler(webhooks *repository.WebhookRepository) *WebhookHandler {
+	return &WebhookHandler{Webhooks: webhooks}
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"max=10,dive,oneof=budget_updated budget_alert ai_advices"`
+}
+
+type WebhookResponse struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Create регистрирует endpoint и один раз возвращает секрет для проверки подписи.
+func (h *WebhookHandler) Create(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	var req CreateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return badRequest(c, "invalid payload")
+	}
+	if err := c.Validate(&req); err != nil {
+		return badRequest(c, "validation failed")
+	}
+
+	if err := webhooks.ValidateURL(c.Request().Context(), req.URL); err != nil {
+		if errors.Is(err, webhooks.ErrForbiddenAddress) {
+			return badRequest(c, "url must point to a public address")
+		}
+		return badRequest(c, err.Error())
+	}
+
+	count, err := h.Webhooks.CountEndpoints(c.Request().Context(), userID)
+	if err != nil {
+		return serverError(c)
+	}
+	if count >= maxWebhooksPerUser {
+		return conflict(c, "webhook limit reached")
+	}
+
+	token, err := auth.GenerateToken(webhookSecretBytes)
+	if err != nil {
+		return serverError(c)
+	}
+
+	endpoint, err := h.Webhooks.CreateEndpoint(c.Request().Context(), userID, req.URL, webhookSecretPrefix+token, req.EventTypes)
+	if err != nil {
+		return serverError(c)
+	}
+
+	response := toWebhookResponse(endpoint)
+	response.Secret = endpoint.Secret
+	return c.JSON(http.StatusCreated, response)
+}
+
+// List возвращает endpoint пользователя без секретов.
+func (h *WebhookHandler) List(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	endpoints, err := h.Webhooks.ListEndpoints(c.Request().Context(), userID)
+	if err != nil {
+		return serverError(c)
+	}
+
+	response := make([]WebhookResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		response = append(response, toWebhookResponse(endpoint))
+	}
+
+	return c.JSON(http.StatusOK, map[string][]WebhookResponse{"webhooks": response})
+}
+
+// Delete удаляет endpoint вместе с журналом доставок.
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid webhook id")
+	}
+
+	if err := h.Webhooks.DeleteEndpoint(c.Request().Context(), userID, endpointID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "webhook not found")
+		}
+		return serverError(c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Deliveries возвращает журнал доставок endpoint, начиная с новых.
+func (h *WebhookHandler) Deliveries(c echo.Context) error {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok {
+		return unauthorized(c)
+	}
+
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return badRequest(c, "invalid webhook id")
+	}
+
+	limit, offset, err := parsePagination(c, deliveryDefaultLimit, deliveryMaxLimit)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	deliveries, err := h.Webhooks.ListDeliveries(c.Request().Context(), userID, endpointID, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return notFound(c, "webhook not found")
+		}
+		return serverError(c)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]models.WebhookDelivery{"deliveries": deliveries})
+}
+
+func toWebhookResponse(endpoint models.WebhookEndpoint) WebhookResponse {
+	return WebhookResponse{
+		ID:         endpoint.ID,
+		URL:        endpoint.URL,
+		EventTypes: endpoint.EventTypes,
+		CreatedAt:  endpoint.CreatedAt,
+	}
+}
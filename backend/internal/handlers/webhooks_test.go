@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+)
+
+// TestCreateWebhookRejectsInternalURL проверяет, что endpoint во внутренней
+// сети не регистрируется: до хранилища запрос не доходит.
+func TestCreateWebhookRejectsInternalURL(t *testing.T) {
+	e := echo.New()
+	e.Validator = structValidator{validate: validator.New()}
+	handler := NewWebhookHandler(nil)
+
+	for _, target := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://10.0.0.1/hook",
+	} {
+		body := `{"url":"` + target + `","event_types":["budget_updated"]}`
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(auth.ContextUserIDKey, uuid.New())
+
+		if err := handler.Create(c); err != nil {
+			t.Fatalf("%s: unexpected error: %v", target, err)
+		}
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "public address") {
+			t.Fatalf("%s: expected 400, got %d %s", target, rec.Code, rec.Body.String())
+		}
+	}
+}
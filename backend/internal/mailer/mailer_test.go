@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuildMessage проверяет заголовки и кодирование темы письма.
+func TestBuildMessage(t *testing.T) {
+	date := time.Date(2026, 1, 12, 14, 0, 0, 0, time.UTC)
+	raw := string(buildMessage("noreply@example.com", Message{
+		To:      "user@example.com",
+		Subject: "Сброс пароля",
+		Body:    "строка 1\nстрока 2",
+	}, date))
+
+	headers, body, ok := strings.Cut(raw, "\r\n\r\n")
+	if !ok {
+		t.Fatal("expected blank line between headers and body")
+	}
+
+	for _, want := range []string{
+		"From: noreply@example.com",
+		"To: user@example.com",
+		"Subject: =?utf-8?q?",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Date: Mon, 12 Jan 2026 14:00:00 +0000",
+	} {
+		if !strings.Contains(headers, want) {
+			t.Fatalf("expected header %q in:\n%s", want, headers)
+		}
+	}
+
+	if body != "строка 1\r\nстрока 2" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+// TestMemoryMailer проверяет сохранение отправленных писем.
+func TestMemoryMailer(t *testing.T) {
+	m := NewMemoryMailer()
+	if err := m.Send(context.Background(), Message{To: "a@example.com", Subject: "s", Body: "b"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	messages := m.Messages()
+	if len(messages) != 1 || messages[0].To != "a@example.com" {
+		t.Fatalf("unexpected messages %+v", messages)
+	}
+}
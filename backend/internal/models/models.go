@@ -13,6 +13,20 @@ type PriorityColor string
 
 type NoteType string
 
+type Recurrence string
+
+type PlanRole string
+
+type AlertRuleType string
+
+type WebhookDeliveryStatus string
+
+type UserTokenPurpose string
+
+type WeekStart string
+
+type Permission string
+
 const (
 	CategoryTypeMandatory CategoryType = "mandatory"
 	CategoryTypeOptional  CategoryType = "optional"
@@ -23,28 +37,79 @@ const (
 
 	NoteTypeAI   NoteType = "ai"
 	NoteTypeUser NoteType = "user"
+
+	RecurrenceNone     Recurrence = "none"
+	RecurrenceMonthly  Recurrence = "monthly"
+	RecurrenceBiweekly Recurrence = "biweekly"
+	RecurrenceCustom   Recurrence = "custom"
+
+	PlanRoleOwner  PlanRole = "owner"
+	PlanRoleEditor PlanRole = "editor"
+	PlanRoleViewer PlanRole = "viewer"
+
+	AlertRulePlanTotal     AlertRuleType = "plan_total"
+	AlertRuleCategoryShare AlertRuleType = "category_share"
+
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenEmailChange       UserTokenPurpose = "email_change"
+
+	WeekStartMonday WeekStart = "monday"
+	WeekStartSunday WeekStart = "sunday"
+
+	PermissionUsersRead          Permission = "users:read"
+	PermissionUsersUnlock        Permission = "users:unlock"
+	PermissionAIRequestsRead     Permission = "ai_requests:read"
+	PermissionAIRequestsPayloads Permission = "ai_requests:payloads"
+	PermissionUsageRead          Permission = "usage:read"
+	PermissionExchangeRatesRead  Permission = "exchange_rates:read"
+	PermissionExchangeRatesWrite Permission = "exchange_rates:write"
+	PermissionRolesManage        Permission = "roles:manage"
+
+	// RoleAdmin выдается пользователям из ADMIN_EMAILS при запуске.
+	RoleAdmin = "admin"
 )
 
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	Name         *string   `json:"name,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"`
+	Name            *string    `json:"name,omitempty"`
+	BaseCurrency    string     `json:"base_currency"`
+	Locale          string     `json:"locale"`
+	TimeZone        string     `json:"time_zone"`
+	WeekStart       WeekStart  `json:"week_start"`
+	PendingEmail    *string    `json:"pending_email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	TOTPSecret      *string    `json:"-"`
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at,omitempty"`
+	FailedLogins    int        `json:"failed_login_attempts"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type BudgetPlan struct {
-	ID              uuid.UUID `json:"id"`
-	UserID          uuid.UUID `json:"user_id"`
-	Title           string    `json:"title"`
-	BudgetCents     int64     `json:"budget_cents"`
-	PeriodStart     time.Time `json:"period_start"`
-	PeriodEnd       time.Time `json:"period_end"`
-	BackgroundColor string    `json:"background_color"`
-	IsAIGenerated   bool      `json:"is_ai_generated"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Title           string     `json:"title"`
+	BudgetCents     int64      `json:"budget_cents"`
+	Currency        string     `json:"currency"`
+	PeriodStart     time.Time  `json:"period_start"`
+	PeriodEnd       time.Time  `json:"period_end"`
+	BackgroundColor string     `json:"background_color"`
+	IsAIGenerated   bool       `json:"is_ai_generated"`
+	Recurrence      Recurrence `json:"recurrence"`
+	RecurrenceDays  *int       `json:"recurrence_interval_days,omitempty"`
+	CarryOver       bool       `json:"carry_over"`
+	NextPlanID      *uuid.UUID `json:"next_plan_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type ExpenseCategory struct {
@@ -68,6 +133,17 @@ type ExpenseItem struct {
 	UpdatedAt     time.Time     `json:"updated_at"`
 }
 
+type Transaction struct {
+	ID          uuid.UUID `json:"id"`
+	ItemID      uuid.UUID `json:"item_id"`
+	AmountCents int64     `json:"amount_cents"`
+	OccurredOn  time.Time `json:"occurred_on"`
+	Merchant    *string   `json:"merchant,omitempty"`
+	Memo        *string   `json:"memo,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
 type Note struct {
 	ID        uuid.UUID `json:"id"`
 	PlanID    uuid.UUID `json:"plan_id"`
@@ -95,8 +171,113 @@ type RefreshToken struct {
 	ID         uuid.UUID  `json:"id"`
 	UserID     uuid.UUID  `json:"user_id"`
 	TokenHash  string     `json:"-"`
+	UserAgent  *string    `json:"user_agent,omitempty"`
+	IPAddress  *string    `json:"ip_address,omitempty"`
 	ExpiresAt  time.Time  `json:"expires_at"`
 	CreatedAt  time.Time  `json:"created_at"`
 	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
 	ReplacedBy *uuid.UUID `json:"replaced_by,omitempty"`
 }
+
+type ExchangeRate struct {
+	FromCurrency  string    `json:"from_currency"`
+	ToCurrency    string    `json:"to_currency"`
+	Rate          float64   `json:"rate"`
+	EffectiveDate time.Time `json:"effective_date"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PlanMember struct {
+	PlanID    uuid.UUID `json:"plan_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      *string   `json:"name,omitempty"`
+	Role      PlanRole  `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PlanInvite struct {
+	ID         uuid.UUID  `json:"id"`
+	PlanID     uuid.UUID  `json:"plan_id"`
+	Email      string     `json:"email"`
+	Role       PlanRole   `json:"role"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  uuid.UUID  `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AlertRule struct {
+	ID               uuid.UUID     `json:"id"`
+	PlanID           uuid.UUID     `json:"plan_id"`
+	RuleType         AlertRuleType `json:"rule_type"`
+	ThresholdPercent int           `json:"threshold_percent"`
+	CategoryID       *uuid.UUID    `json:"category_id,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+type BudgetAlert struct {
+	RuleID           uuid.UUID     `json:"rule_id"`
+	PlanID           uuid.UUID     `json:"plan_id"`
+	RuleType         AlertRuleType `json:"rule_type"`
+	ThresholdPercent int           `json:"threshold_percent"`
+	CategoryID       *uuid.UUID    `json:"category_id,omitempty"`
+	CategoryTitle    *string       `json:"category_title,omitempty"`
+	SpentCents       int64         `json:"spent_cents"`
+	LimitCents       int64         `json:"limit_cents"`
+}
+
+type WebhookEndpoint struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id"`
+	EndpointID     uuid.UUID             `json:"endpoint_id"`
+	EventType      string                `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty"`
+	LastError      *string               `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
+
+type PersonalAccessToken struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	TokenHash   string     `json:"-"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type SigningKey struct {
+	ID        string `json:"kid"`
+	Algorithm string `json:"algorithm"`
+	// EncryptedPrivateKey — PEM закрытого ключа, зашифрованный JWT_KEY_ENCRYPTION_KEY.
+	EncryptedPrivateKey []byte     `json:"-"`
+	ActivatesAt         time.Time  `json:"activates_at"`
+	ExpiresAt           *time.Time `json:"expires_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+type Role struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
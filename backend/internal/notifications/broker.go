@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DeliverFunc доставляет событие локальным подписчикам пользователя.
+type DeliverFunc func(userID uuid.UUID, event Event)
+
+// Broker рассылает опубликованные события всем экземплярам сервиса.
+// Каждый экземпляр регистрирует DeliverFunc через Subscribe и получает
+// в нее все события, включая опубликованные им самим. Ошибка Publish
+// означает, что событие не доставлено никому, даже на этом экземпляре.
+type Broker interface {
+	Publish(ctx context.Context, userID uuid.UUID, event Event) error
+	Subscribe(deliver DeliverFunc)
+}
+
+// MemoryBroker доставляет события только внутри текущего процесса.
+type MemoryBroker struct {
+	mu       sync.RWMutex
+	handlers []DeliverFunc
+}
+
+// NewMemoryBroker создает брокер для одного экземпляра сервиса.
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// Publish синхронно передает событие всем зарегистрированным обработчикам.
+func (b *MemoryBroker) Publish(_ context.Context, userID uuid.UUID, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, deliver := range b.handlers {
+		deliver(userID, event)
+	}
+	return nil
+}
+
+// Subscribe регистрирует обработчик событий.
+func (b *MemoryBroker) Subscribe(deliver DeliverFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, deliver)
+}
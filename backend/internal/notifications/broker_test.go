@@ -0,0 +1,65 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestSharedBrokerFanOut проверяет доставку события подписчику другого хаба через общий брокер.
+func TestSharedBrokerFanOut(t *testing.T) {
+	broker := NewMemoryBroker()
+	replicaA := NewHub(WithBroker(broker))
+	replicaB := NewHub(WithBroker(broker))
+	userID := uuid.New()
+
+	chA, unsubscribeA := replicaA.Subscribe(userID)
+	defer unsubscribeA()
+	chB, unsubscribeB := replicaB.Subscribe(userID)
+	defer unsubscribeB()
+
+	replicaA.Publish(userID, Event{Type: "budget_updated"})
+
+	for name, ch := range map[string]<-chan Event{"A": chA, "B": chB} {
+		select {
+		case event := <-ch:
+			if event.Type != "budget_updated" {
+				t.Fatalf("replica %s: unexpected event type %s", name, event.Type)
+			}
+		case <-time.After(500 * time.Millisecond):
+			t.Fatalf("replica %s: expected event to be delivered", name)
+		}
+	}
+}
+
+type failingBroker struct {
+	MemoryBroker
+}
+
+func (b *failingBroker) Publish(context.Context, uuid.UUID, Event) error {
+	return errors.New("broker unavailable")
+}
+
+// TestHubDeliversLocallyWhenBroadcastFails проверяет, что событие, которое
+// брокер не принял, все равно доходит до подписчиков этого экземпляра.
+func TestHubDeliversLocallyWhenBroadcastFails(t *testing.T) {
+	hub := NewHub(WithBroker(&failingBroker{}))
+	userID := uuid.New()
+
+	ch, unsubscribe := hub.Subscribe(userID)
+	defer unsubscribe()
+
+	hub.Publish(userID, Event{Type: "budget_updated"})
+
+	select {
+	case event := <-ch:
+		if event.Type != "budget_updated" {
+			t.Fatalf("unexpected event type %s", event.Type)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected event to be delivered locally")
+	}
+}
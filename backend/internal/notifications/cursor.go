@@ -0,0 +1,114 @@
+package notifications
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cursorSeenLimit ограничивает число полученных ID в курсоре, чтобы он не
+// разрастался при всплеске событий; сверх лимита возможны повторы.
+const cursorSeenLimit = 50
+
+// Cursor — позиция SSE-клиента в журнале событий. Кроме наибольшего
+// полученного ID он помнит ID из окна replayOverlap перед ним, которые клиент
+// уже получил, чтобы при переподключении не досылать их повторно. Курсор
+// передается клиенту в поле id SSE-события в виде "42" или "42:38,40" и
+// возвращается в Last-Event-ID.
+type Cursor struct {
+	lastID int64
+	lastAt time.Time
+	// seen хранит время публикации полученных событий; нулевое время у ID,
+	// прочитанных из курсора клиента, пока их событие не встретится снова.
+	seen map[int64]time.Time
+}
+
+// ParseCursor разбирает курсор из Last-Event-ID. Неверное значение дает
+// пустой курсор: события досылаются без учета ранее полученных.
+func ParseCursor(value string) *Cursor {
+	cursor := &Cursor{seen: make(map[int64]time.Time)}
+
+	last, rest, _ := strings.Cut(strings.TrimSpace(value), ":")
+	lastID, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || lastID <= 0 {
+		return cursor
+	}
+	cursor.lastID = lastID
+	cursor.seen[lastID] = time.Time{}
+
+	for _, part := range strings.Split(rest, ",") {
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err == nil && id > 0 && id < lastID && len(cursor.seen) <= cursorSeenLimit {
+			cursor.seen[id] = time.Time{}
+		}
+	}
+
+	return cursor
+}
+
+// LastID возвращает наибольший полученный клиентом ID.
+func (c *Cursor) LastID() int64 {
+	return c.lastID
+}
+
+// Delivered сообщает, получал ли клиент событие с этим ID.
+func (c *Cursor) Delivered(id int64) bool {
+	_, ok := c.seen[id]
+	return ok
+}
+
+// Add отмечает событие полученным. События без ID не учитываются.
+func (c *Cursor) Add(event Event) {
+	if event.ID == 0 {
+		return
+	}
+
+	// Время ID из курсора клиента неизвестно; событие с меньшим ID
+	// опубликовано не позже текущего, поэтому из окна выйдет не раньше срока.
+	for id, at := range c.seen {
+		if at.IsZero() && id < event.ID {
+			c.seen[id] = event.Timestamp
+		}
+	}
+	c.seen[event.ID] = event.Timestamp
+
+	if event.ID > c.lastID {
+		c.lastID = event.ID
+		c.lastAt = event.Timestamp
+	}
+
+	since := c.lastAt.Add(-replayOverlap)
+	for id, at := range c.seen {
+		if id != c.lastID && !at.IsZero() && at.Before(since) {
+			delete(c.seen, id)
+		}
+	}
+}
+
+// String возвращает курсор для поля id SSE-события.
+func (c *Cursor) String() string {
+	ids := make([]int64, 0, len(c.seen))
+	for id := range c.seen {
+		if id < c.lastID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > cursorSeenLimit {
+		ids = ids[len(ids)-cursorSeenLimit:]
+	}
+
+	var b strings.Builder
+	b.WriteString(strconv.FormatInt(c.lastID, 10))
+	for i, id := range ids {
+		if i == 0 {
+			b.WriteByte(':')
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatInt(id, 10))
+	}
+
+	return b.String()
+}
@@ -1,33 +1,99 @@
 package notifications
 
 import (
+	"context"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	subscriberBuffer = 10
+	replayLimit      = 500
+	storeTimeout     = 5 * time.Second
+	// replayOverlap — сколько событий до Last-Event-ID досылается повторно.
+	// ID присваивается при вставке, а не при коммите, и события публикуются
+	// не строго по порядку ID: событие с меньшим ID может появиться в журнале
+	// или дойти до подписчика позже события с большим. Публикация укладывается
+	// в storeTimeout, окно взято с запасом на расхождение часов экземпляров.
+	replayOverlap = 30 * time.Second
+)
+
 type Event struct {
+	ID        int64       `json:"id,omitempty"`
 	Type      string      `json:"type"`
 	Timestamp time.Time   `json:"timestamp"`
 	Data      interface{} `json:"data,omitempty"`
 }
 
+// Store сохраняет события пользователей, чтобы доставить их после переподключения.
+// Append присваивает событию монотонно растущий ID. ListAfter возвращает события
+// с ID больше afterID, а также события с меньшим ID, созданные не раньше чем за overlap до события afterID.
+type Store interface {
+	Append(ctx context.Context, userID uuid.UUID, event Event) (Event, error)
+	ListAfter(ctx context.Context, userID uuid.UUID, afterID int64, overlap time.Duration, limit int) ([]Event, error)
+}
+
+// Sink получает каждое опубликованное событие один раз, на экземпляре, который его
+// опубликовал, например чтобы поставить его в очередь доставки вебхуков.
+type Sink interface {
+	Enqueue(ctx context.Context, userID uuid.UUID, event Event) error
+}
+
 type Hub struct {
-	mu          sync.RWMutex
+	mu          sync.Mutex
 	subscribers map[uuid.UUID]map[chan Event]struct{}
+	store       Store
+	broker      Broker
+	sinks       []Sink
+}
+
+// Option настраивает Hub при создании.
+type Option func(*Hub)
+
+// WithStore включает сохранение событий в store и их повторную доставку по Last-Event-ID.
+func WithStore(store Store) Option {
+	return func(h *Hub) {
+		h.store = store
+	}
+}
+
+// WithBroker задает брокер, через который события доходят до всех экземпляров сервиса.
+func WithBroker(broker Broker) Option {
+	return func(h *Hub) {
+		h.broker = broker
+	}
+}
+
+// WithSink добавляет получателя всех опубликованных событий.
+func WithSink(sink Sink) Option {
+	return func(h *Hub) {
+		h.sinks = append(h.sinks, sink)
+	}
 }
 
-// NewHub создает хаб для SSE-подписок.
-func NewHub() *Hub {
-	return &Hub{
+// NewHub создает хаб для SSE-подписок. По умолчанию события не сохраняются
+// и доставляются только внутри процесса (MemoryBroker).
+func NewHub(options ...Option) *Hub {
+	hub := &Hub{
 		subscribers: make(map[uuid.UUID]map[chan Event]struct{}),
 	}
+	for _, option := range options {
+		option(hub)
+	}
+	if hub.broker == nil {
+		hub.broker = NewMemoryBroker()
+	}
+	hub.broker.Subscribe(hub.deliver)
+	return hub
 }
 
 // Subscribe подписывает пользователя на события и возвращает канал и функцию отписки.
+// Канал закрывается при отписке или если подписчик не успевает читать события.
 func (h *Hub) Subscribe(userID uuid.UUID) (<-chan Event, func()) {
-	ch := make(chan Event, 10)
+	ch := make(chan Event, subscriberBuffer)
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -43,32 +109,93 @@ func (h *Hub) Subscribe(userID uuid.UUID) (<-chan Event, func()) {
 		h.mu.Lock()
 		defer h.mu.Unlock()
 
-		if subs, exists := h.subscribers[userID]; exists {
-			delete(subs, ch)
-			if len(subs) == 0 {
-				delete(h.subscribers, userID)
-			}
-		}
-		close(ch)
+		h.remove(userID, ch)
 	}
 }
 
-// Publish отправляет событие всем подписчикам пользователя.
+// Publish сохраняет событие (если задан store), передает его получателям Sink
+// и брокеру, который доставляет событие подписчикам пользователя на всех экземплярах.
+// Если брокер не принял событие, оно доставляется хотя бы локальным подписчикам.
 func (h *Hub) Publish(userID uuid.UUID, event Event) {
 	event.Timestamp = time.Now().UTC()
 
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
 
-	subs, ok := h.subscribers[userID]
-	if !ok {
-		return
+	if h.store != nil {
+		stored, err := h.store.Append(ctx, userID, event)
+		if err != nil {
+			slog.Warn("notification persist failed",
+				slog.String("user_id", userID.String()),
+				slog.String("type", event.Type),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			event = stored
+		}
 	}
 
-	for ch := range subs {
+	for _, sink := range h.sinks {
+		if err := sink.Enqueue(ctx, userID, event); err != nil {
+			slog.Warn("notification sink failed",
+				slog.String("user_id", userID.String()),
+				slog.String("type", event.Type),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if err := h.broker.Publish(ctx, userID, event); err != nil {
+		slog.Warn("notification broadcast failed, delivering locally",
+			slog.String("user_id", userID.String()),
+			slog.String("type", event.Type),
+			slog.String("error", err.Error()),
+		)
+		h.deliver(userID, event)
+	}
+}
+
+// deliver отправляет событие локальным подписчикам пользователя.
+// Подписчик с переполненным буфером отключается: клиент переподключится
+// и получит пропущенные события через Replay.
+func (h *Hub) deliver(userID uuid.UUID, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[userID] {
 		select {
 		case ch <- event:
 		default:
+			h.remove(userID, ch)
 		}
 	}
 }
+
+// Replay возвращает сохраненные события пользователя с ID больше afterID и
+// события из окна replayOverlap перед ним. Часть из них клиент уже получил:
+// такие события отсекаются по его Cursor.
+func (h *Hub) Replay(ctx context.Context, userID uuid.UUID, afterID int64) ([]Event, error) {
+	if h.store == nil {
+		return nil, nil
+	}
+
+	return h.store.ListAfter(ctx, userID, afterID, replayOverlap, replayLimit)
+}
+
+// remove отписывает канал и закрывает его. Вызывается под h.mu.
+func (h *Hub) remove(userID uuid.UUID, ch chan Event) {
+	subs, ok := h.subscribers[userID]
+	if !ok {
+		return
+	}
+
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(h.subscribers, userID)
+	}
+}
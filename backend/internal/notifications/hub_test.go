@@ -1,6 +1,8 @@
 package notifications
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -42,3 +44,129 @@ func TestHubUnsubscribe(t *testing.T) {
 		t.Fatal("expected channel to be closed")
 	}
 }
+
+type memoryStore struct {
+	mu     sync.Mutex
+	nextID int64
+	events map[uuid.UUID][]Event
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{events: make(map[uuid.UUID][]Event)}
+}
+
+func (s *memoryStore) Append(_ context.Context, userID uuid.UUID, event Event) (Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	event.ID = s.nextID
+	s.events[userID] = append(s.events[userID], event)
+	return event, nil
+}
+
+func (s *memoryStore) ListAfter(_ context.Context, userID uuid.UUID, afterID int64, overlap time.Duration, limit int) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var since time.Time
+	found := false
+	for _, event := range s.events[userID] {
+		if event.ID == afterID {
+			since, found = event.Timestamp.Add(-overlap), true
+		}
+	}
+
+	out := make([]Event, 0)
+	for _, event := range s.events[userID] {
+		inWindow := found && event.ID < afterID && !event.Timestamp.Before(since)
+		if (event.ID > afterID || inWindow) && len(out) < limit {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+// backdate сдвигает время события назад, как будто оно опубликовано раньше.
+func (s *memoryStore) backdate(userID uuid.UUID, id int64, by time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.events[userID] {
+		if s.events[userID][i].ID == id {
+			s.events[userID][i].Timestamp = s.events[userID][i].Timestamp.Add(-by)
+		}
+	}
+}
+
+// TestPersistentHubReplay проверяет присвоение ID и повторную доставку пропущенных событий.
+func TestPersistentHubReplay(t *testing.T) {
+	hub := NewHub(WithStore(newMemoryStore()))
+	userID := uuid.New()
+
+	hub.Publish(userID, Event{Type: "first"})
+	hub.Publish(userID, Event{Type: "second"})
+	hub.Publish(uuid.New(), Event{Type: "other"})
+	hub.Publish(userID, Event{Type: "third"})
+
+	events, err := hub.Replay(context.Background(), userID, 1)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != "second" || events[1].Type != "third" {
+		t.Fatalf("unexpected replay: %+v", events)
+	}
+	if events[0].ID >= events[1].ID {
+		t.Fatalf("expected increasing ids, got %d and %d", events[0].ID, events[1].ID)
+	}
+}
+
+// TestPersistentHubReplayOverlap проверяет, что события с меньшим ID из окна
+// replayOverlap досылаются повторно: их транзакция могла закоммититься позже.
+func TestPersistentHubReplayOverlap(t *testing.T) {
+	store := newMemoryStore()
+	hub := NewHub(WithStore(store))
+	userID := uuid.New()
+
+	hub.Publish(userID, Event{Type: "old"})
+	hub.Publish(userID, Event{Type: "late"})
+	hub.Publish(userID, Event{Type: "last"})
+	store.backdate(userID, 1, 2*replayOverlap)
+
+	events, err := hub.Replay(context.Background(), userID, 3)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != "late" {
+		t.Fatalf("expected only the event inside the overlap window, got %+v", events)
+	}
+
+	events, err = hub.Replay(context.Background(), userID, 42)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no overlap for unknown last id, got %+v", events)
+	}
+}
+
+// TestHubDisconnectsSlowSubscriber проверяет отключение подписчика с переполненным буфером.
+func TestHubDisconnectsSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+	userID := uuid.New()
+
+	ch, unsubscribe := hub.Subscribe(userID)
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		hub.Publish(userID, Event{Type: "test"})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Fatalf("expected %d buffered events before disconnect, got %d", subscriberBuffer, received)
+	}
+}
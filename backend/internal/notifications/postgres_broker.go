@@ -0,0 +1,150 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// PostgresChannel — канал LISTEN/NOTIFY для событий уведомлений.
+	PostgresChannel = "budget_notifications"
+
+	listenRetryMin = time.Second
+	listenRetryMax = 30 * time.Second
+)
+
+type postgresMessage struct {
+	UserID uuid.UUID `json:"user_id"`
+	Event  Event     `json:"event"`
+}
+
+// PostgresBroker рассылает события между экземплярами через Postgres LISTEN/NOTIFY.
+// Run держит отдельное соединение с LISTEN и должен работать все время жизни сервиса.
+type PostgresBroker struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+	// listening — активен ли LISTEN; пока его нет, события этого экземпляра
+	// доставляются локальным подписчикам напрямую.
+	listening atomic.Bool
+
+	mu       sync.RWMutex
+	handlers []DeliverFunc
+}
+
+// NewPostgresBroker создает брокер поверх пула соединений.
+func NewPostgresBroker(db *pgxpool.Pool, logger *slog.Logger) *PostgresBroker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PostgresBroker{db: db, logger: logger}
+}
+
+// Publish отправляет событие в канал NOTIFY. Само событие доставляется
+// подписчикам, в том числе на этом экземпляре, из Run. Пока Run
+// переподключается, локальные подписчики получают событие сразу: если LISTEN
+// восстановится раньше NOTIFY, событие придет дважды, но не потеряется.
+func (b *PostgresBroker) Publish(ctx context.Context, userID uuid.UUID, event Event) error {
+	payload, err := json.Marshal(postgresMessage{UserID: userID, Event: event})
+	if err != nil {
+		return err
+	}
+
+	listening := b.listening.Load()
+	if _, err := b.db.Exec(ctx, `SELECT pg_notify($1, $2)`, PostgresChannel, string(payload)); err != nil {
+		return err
+	}
+
+	if !listening {
+		b.dispatch(userID, event)
+	}
+	return nil
+}
+
+// Subscribe регистрирует обработчик событий.
+func (b *PostgresBroker) Subscribe(deliver DeliverFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, deliver)
+}
+
+// Run слушает канал до отмены ctx и переподключается с экспоненциальной задержкой
+// при обрыве соединения. События, опубликованные во время обрыва, клиенты получат
+// повторно по Last-Event-ID, если у Hub включено сохранение.
+func (b *PostgresBroker) Run(ctx context.Context) {
+	delay := listenRetryMin
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		b.logger.Warn("notification listener disconnected",
+			slog.String("error", err.Error()),
+			slog.Duration("retry_in", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > listenRetryMax {
+			delay = listenRetryMax
+		}
+	}
+}
+
+func (b *PostgresBroker) listen(ctx context.Context) error {
+	pooled, err := b.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Соединение с активным LISTEN нельзя возвращать в пул.
+	conn := pooled.Hijack()
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, `LISTEN `+PostgresChannel); err != nil {
+		return err
+	}
+	b.listening.Store(true)
+	defer b.listening.Store(false)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var message postgresMessage
+		if err := json.Unmarshal([]byte(notification.Payload), &message); err != nil {
+			b.logger.Warn("invalid notification payload", slog.String("error", err.Error()))
+			continue
+		}
+
+		b.dispatch(message.UserID, message.Event)
+	}
+}
+
+func (b *PostgresBroker) dispatch(userID uuid.UUID, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, deliver := range b.handlers {
+		deliver(userID, event)
+	}
+}
@@ -0,0 +1,108 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+type deliveredEvent struct {
+	userID uuid.UUID
+	event  Event
+}
+
+func subscribeBroker(broker *PostgresBroker) <-chan deliveredEvent {
+	ch := make(chan deliveredEvent, 16)
+	broker.Subscribe(func(userID uuid.UUID, event Event) {
+		select {
+		case ch <- deliveredEvent{userID: userID, event: event}:
+		default:
+		}
+	})
+	return ch
+}
+
+// receive ждет событие пользователя userID из канала, пропуская чужие:
+// канал NOTIFY общий для всей базы.
+func receive(t *testing.T, ch <-chan deliveredEvent, userID uuid.UUID) (Event, bool) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case delivered := <-ch:
+			if delivered.userID == userID {
+				return delivered.event, true
+			}
+		case <-timeout:
+			return Event{}, false
+		}
+	}
+}
+
+// TestPostgresBrokerRoundTrip проверяет, что событие через NOTIFY доходит до
+// подписчиков обоих экземпляров ровно один раз.
+func TestPostgresBrokerRoundTrip(t *testing.T) {
+	db := testdb.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	publisher := NewPostgresBroker(db, nil)
+	replica := NewPostgresBroker(db, nil)
+	fromPublisher := subscribeBroker(publisher)
+	fromReplica := subscribeBroker(replica)
+
+	for _, broker := range []*PostgresBroker{publisher, replica} {
+		go broker.Run(ctx)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !publisher.listening.Load() || !replica.listening.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("listeners did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	userID := uuid.New()
+	if err := publisher.Publish(ctx, userID, Event{ID: 7, Type: "budget_updated"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	for name, ch := range map[string]<-chan deliveredEvent{"publisher": fromPublisher, "replica": fromReplica} {
+		event, ok := receive(t, ch, userID)
+		if !ok {
+			t.Fatalf("%s: expected the event to arrive through NOTIFY", name)
+		}
+		if event.ID != 7 || event.Type != "budget_updated" {
+			t.Fatalf("%s: unexpected event %+v", name, event)
+		}
+	}
+
+	select {
+	case delivered := <-fromPublisher:
+		if delivered.userID == userID {
+			t.Fatalf("expected a single delivery, got a duplicate %+v", delivered.event)
+		}
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// TestPostgresBrokerDeliversLocallyWithoutListener проверяет, что пока LISTEN
+// не работает, локальные подписчики получают событие сразу.
+func TestPostgresBrokerDeliversLocallyWithoutListener(t *testing.T) {
+	db := testdb.New(t)
+	broker := NewPostgresBroker(db, nil)
+	ch := subscribeBroker(broker)
+	userID := uuid.New()
+
+	if err := broker.Publish(context.Background(), userID, Event{Type: "budget_updated"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if _, ok := receive(t, ch, userID); !ok {
+		t.Fatal("expected local delivery while the listener is down")
+	}
+}
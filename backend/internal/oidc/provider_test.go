@@ -0,0 +1,190 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "budget-planner"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "http://localhost:3000/auth/oidc/callback"
+	testCode         = "auth-code"
+)
+
+// fakeIssuer — минимальный OIDC-провайдер: discovery, JWKS и token endpoint,
+// проверяющий PKCE по challenge из запроса авторизации.
+type fakeIssuer struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	issuer := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+			return
+		}
+
+		clientID, secret, ok := r.BasicAuth()
+		if !ok || clientID != testClientID || secret != testClientSecret {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+		if r.PostForm.Get("code") != testCode || r.PostForm.Get("redirect_uri") != testRedirectURL ||
+			CodeChallenge(r.PostForm.Get("code_verifier")) != issuer.challenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":            issuer.server.URL,
+			"sub":            "user-1",
+			"aud":            testClientID,
+			"exp":            time.Now().Add(time.Minute).Unix(),
+			"iat":            time.Now().Unix(),
+			"nonce":          issuer.nonce,
+			"email":          "User@Example.com",
+			"email_verified": true,
+			"name":           "Иван",
+		}
+		for name, value := range issuer.claims {
+			claims[name] = value
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test-key"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// authorize имитирует страницу входа: запоминает challenge и nonce из адреса.
+func (f *fakeIssuer) authorize(t *testing.T, authURL string) {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != testClientID {
+		t.Fatalf("unexpected auth url %s", authURL)
+	}
+	f.challenge = query.Get("code_challenge")
+	f.nonce = query.Get("nonce")
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// TestProviderExchange проверяет полный обмен кода на проверенную личность.
+func TestProviderExchange(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := NewProvider(issuer.server.URL, testClientID, testClientSecret, testRedirectURL, 5*time.Second)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce-1", "verifier-1234567890")
+	if err != nil {
+		t.Fatalf("auth url: %v", err)
+	}
+	issuer.authorize(t, authURL)
+
+	identity, err := provider.Exchange(ctx, testCode, "verifier-1234567890", "nonce-1")
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+
+	if identity.Subject != "user-1" || identity.Email != "user@example.com" || !identity.EmailVerified || identity.Issuer != issuer.server.URL {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+}
+
+// TestProviderExchangeRejects проверяет отказ при неверном PKCE, nonce и аудитории.
+func TestProviderExchangeRejects(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := NewProvider(issuer.server.URL, testClientID, testClientSecret, testRedirectURL, 5*time.Second)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce-1", "verifier-1234567890")
+	if err != nil {
+		t.Fatalf("auth url: %v", err)
+	}
+	issuer.authorize(t, authURL)
+
+	if _, err := provider.Exchange(ctx, testCode, "other-verifier", "nonce-1"); err == nil {
+		t.Fatal("expected wrong code verifier to be rejected")
+	}
+
+	if _, err := provider.Exchange(ctx, testCode, "verifier-1234567890", "nonce-2"); err == nil {
+		t.Fatal("expected wrong nonce to be rejected")
+	}
+
+	issuer.claims = jwt.MapClaims{"aud": "another-client"}
+	if _, err := provider.Exchange(ctx, testCode, "verifier-1234567890", "nonce-1"); err == nil {
+		t.Fatal("expected foreign audience to be rejected")
+	}
+}
+
+// TestFlexibleBool проверяет разбор email_verified в виде строки.
+func TestFlexibleBool(t *testing.T) {
+	var claims idTokenClaims
+	if err := json.Unmarshal([]byte(`{"email_verified":"true"}`), &claims); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !claims.EmailVerified {
+		t.Fatal("expected string true to be parsed")
+	}
+}
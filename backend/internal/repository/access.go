@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// querier — общий интерфейс pgxpool.Pool и pgx.Tx для проверок доступа.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Запросы, находящие план сущности по ее идентификатору ($1).
+const (
+	planByID            = `SELECT id AS plan_id FROM budget_plans WHERE id = $1`
+	planByCategoryID    = `SELECT plan_id FROM expense_categories WHERE id = $1`
+	planByItemID        = `SELECT c.plan_id FROM expense_items i JOIN expense_categories c ON c.id = i.category_id WHERE i.id = $1`
+	planByNoteID        = `SELECT plan_id FROM notes WHERE id = $1`
+	planByTransactionID = `SELECT c.plan_id FROM transactions t JOIN expense_items i ON i.id = t.item_id JOIN expense_categories c ON c.id = i.category_id WHERE t.id = $1`
+)
+
+var (
+	memberRoles = []models.PlanRole{models.PlanRoleOwner, models.PlanRoleEditor, models.PlanRoleViewer}
+	editorRoles = []models.PlanRole{models.PlanRoleOwner, models.PlanRoleEditor}
+	ownerRoles  = []models.PlanRole{models.PlanRoleOwner}
+)
+
+// memberOf возвращает SQL-условие: пользователь userParam участвует в плане planExpr.
+func memberOf(planExpr, userParam string) string {
+	return `EXISTS (SELECT 1 FROM plan_members pm WHERE pm.plan_id = ` + planExpr + ` AND pm.user_id = ` + userParam + `)`
+}
+
+// editorOf возвращает SQL-условие: пользователь userParam может изменять план planExpr.
+func editorOf(planExpr, userParam string) string {
+	return `EXISTS (SELECT 1 FROM plan_members pm WHERE pm.plan_id = ` + planExpr + ` AND pm.user_id = ` + userParam + ` AND pm.role IN ('owner', 'editor'))`
+}
+
+// authorizePlan находит план сущности запросом lookup и проверяет роль пользователя в нем.
+// Возвращает ErrNotFound, если сущности нет или пользователь не участник плана,
+// и ErrForbidden, если его роль не входит в allowed.
+func authorizePlan(ctx context.Context, q querier, lookup string, entityID, userID uuid.UUID, allowed []models.PlanRole) (uuid.UUID, error) {
+	var planID uuid.UUID
+	var role models.PlanRole
+
+	err := q.QueryRow(ctx,
+		`SELECT x.plan_id, pm.role
+		 FROM (`+lookup+`) x
+		 JOIN plan_members pm ON pm.plan_id = x.plan_id AND pm.user_id = $2`,
+		entityID, userID,
+	).Scan(&planID, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrNotFound
+		}
+		return uuid.Nil, err
+	}
+
+	for _, candidate := range allowed {
+		if role == candidate {
+			return planID, nil
+		}
+	}
+
+	return uuid.Nil, ErrForbidden
+}
+
+// addPlanOwner делает пользователя владельцем только что созданного плана.
+func addPlanOwner(ctx context.Context, tx pgx.Tx, planID, userID uuid.UUID) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO plan_members (plan_id, user_id, role)
+		 VALUES ($1, $2, 'owner')`,
+		planID, userID,
+	)
+	return err
+}
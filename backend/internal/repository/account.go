@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// AccountRepository собирает данные пользователя для выгрузки и управляет
+// удалением учетной записи.
+type AccountRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewAccountRepository создает репозиторий учетных записей.
+func NewAccountRepository(db *pgxpool.Pool) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// ListOwnedPlans возвращает все планы, созданные пользователем, включая архивные.
+// Планы, к которым у пользователя есть только доступ участника, не входят.
+func (r *AccountRepository) ListOwnedPlans(ctx context.Context, userID uuid.UUID) ([]models.BudgetPlan, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+planColumns+`
+		 FROM budget_plans
+		 WHERE user_id = $1
+		 ORDER BY period_start, created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := make([]models.BudgetPlan, 0)
+	for rows.Next() {
+		var plan models.BudgetPlan
+		if err := rows.Scan(planScanDest(&plan)...); err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+// ListTransactions возвращает фактические траты по расходам планов пользователя.
+func (r *AccountRepository) ListTransactions(ctx context.Context, userID uuid.UUID) ([]models.Transaction, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT t.id, t.item_id, t.amount_cents, t.occurred_on, t.merchant, t.memo, t.created_at, t.updated_at
+		 FROM transactions t
+		 JOIN expense_items i ON i.id = t.item_id
+		 JOIN expense_categories c ON c.id = i.category_id
+		 JOIN budget_plans p ON p.id = c.plan_id
+		 WHERE p.user_id = $1
+		 ORDER BY t.occurred_on, t.created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := rows.Scan(
+			&transaction.ID,
+			&transaction.ItemID,
+			&transaction.AmountCents,
+			&transaction.OccurredOn,
+			&transaction.Merchant,
+			&transaction.Memo,
+			&transaction.CreatedAt,
+			&transaction.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// ListAIInputs возвращает анкеты, которые пользователь отправлял для генерации планов.
+func (r *AccountRepository) ListAIInputs(ctx context.Context, userID uuid.UUID) ([]models.AIInputData, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, user_id, period, income, mandatory_expenses, optional_expenses, assets, debts, additional_notes, created_at
+		 FROM ai_input_data
+		 WHERE user_id = $1
+		 ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inputs := make([]models.AIInputData, 0)
+	for rows.Next() {
+		var input models.AIInputData
+		if err := rows.Scan(
+			&input.ID,
+			&input.UserID,
+			&input.Period,
+			&input.Income,
+			&input.MandatoryExpenses,
+			&input.OptionalExpenses,
+			&input.Assets,
+			&input.Debts,
+			&input.AdditionalNotes,
+			&input.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return inputs, nil
+}
+
+// ListAIRequests возвращает логи AI-запросов пользователя вместе с промптами и ответами.
+func (r *AccountRepository) ListAIRequests(ctx context.Context, userID uuid.UUID) ([]AIRequestRecord, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, user_id, request_type, provider, model, prompt, request_payload, response_payload, raw_response, success, error_message, created_at
+		 FROM ai_requests
+		 WHERE user_id = $1
+		 ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]AIRequestRecord, 0)
+	for rows.Next() {
+		var record AIRequestRecord
+		if err := rows.Scan(
+			&record.ID,
+			&record.UserID,
+			&record.RequestType,
+			&record.Provider,
+			&record.Model,
+			&record.Prompt,
+			&record.RequestPayload,
+			&record.ResponsePayload,
+			&record.RawResponse,
+			&record.Success,
+			&record.ErrorMessage,
+			&record.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		requests = append(requests, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// ScheduleDeletion помечает учетную запись удаленной и отзывает все refresh-токены
+// и персональные токены. Данные остаются в базе до PurgeDeleted. Повторный
+// вызов не сдвигает дату удаления.
+func (r *AccountRepository) ScheduleDeletion(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	var deletedAt time.Time
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return deletedAt, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = tx.QueryRow(ctx,
+		`UPDATE users
+		 SET deleted_at = COALESCE(deleted_at, NOW()),
+		     updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING deleted_at`,
+		userID,
+	).Scan(&deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return deletedAt, ErrNotFound
+		}
+		return deletedAt, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE refresh_tokens
+		 SET revoked_at = NOW()
+		 WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return deletedAt, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE personal_access_tokens
+		 SET revoked_at = NOW()
+		 WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return deletedAt, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return deletedAt, err
+	}
+
+	return deletedAt, nil
+}
+
+// PurgeDeleted окончательно удаляет учетные записи, помеченные удаленными
+// раньше before. Планы, траты, заметки, AI-данные и токены удаляются каскадно.
+func (r *AccountRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM users
+		 WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+		before,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
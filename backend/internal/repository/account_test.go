@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestPurgeDeletedCutoff проверяет, что удаляются только учетные записи,
+// помеченные удаленными раньше cutoff, вместе с их данными.
+func TestPurgeDeletedCutoff(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	users := NewUserRepository(db)
+	accounts := NewAccountRepository(db)
+
+	expired, err := users.Create(ctx, "expired@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create expired user: %v", err)
+	}
+	recent, err := users.Create(ctx, "recent@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create recent user: %v", err)
+	}
+	active, err := users.Create(ctx, "active@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create active user: %v", err)
+	}
+
+	if _, err := NewPlanRepository(db).Create(ctx, expired.ID, "План", 10000, nil, time.Now(), time.Now().AddDate(0, 1, 0), "#FFFFFF", false); err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+
+	now := time.Now()
+	for user, deletedAt := range map[string]time.Time{
+		expired.ID.String(): now.Add(-31 * 24 * time.Hour),
+		recent.ID.String():  now.Add(-29 * 24 * time.Hour),
+	} {
+		if _, err := db.Exec(ctx, `UPDATE users SET deleted_at = $2 WHERE id = $1`, user, deletedAt); err != nil {
+			t.Fatalf("mark user deleted: %v", err)
+		}
+	}
+
+	purged, err := accounts.PurgeDeleted(ctx, now.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if purged != 1 {
+		t.Fatalf("expected one purged account, got %d", purged)
+	}
+
+	if _, err := users.GetByID(ctx, expired.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected expired account to be purged, got %v", err)
+	}
+	plans, err := accounts.ListOwnedPlans(ctx, expired.ID)
+	if err != nil || len(plans) != 0 {
+		t.Fatalf("expected purged account plans to be gone, got %d, %v", len(plans), err)
+	}
+	for _, user := range []string{recent.Email, active.Email} {
+		if _, err := users.GetByEmail(ctx, user); err != nil {
+			t.Fatalf("expected %s to stay, got %v", user, err)
+		}
+	}
+}
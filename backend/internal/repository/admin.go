@@ -15,11 +15,15 @@ type AdminRepository struct {
 }
 
 type AdminUser struct {
-	ID        uuid.UUID
-	Email     string
-	Name      *string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           uuid.UUID
+	Email        string
+	Name         *string
+	Roles        []string
+	FailedLogins int
+	LockedUntil  *time.Time
+	DeletedAt    *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 type AIRequestFilter struct {
@@ -65,7 +69,9 @@ func NewAdminRepository(db *pgxpool.Pool) *AdminRepository {
 // ListUsers возвращает список пользователей с пагинацией.
 func (r *AdminRepository) ListUsers(ctx context.Context, limit, offset int) ([]AdminUser, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT id, email, name, created_at, updated_at
+		`SELECT id, email, name,
+		        COALESCE((SELECT array_agg(role ORDER BY role) FROM user_roles WHERE user_id = users.id), '{}'),
+		        failed_login_attempts, locked_until, deleted_at, created_at, updated_at
 		 FROM users
 		 ORDER BY created_at DESC
 		 LIMIT $1 OFFSET $2`,
@@ -80,7 +86,7 @@ func (r *AdminRepository) ListUsers(ctx context.Context, limit, offset int) ([]A
 	for rows.Next() {
 		var user AdminUser
 		var name *string
-		if err := rows.Scan(&user.ID, &user.Email, &name, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Email, &name, &user.Roles, &user.FailedLogins, &user.LockedUntil, &user.DeletedAt, &user.CreatedAt, &user.UpdatedAt); err != nil {
 			return nil, err
 		}
 		user.Name = name
@@ -94,6 +100,26 @@ func (r *AdminRepository) ListUsers(ctx context.Context, limit, offset int) ([]A
 	return users, nil
 }
 
+// UnlockUser сбрасывает счетчик неудачных входов пользователя и снимает блокировку.
+func (r *AdminRepository) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE users
+		 SET failed_login_attempts = 0,
+		     last_failed_login_at = NULL,
+		     locked_until = NULL
+		 WHERE id = $1`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 // CountUsers возвращает общее количество пользователей.
 func (r *AdminRepository) CountUsers(ctx context.Context) (int, error) {
 	var count int
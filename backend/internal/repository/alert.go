@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+const maxAlertRules = 20
+
+type AlertRuleInput struct {
+	RuleType         models.AlertRuleType
+	ThresholdPercent int
+	CategoryID       *uuid.UUID
+}
+
+// defaultAlertRules добавляются к каждому новому плану.
+var defaultAlertRules = []AlertRuleInput{
+	{RuleType: models.AlertRulePlanTotal, ThresholdPercent: 50},
+	{RuleType: models.AlertRulePlanTotal, ThresholdPercent: 80},
+	{RuleType: models.AlertRulePlanTotal, ThresholdPercent: 100},
+	{RuleType: models.AlertRuleCategoryShare, ThresholdPercent: 100},
+}
+
+// ListAlertRules возвращает правила оповещений плана. Доступно любому участнику.
+func (r *PlanRepository) ListAlertRules(ctx context.Context, userID, planID uuid.UUID) ([]models.AlertRule, error) {
+	if _, err := authorizePlan(ctx, r.db, planByID, planID, userID, memberRoles); err != nil {
+		return nil, err
+	}
+
+	return r.listAlertRules(ctx, planID)
+}
+
+// ReplaceAlertRules заменяет набор правил оповещений плана. Сработавшие
+// оповещения сбрасываются вместе со старыми правилами.
+func (r *PlanRepository) ReplaceAlertRules(ctx context.Context, userID, planID uuid.UUID, rules []AlertRuleInput) ([]models.AlertRule, error) {
+	if len(rules) > maxAlertRules {
+		return nil, ErrInvalid
+	}
+
+	for _, rule := range rules {
+		if rule.ThresholdPercent <= 0 || rule.ThresholdPercent > 1000 {
+			return nil, ErrInvalid
+		}
+		switch rule.RuleType {
+		case models.AlertRulePlanTotal:
+			if rule.CategoryID != nil {
+				return nil, ErrInvalid
+			}
+		case models.AlertRuleCategoryShare:
+		default:
+			return nil, ErrInvalid
+		}
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err = authorizePlan(ctx, tx, planByID, planID, userID, editorRoles); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if rule.CategoryID == nil {
+			continue
+		}
+		if err = ensureCategoryInPlan(ctx, tx, *rule.CategoryID, planID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, ErrInvalid
+			}
+			return nil, err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM budget_alert_rules WHERE plan_id = $1`, planID); err != nil {
+		return nil, err
+	}
+
+	if err = insertAlertRules(ctx, tx, planID, rules); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.listAlertRules(ctx, planID)
+}
+
+// EvaluateAlerts проверяет правила плана по текущим тратам и возвращает
+// только впервые сработавшие оповещения.
+func (r *PlanRepository) EvaluateAlerts(ctx context.Context, planID uuid.UUID) ([]models.BudgetAlert, error) {
+	rules, err := r.listAlertRules(ctx, planID)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	var budgetCents int64
+	err = r.db.QueryRow(ctx, `SELECT budget_cents FROM budget_plans WHERE id = $1`, planID).Scan(&budgetCents)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	spentCents, err := r.GetSpentCents(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := listCategorySpending(ctx, r.db, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	fired := make([]models.BudgetAlert, 0)
+	for _, alert := range crossedAlerts(planID, budgetCents, spentCents, categories, rules) {
+		scopeID := planID
+		if alert.CategoryID != nil {
+			scopeID = *alert.CategoryID
+		}
+
+		cmd, err := r.db.Exec(ctx,
+			`INSERT INTO budget_alert_triggers (rule_id, scope_id)
+			 VALUES ($1, $2)
+			 ON CONFLICT DO NOTHING`,
+			alert.RuleID, scopeID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if cmd.RowsAffected() == 1 {
+			fired = append(fired, alert)
+		}
+	}
+
+	return fired, nil
+}
+
+// crossedAlerts возвращает оповещения, пороги которых достигнуты. Правило plan_total
+// срабатывает, когда траты плана достигают доли бюджета; category_share — когда траты
+// категории превышают долю ее плановой суммы (для всех категорий, если категория не задана).
+func crossedAlerts(planID uuid.UUID, budgetCents, spentCents int64, categories []CategorySpend, rules []models.AlertRule) []models.BudgetAlert {
+	alerts := make([]models.BudgetAlert, 0)
+
+	for _, rule := range rules {
+		switch rule.RuleType {
+		case models.AlertRulePlanTotal:
+			if budgetCents <= 0 {
+				continue
+			}
+			limit := percentOf(budgetCents, rule.ThresholdPercent)
+			if spentCents >= limit {
+				alerts = append(alerts, models.BudgetAlert{
+					RuleID:           rule.ID,
+					PlanID:           planID,
+					RuleType:         rule.RuleType,
+					ThresholdPercent: rule.ThresholdPercent,
+					SpentCents:       spentCents,
+					LimitCents:       limit,
+				})
+			}
+		case models.AlertRuleCategoryShare:
+			for _, category := range categories {
+				if rule.CategoryID != nil && *rule.CategoryID != category.CategoryID {
+					continue
+				}
+				if category.PlannedCents <= 0 {
+					continue
+				}
+				limit := percentOf(category.PlannedCents, rule.ThresholdPercent)
+				if category.SpentCents > limit {
+					categoryID := category.CategoryID
+					title := category.Title
+					alerts = append(alerts, models.BudgetAlert{
+						RuleID:           rule.ID,
+						PlanID:           planID,
+						RuleType:         rule.RuleType,
+						ThresholdPercent: rule.ThresholdPercent,
+						CategoryID:       &categoryID,
+						CategoryTitle:    &title,
+						SpentCents:       category.SpentCents,
+						LimitCents:       limit,
+					})
+				}
+			}
+		}
+	}
+
+	return alerts
+}
+
+func percentOf(amount int64, percent int) int64 {
+	return amount * int64(percent) / 100
+}
+
+func (r *PlanRepository) listAlertRules(ctx context.Context, planID uuid.UUID) ([]models.AlertRule, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, plan_id, rule_type, threshold_percent, category_id, created_at
+		 FROM budget_alert_rules
+		 WHERE plan_id = $1
+		 ORDER BY rule_type DESC, threshold_percent, created_at`,
+		planID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]models.AlertRule, 0)
+	for rows.Next() {
+		var rule models.AlertRule
+		if err := rows.Scan(&rule.ID, &rule.PlanID, &rule.RuleType, &rule.ThresholdPercent, &rule.CategoryID, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func insertAlertRules(ctx context.Context, tx pgx.Tx, planID uuid.UUID, rules []AlertRuleInput) error {
+	for _, rule := range rules {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO budget_alert_rules (id, plan_id, rule_type, threshold_percent, category_id)
+			 VALUES ($1, $2, $3, $4, $5)`,
+			uuid.New(), planID, rule.RuleType, rule.ThresholdPercent, rule.CategoryID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyAlertRules копирует правила уровня плана (без привязки к категории) в новый план.
+func copyAlertRules(ctx context.Context, tx pgx.Tx, sourceID, targetID uuid.UUID) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO budget_alert_rules (id, plan_id, rule_type, threshold_percent)
+		 SELECT gen_random_uuid(), $2, rule_type, threshold_percent
+		 FROM budget_alert_rules
+		 WHERE plan_id = $1 AND category_id IS NULL`,
+		sourceID, targetID,
+	)
+	return err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// TestCrossedAlertsPlanTotal проверяет пороги доли бюджета плана.
+func TestCrossedAlertsPlanTotal(t *testing.T) {
+	planID := uuid.New()
+	rules := []models.AlertRule{
+		{ID: uuid.New(), RuleType: models.AlertRulePlanTotal, ThresholdPercent: 50},
+		{ID: uuid.New(), RuleType: models.AlertRulePlanTotal, ThresholdPercent: 80},
+		{ID: uuid.New(), RuleType: models.AlertRulePlanTotal, ThresholdPercent: 100},
+	}
+
+	alerts := crossedAlerts(planID, 100000, 80000, nil, rules)
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %d", len(alerts))
+	}
+	if alerts[0].ThresholdPercent != 50 || alerts[1].ThresholdPercent != 80 {
+		t.Fatalf("unexpected thresholds: %+v", alerts)
+	}
+	if alerts[1].LimitCents != 80000 {
+		t.Fatalf("expected limit 80000, got %d", alerts[1].LimitCents)
+	}
+}
+
+// TestCrossedAlertsCategoryShare проверяет превышение плановой суммы категорий.
+func TestCrossedAlertsCategoryShare(t *testing.T) {
+	planID := uuid.New()
+	food := CategorySpend{CategoryID: uuid.New(), Title: "Еда", PlannedCents: 20000, SpentCents: 25000}
+	rent := CategorySpend{CategoryID: uuid.New(), Title: "Жилье", PlannedCents: 50000, SpentCents: 50000}
+	empty := CategorySpend{CategoryID: uuid.New(), Title: "Другое", PlannedCents: 0, SpentCents: 100}
+
+	anyCategory := models.AlertRule{ID: uuid.New(), RuleType: models.AlertRuleCategoryShare, ThresholdPercent: 100}
+	alerts := crossedAlerts(planID, 100000, 75100, []CategorySpend{food, rent, empty}, []models.AlertRule{anyCategory})
+	if len(alerts) != 1 || *alerts[0].CategoryID != food.CategoryID {
+		t.Fatalf("expected only food alert, got %+v", alerts)
+	}
+
+	rentOnly := models.AlertRule{ID: uuid.New(), RuleType: models.AlertRuleCategoryShare, ThresholdPercent: 90, CategoryID: &rent.CategoryID}
+	alerts = crossedAlerts(planID, 100000, 75100, []CategorySpend{food, rent}, []models.AlertRule{rentOnly})
+	if len(alerts) != 1 || *alerts[0].CategoryID != rent.CategoryID || alerts[0].LimitCents != 45000 {
+		t.Fatalf("expected rent alert with limit 45000, got %+v", alerts)
+	}
+}
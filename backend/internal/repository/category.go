@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+type CategoryRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewCategoryRepository создает репозиторий категорий расходов.
+func NewCategoryRepository(db *pgxpool.Pool) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// GetPlanIDByCategoryID возвращает план, которому принадлежит категория.
+func (r *CategoryRepository) GetPlanIDByCategoryID(ctx context.Context, userID, categoryID uuid.UUID) (uuid.UUID, error) {
+	var planID uuid.UUID
+
+	err := r.db.QueryRow(ctx,
+		`SELECT p.id
+		 FROM expense_categories c
+		 JOIN budget_plans p ON p.id = c.plan_id
+		 WHERE c.id = $1 AND `+memberOf("p.id", "$2"),
+		categoryID, userID,
+	).Scan(&planID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrNotFound
+		}
+		return uuid.Nil, err
+	}
+
+	return planID, nil
+}
+
+// Create добавляет категорию в конец списка категорий плана.
+func (r *CategoryRepository) Create(ctx context.Context, userID, planID uuid.UUID, title string, categoryType models.CategoryType) (models.ExpenseCategory, error) {
+	var category models.ExpenseCategory
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return category, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err = lockPlanBudget(ctx, tx, userID, planID); err != nil {
+		return category, err
+	}
+
+	var maxOrder int
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(MAX(sort_order), -1)
+		 FROM expense_categories
+		 WHERE plan_id = $1`,
+		planID,
+	).Scan(&maxOrder)
+	if err != nil {
+		return category, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO expense_categories (id, plan_id, title, category_type, sort_order)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, plan_id, title, category_type, sort_order, created_at`,
+		uuid.New(), planID, title, categoryType, maxOrder+1,
+	).Scan(&category.ID, &category.PlanID, &category.Title, &category.CategoryType, &category.SortOrder, &category.CreatedAt)
+	if err != nil {
+		return category, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+// Update переименовывает категорию и/или меняет ее тип.
+func (r *CategoryRepository) Update(ctx context.Context, userID, categoryID uuid.UUID, title *string, categoryType *models.CategoryType) (models.ExpenseCategory, error) {
+	var category models.ExpenseCategory
+
+	if _, err := authorizePlan(ctx, r.db, planByCategoryID, categoryID, userID, editorRoles); err != nil {
+		return category, err
+	}
+
+	err := r.db.QueryRow(ctx,
+		`UPDATE expense_categories c
+		 SET title = COALESCE($3, c.title),
+		     category_type = COALESCE($4, c.category_type)
+		 FROM budget_plans p
+		 WHERE c.id = $1
+		   AND c.plan_id = p.id
+		   AND `+editorOf("p.id", "$2")+`
+		 RETURNING c.id, c.plan_id, c.title, c.category_type, c.sort_order, c.created_at`,
+		categoryID, userID, title, categoryType,
+	).Scan(&category.ID, &category.PlanID, &category.Title, &category.CategoryType, &category.SortOrder, &category.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return category, ErrNotFound
+		}
+		return category, err
+	}
+
+	return category, nil
+}
+
+// Delete удаляет категорию. Если задан moveTo, расходы переносятся в указанную
+// категорию того же плана, иначе удаляются вместе с категорией.
+func (r *CategoryRepository) Delete(ctx context.Context, userID, categoryID uuid.UUID, moveTo *uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err = authorizePlan(ctx, tx, planByCategoryID, categoryID, userID, editorRoles); err != nil {
+		return err
+	}
+
+	var planID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`SELECT p.id
+		 FROM expense_categories c
+		 JOIN budget_plans p ON p.id = c.plan_id
+		 WHERE c.id = $1 AND `+editorOf("p.id", "$2")+`
+		 FOR UPDATE OF p`,
+		categoryID, userID,
+	).Scan(&planID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if moveTo != nil {
+		if *moveTo == categoryID {
+			return ErrInvalid
+		}
+
+		if err = ensureCategoryInPlan(ctx, tx, *moveTo, planID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrInvalid
+			}
+			return err
+		}
+
+		var maxOrder int
+		err = tx.QueryRow(ctx,
+			`SELECT COALESCE(MAX(sort_order), -1)
+			 FROM expense_items
+			 WHERE category_id = $1`,
+			*moveTo,
+		).Scan(&maxOrder)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			`UPDATE expense_items
+			 SET category_id = $2,
+			     sort_order = sort_order + $3,
+			     updated_at = NOW()
+			 WHERE category_id = $1`,
+			categoryID, *moveTo, maxOrder+1,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM expense_categories WHERE id = $1`, categoryID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
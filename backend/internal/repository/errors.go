@@ -7,4 +7,5 @@ var (
 	ErrConflict       = errors.New("conflict")
 	ErrInvalid        = errors.New("invalid input")
 	ErrBudgetExceeded = errors.New("budget exceeded")
+	ErrForbidden      = errors.New("forbidden")
 )
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// planRateJoin подключает к плану ps курс его валюты к базовой валюте
+// пользователя u: последний курс, действующий на конец периода плана.
+// Если сохранен только обратный курс, используется 1/rate.
+const planRateJoin = `LEFT JOIN LATERAL (
+			SELECT rates.rate
+			FROM (
+				SELECT rate, effective_date
+				FROM exchange_rates
+				WHERE from_currency = ps.currency AND to_currency = u.base_currency
+				UNION ALL
+				SELECT 1 / rate, effective_date
+				FROM exchange_rates
+				WHERE from_currency = u.base_currency AND to_currency = ps.currency
+			) rates
+			WHERE rates.effective_date <= ps.period_end
+			ORDER BY rates.effective_date DESC
+			LIMIT 1
+		) fx ON TRUE`
+
+// planRateExpr — курс пересчета плана ps в базовую валюту (NULL, если курса нет).
+const planRateExpr = `CASE WHEN ps.currency = u.base_currency THEN 1::numeric ELSE fx.rate END`
+
+type ExchangeRateRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewExchangeRateRepository создает репозиторий курсов валют.
+func NewExchangeRateRepository(db *pgxpool.Pool) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// Upsert сохраняет курсы валют, перезаписывая курсы на ту же дату.
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return ErrInvalid
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	for _, rate := range rates {
+		if rate.Rate <= 0 || rate.FromCurrency == rate.ToCurrency {
+			return ErrInvalid
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO exchange_rates (from_currency, to_currency, rate, effective_date)
+			 VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (from_currency, to_currency, effective_date)
+			 DO UPDATE SET rate = EXCLUDED.rate, created_at = NOW()`,
+			rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.EffectiveDate,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// List возвращает последние сохраненные курсы, при необходимости по одной валюте.
+func (r *ExchangeRateRepository) List(ctx context.Context, currencyCode *string, limit, offset int) ([]models.ExchangeRate, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT from_currency, to_currency, rate::float8, effective_date, created_at
+		 FROM exchange_rates
+		 WHERE $1::text IS NULL OR from_currency = $1 OR to_currency = $1
+		 ORDER BY effective_date DESC, from_currency, to_currency
+		 LIMIT $2 OFFSET $3`,
+		currencyCode, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make([]models.ExchangeRate, 0)
+	for rows.Next() {
+		var rate models.ExchangeRate
+		if err := rows.Scan(&rate.FromCurrency, &rate.ToCurrency, &rate.Rate, &rate.EffectiveDate, &rate.CreatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
@@ -29,7 +29,7 @@ func (r *ItemRepository) GetPlanIDByItemID(ctx context.Context, userID, itemID u
 		 FROM expense_items i
 		 JOIN expense_categories c ON c.id = i.category_id
 		 JOIN budget_plans p ON p.id = c.plan_id
-		 WHERE i.id = $1 AND p.user_id = $2`,
+		 WHERE i.id = $1 AND `+memberOf("p.id", "$2"),
 		itemID, userID,
 	).Scan(&planID)
 	if err != nil {
@@ -42,6 +42,49 @@ func (r *ItemRepository) GetPlanIDByItemID(ctx context.Context, userID, itemID u
 	return planID, nil
 }
 
+// GetByID возвращает расход пользователя по идентификатору.
+func (r *ItemRepository) GetByID(ctx context.Context, userID, itemID uuid.UUID) (models.ExpenseItem, error) {
+	var item models.ExpenseItem
+
+	err := r.db.QueryRow(ctx,
+		`SELECT i.id, i.category_id, i.title, i.amount_cents, i.priority_color, i.is_completed, i.sort_order, i.created_at, i.updated_at
+		 FROM expense_items i
+		 JOIN expense_categories c ON c.id = i.category_id
+		 JOIN budget_plans p ON p.id = c.plan_id
+		 WHERE i.id = $1 AND `+memberOf("p.id", "$2"),
+		itemID, userID,
+	).Scan(&item.ID, &item.CategoryID, &item.Title, &item.AmountCents, &item.PriorityColor, &item.IsCompleted, &item.SortOrder, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return item, ErrNotFound
+		}
+		return item, err
+	}
+
+	return item, nil
+}
+
+// GetSpentCents считает фактические траты по расходу.
+func (r *ItemRepository) GetSpentCents(ctx context.Context, itemID uuid.UUID) (int64, error) {
+	var spent int64
+
+	err := r.db.QueryRow(ctx,
+		`SELECT `+itemSpentExpr+`
+		 FROM expense_items i
+		 `+itemTransactionsJoin+`
+		 WHERE i.id = $1`,
+		itemID,
+	).Scan(&spent)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	return spent, nil
+}
+
 // Create добавляет новый расход с проверкой бюджета.
 func (r *ItemRepository) Create(ctx context.Context, userID, planID, categoryID uuid.UUID, title string, amountCents int64, priorityColor models.PriorityColor, isCompleted bool) (models.ExpenseItem, error) {
 	var item models.ExpenseItem
@@ -114,6 +157,10 @@ func (r *ItemRepository) Update(ctx context.Context, userID, itemID uuid.UUID, t
 		_ = tx.Rollback(ctx)
 	}()
 
+	if _, err = authorizePlan(ctx, tx, planByItemID, itemID, userID, editorRoles); err != nil {
+		return item, err
+	}
+
 	var planID uuid.UUID
 	var budgetCents int64
 	var currentAmount int64
@@ -123,7 +170,7 @@ func (r *ItemRepository) Update(ctx context.Context, userID, itemID uuid.UUID, t
 		 FROM expense_items i
 		 JOIN expense_categories c ON c.id = i.category_id
 		 JOIN budget_plans p ON p.id = c.plan_id
-		 WHERE i.id = $1 AND p.user_id = $2
+		 WHERE i.id = $1 AND `+editorOf("p.id", "$2")+`
 		 FOR UPDATE OF p`,
 		itemID, userID,
 	).Scan(&planID, &budgetCents, &currentAmount)
@@ -167,13 +214,17 @@ func (r *ItemRepository) Update(ctx context.Context, userID, itemID uuid.UUID, t
 
 // Delete удаляет расход пользователя.
 func (r *ItemRepository) Delete(ctx context.Context, userID, itemID uuid.UUID) error {
+	if _, err := authorizePlan(ctx, r.db, planByItemID, itemID, userID, editorRoles); err != nil {
+		return err
+	}
+
 	cmd, err := r.db.Exec(ctx,
 		`DELETE FROM expense_items i
 		 USING expense_categories c, budget_plans p
 		 WHERE i.id = $1
 		   AND i.category_id = c.id
 		   AND c.plan_id = p.id
-		   AND p.user_id = $2`,
+		   AND `+editorOf("p.id", "$2"),
 		itemID, userID,
 	)
 	if err != nil {
@@ -199,13 +250,17 @@ func (r *ItemRepository) Toggle(ctx context.Context, userID, itemID uuid.UUID, i
 		_ = tx.Rollback(ctx)
 	}()
 
+	if _, err = authorizePlan(ctx, tx, planByItemID, itemID, userID, editorRoles); err != nil {
+		return item, err
+	}
+
 	var current bool
 	err = tx.QueryRow(ctx,
 		`SELECT i.is_completed
 		 FROM expense_items i
 		 JOIN expense_categories c ON c.id = i.category_id
 		 JOIN budget_plans p ON p.id = c.plan_id
-		 WHERE i.id = $1 AND p.user_id = $2
+		 WHERE i.id = $1 AND `+editorOf("p.id", "$2")+`
 		 FOR UPDATE`,
 		itemID, userID,
 	).Scan(&current)
@@ -246,6 +301,10 @@ func (r *ItemRepository) Toggle(ctx context.Context, userID, itemID uuid.UUID, i
 func (r *ItemRepository) UpdateColor(ctx context.Context, userID, itemID uuid.UUID, priorityColor models.PriorityColor) (models.ExpenseItem, error) {
 	var item models.ExpenseItem
 
+	if _, err := authorizePlan(ctx, r.db, planByItemID, itemID, userID, editorRoles); err != nil {
+		return item, err
+	}
+
 	err := r.db.QueryRow(ctx,
 		`UPDATE expense_items i
 		 SET priority_color = $3,
@@ -254,7 +313,7 @@ func (r *ItemRepository) UpdateColor(ctx context.Context, userID, itemID uuid.UU
 		 JOIN budget_plans p ON p.id = c.plan_id
 		 WHERE i.id = $1
 		   AND i.category_id = c.id
-		   AND p.user_id = $2
+		   AND `+editorOf("p.id", "$2")+`
 		 RETURNING i.id, i.category_id, i.title, i.amount_cents, i.priority_color, i.is_completed, i.sort_order, i.created_at, i.updated_at`,
 		itemID, userID, priorityColor,
 	).Scan(&item.ID, &item.CategoryID, &item.Title, &item.AmountCents, &item.PriorityColor, &item.IsCompleted, &item.SortOrder, &item.CreatedAt, &item.UpdatedAt)
@@ -282,13 +341,17 @@ func (r *ItemRepository) Reorder(ctx context.Context, userID, itemID uuid.UUID,
 		_ = tx.Rollback(ctx)
 	}()
 
+	if _, err = authorizePlan(ctx, tx, planByItemID, itemID, userID, editorRoles); err != nil {
+		return err
+	}
+
 	var categoryID uuid.UUID
 	err = tx.QueryRow(ctx,
 		`SELECT c.id
 		 FROM expense_items i
 		 JOIN expense_categories c ON c.id = i.category_id
 		 JOIN budget_plans p ON p.id = c.plan_id
-		 WHERE i.id = $1 AND p.user_id = $2`,
+		 WHERE i.id = $1 AND `+editorOf("p.id", "$2"),
 		itemID, userID,
 	).Scan(&categoryID)
 	if err != nil {
@@ -346,14 +409,20 @@ func (r *ItemRepository) Reorder(ctx context.Context, userID, itemID uuid.UUID,
 	return tx.Commit(ctx)
 }
 
+// lockPlanBudget проверяет право пользователя изменять план, блокирует план
+// до конца транзакции и возвращает его бюджет.
 func lockPlanBudget(ctx context.Context, tx pgx.Tx, userID, planID uuid.UUID) (int64, error) {
+	if _, err := authorizePlan(ctx, tx, planByID, planID, userID, editorRoles); err != nil {
+		return 0, err
+	}
+
 	var budgetCents int64
 	if err := tx.QueryRow(ctx,
 		`SELECT budget_cents
 		 FROM budget_plans
-		 WHERE id = $1 AND user_id = $2
+		 WHERE id = $1
 		 FOR UPDATE`,
-		planID, userID,
+		planID,
 	).Scan(&budgetCents); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, ErrNotFound
@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// GetRole возвращает роль пользователя в плане.
+func (r *PlanRepository) GetRole(ctx context.Context, userID, planID uuid.UUID) (models.PlanRole, error) {
+	var role models.PlanRole
+
+	err := r.db.QueryRow(ctx,
+		`SELECT role
+		 FROM plan_members
+		 WHERE plan_id = $1 AND user_id = $2`,
+		planID, userID,
+	).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+// ListMemberIDs возвращает идентификаторы всех участников плана.
+func (r *PlanRepository) ListMemberIDs(ctx context.Context, planID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT user_id
+		 FROM plan_members
+		 WHERE plan_id = $1`,
+		planID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+type MemberRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewMemberRepository создает репозиторий участников планов и приглашений.
+func NewMemberRepository(db *pgxpool.Pool) *MemberRepository {
+	return &MemberRepository{db: db}
+}
+
+// List возвращает участников плана. Доступно любому участнику.
+func (r *MemberRepository) List(ctx context.Context, userID, planID uuid.UUID) ([]models.PlanMember, error) {
+	if _, err := authorizePlan(ctx, r.db, planByID, planID, userID, memberRoles); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT m.plan_id, m.user_id, u.email, u.name, m.role, m.created_at
+		 FROM plan_members m
+		 JOIN users u ON u.id = m.user_id
+		 WHERE m.plan_id = $1
+		 ORDER BY m.role = 'owner' DESC, m.created_at`,
+		planID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]models.PlanMember, 0)
+	for rows.Next() {
+		var member models.PlanMember
+		if err := rows.Scan(&member.PlanID, &member.UserID, &member.Email, &member.Name, &member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// UpdateRole меняет роль участника. Доступно только владельцу; роль владельца
+// не передается и не назначается.
+func (r *MemberRepository) UpdateRole(ctx context.Context, userID, planID, memberID uuid.UUID, role models.PlanRole) (models.PlanMember, error) {
+	var member models.PlanMember
+
+	if role == models.PlanRoleOwner {
+		return member, ErrInvalid
+	}
+
+	if _, err := authorizePlan(ctx, r.db, planByID, planID, userID, ownerRoles); err != nil {
+		return member, err
+	}
+
+	err := r.db.QueryRow(ctx,
+		`UPDATE plan_members m
+		 SET role = $3
+		 FROM users u
+		 WHERE m.plan_id = $1
+		   AND m.user_id = $2
+		   AND m.role <> 'owner'
+		   AND u.id = m.user_id
+		 RETURNING m.plan_id, m.user_id, u.email, u.name, m.role, m.created_at`,
+		planID, memberID, role,
+	).Scan(&member.PlanID, &member.UserID, &member.Email, &member.Name, &member.Role, &member.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return member, ErrNotFound
+		}
+		return member, err
+	}
+
+	return member, nil
+}
+
+// Remove исключает участника из плана. Владелец может исключить любого
+// участника, кроме себя; остальные участники могут только выйти сами.
+func (r *MemberRepository) Remove(ctx context.Context, userID, planID, memberID uuid.UUID) error {
+	allowed := ownerRoles
+	if memberID == userID {
+		allowed = []models.PlanRole{models.PlanRoleEditor, models.PlanRoleViewer}
+	}
+
+	if _, err := authorizePlan(ctx, r.db, planByID, planID, userID, allowed); err != nil {
+		return err
+	}
+
+	cmd, err := r.db.Exec(ctx,
+		`DELETE FROM plan_members
+		 WHERE plan_id = $1 AND user_id = $2 AND role <> 'owner'`,
+		planID, memberID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateInvite создает приглашение в план по email. Доступно только владельцу.
+// Предыдущее непринятое приглашение на тот же email заменяется новым.
+// Возвращает ErrConflict, если пользователь с таким email уже участвует в плане.
+func (r *MemberRepository) CreateInvite(ctx context.Context, userID, planID uuid.UUID, email string, role models.PlanRole, tokenHash string, expiresAt time.Time) (models.PlanInvite, error) {
+	var invite models.PlanInvite
+
+	if role != models.PlanRoleEditor && role != models.PlanRoleViewer {
+		return invite, ErrInvalid
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return invite, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err = authorizePlan(ctx, tx, planByID, planID, userID, ownerRoles); err != nil {
+		return invite, err
+	}
+
+	var isMember bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS (
+			SELECT 1
+			FROM plan_members m
+			JOIN users u ON u.id = m.user_id
+			WHERE m.plan_id = $1 AND lower(u.email) = lower($2)
+		 )`,
+		planID, email,
+	).Scan(&isMember)
+	if err != nil {
+		return invite, err
+	}
+	if isMember {
+		return invite, ErrConflict
+	}
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM plan_invites
+		 WHERE plan_id = $1 AND lower(email) = lower($2) AND accepted_at IS NULL`,
+		planID, email,
+	)
+	if err != nil {
+		return invite, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO plan_invites (id, plan_id, email, role, token_hash, invited_by, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING id, plan_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at`,
+		uuid.New(), planID, email, role, tokenHash, userID, expiresAt,
+	).Scan(&invite.ID, &invite.PlanID, &invite.Email, &invite.Role, &invite.TokenHash, &invite.InvitedBy, &invite.ExpiresAt, &invite.AcceptedAt, &invite.CreatedAt)
+	if err != nil {
+		return invite, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return invite, err
+	}
+
+	return invite, nil
+}
+
+// AcceptInvite принимает приглашение по хэшу токена и добавляет пользователя в план.
+// Приглашение действительно только для пользователя с тем же email.
+func (r *MemberRepository) AcceptInvite(ctx context.Context, userID uuid.UUID, tokenHash string) (models.PlanMember, error) {
+	var member models.PlanMember
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return member, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var invite models.PlanInvite
+	err = tx.QueryRow(ctx,
+		`SELECT id, plan_id, email, role
+		 FROM plan_invites
+		 WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		 FOR UPDATE`,
+		tokenHash,
+	).Scan(&invite.ID, &invite.PlanID, &invite.Email, &invite.Role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return member, ErrNotFound
+		}
+		return member, err
+	}
+
+	var emailMatches bool
+	err = tx.QueryRow(ctx,
+		`SELECT lower(email) = lower($2) FROM users WHERE id = $1`,
+		userID, invite.Email,
+	).Scan(&emailMatches)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return member, ErrNotFound
+		}
+		return member, err
+	}
+	if !emailMatches {
+		return member, ErrForbidden
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO plan_members (plan_id, user_id, role)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (plan_id, user_id) DO NOTHING`,
+		invite.PlanID, userID, invite.Role,
+	)
+	if err != nil {
+		return member, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE plan_invites
+		 SET accepted_at = NOW()
+		 WHERE id = $1`,
+		invite.ID,
+	)
+	if err != nil {
+		return member, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`SELECT m.plan_id, m.user_id, u.email, u.name, m.role, m.created_at
+		 FROM plan_members m
+		 JOIN users u ON u.id = m.user_id
+		 WHERE m.plan_id = $1 AND m.user_id = $2`,
+		invite.PlanID, userID,
+	).Scan(&member.PlanID, &member.UserID, &member.Email, &member.Name, &member.Role, &member.CreatedAt)
+	if err != nil {
+		return member, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return member, err
+	}
+
+	return member, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// SetPendingTOTP сохраняет секрет TOTP, который еще нужно подтвердить кодом.
+// Возвращает ErrConflict, если второй фактор уже включен.
+func (r *UserRepository) SetPendingTOTP(ctx context.Context, userID uuid.UUID, secret string) error {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE users
+		 SET totp_secret = $2,
+		     totp_last_counter = NULL,
+		     updated_at = NOW()
+		 WHERE id = $1 AND totp_enabled_at IS NULL`,
+		userID, secret,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// EnableTOTP включает второй фактор с подтвержденным кодом counter и заменяет
+// коды восстановления на recoveryHashes.
+func (r *UserRepository) EnableTOTP(ctx context.Context, userID uuid.UUID, counter int64, recoveryHashes []string) (models.User, error) {
+	var user models.User
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return user, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = tx.QueryRow(ctx,
+		`UPDATE users
+		 SET totp_enabled_at = NOW(),
+		     totp_last_counter = $2,
+		     updated_at = NOW()
+		 WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+		 RETURNING `+userColumns,
+		userID, counter,
+	).Scan(userScanDest(&user)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, ErrConflict
+		}
+		return user, err
+	}
+
+	if err = replaceRecoveryCodes(ctx, tx, userID, recoveryHashes); err != nil {
+		return user, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// DisableTOTP выключает второй фактор и удаляет коды восстановления.
+func (r *UserRepository) DisableTOTP(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx,
+		`UPDATE users
+		 SET totp_secret = NULL,
+		     totp_enabled_at = NULL,
+		     totp_last_counter = NULL,
+		     updated_at = NOW()
+		 WHERE id = $1`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseTOTPCounter запоминает интервал принятого кода. Возвращает ErrConflict,
+// если код этого или более позднего интервала уже использован.
+func (r *UserRepository) UseTOTPCounter(ctx context.Context, userID uuid.UUID, counter int64) error {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE users
+		 SET totp_last_counter = $2
+		 WHERE id = $1
+		   AND totp_enabled_at IS NOT NULL
+		   AND (totp_last_counter IS NULL OR totp_last_counter < $2)`,
+		userID, counter,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// ConsumeRecoveryCode погашает неиспользованный код восстановления.
+func (r *UserRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE user_recovery_codes
+		 SET used_at = NOW()
+		 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes заменяет все коды восстановления пользователя.
+func (r *UserRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err = replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CountRecoveryCodes возвращает число неиспользованных кодов восстановления.
+func (r *UserRepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*)
+		 FROM user_recovery_codes
+		 WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx,
+		`INSERT INTO user_recovery_codes (user_id, code_hash)
+		 SELECT $1, unnest($2::text[])`,
+		userID, codeHashes,
+	)
+	return err
+}
@@ -25,7 +25,7 @@ func (r *NoteRepository) ListByPlan(ctx context.Context, userID, planID uuid.UUI
 	var exists bool
 	if err := r.db.QueryRow(ctx,
 		`SELECT EXISTS (
-			SELECT 1 FROM budget_plans WHERE id = $1 AND user_id = $2
+			SELECT 1 FROM plan_members WHERE plan_id = $1 AND user_id = $2
 		 )`,
 		planID, userID,
 	).Scan(&exists); err != nil {
@@ -72,7 +72,7 @@ func (r *NoteRepository) ListByPlanAndType(ctx context.Context, userID, planID u
 	var exists bool
 	if err := r.db.QueryRow(ctx,
 		`SELECT EXISTS (
-			SELECT 1 FROM budget_plans WHERE id = $1 AND user_id = $2
+			SELECT 1 FROM plan_members WHERE plan_id = $1 AND user_id = $2
 		 )`,
 		planID, userID,
 	).Scan(&exists); err != nil {
@@ -126,20 +126,10 @@ func (r *NoteRepository) Create(ctx context.Context, userID, planID uuid.UUID, c
 		_ = tx.Rollback(ctx)
 	}()
 
-	var exists bool
-	if err := tx.QueryRow(ctx,
-		`SELECT EXISTS (
-			SELECT 1 FROM budget_plans WHERE id = $1 AND user_id = $2
-		 )`,
-		planID, userID,
-	).Scan(&exists); err != nil {
+	if _, err = authorizePlan(ctx, tx, planByID, planID, userID, editorRoles); err != nil {
 		return note, err
 	}
 
-	if !exists {
-		return note, ErrNotFound
-	}
-
 	var maxOrder int
 	err = tx.QueryRow(ctx,
 		`SELECT COALESCE(MAX(sort_order), -1)
@@ -172,12 +162,16 @@ func (r *NoteRepository) Create(ctx context.Context, userID, planID uuid.UUID, c
 
 // DeleteByPlanAndType удаляет заметки плана по типу.
 func (r *NoteRepository) DeleteByPlanAndType(ctx context.Context, userID, planID uuid.UUID, noteType models.NoteType) error {
+	if _, err := authorizePlan(ctx, r.db, planByID, planID, userID, editorRoles); err != nil {
+		return err
+	}
+
 	cmd, err := r.db.Exec(ctx,
 		`DELETE FROM notes n
 		 USING budget_plans p
 		 WHERE n.plan_id = p.id
 		   AND p.id = $1
-		   AND p.user_id = $2
+		   AND `+editorOf("p.id", "$2")+`
 		   AND n.note_type = $3`,
 		planID, userID, noteType,
 	)
@@ -196,6 +190,10 @@ func (r *NoteRepository) DeleteByPlanAndType(ctx context.Context, userID, planID
 func (r *NoteRepository) Update(ctx context.Context, userID, noteID uuid.UUID, content string, noteType models.NoteType) (models.Note, error) {
 	var note models.Note
 
+	if _, err := authorizePlan(ctx, r.db, planByNoteID, noteID, userID, editorRoles); err != nil {
+		return note, err
+	}
+
 	err := r.db.QueryRow(ctx,
 		`UPDATE notes n
 		 SET content = $2,
@@ -204,7 +202,7 @@ func (r *NoteRepository) Update(ctx context.Context, userID, noteID uuid.UUID, c
 		 FROM budget_plans p
 		 WHERE n.id = $1
 		   AND n.plan_id = p.id
-		   AND p.user_id = $4
+		   AND `+editorOf("p.id", "$4")+`
 		 RETURNING n.id, n.plan_id, n.content, n.note_type, n.sort_order, n.created_at, n.updated_at`,
 		noteID, content, noteType, userID,
 	).Scan(&note.ID, &note.PlanID, &note.Content, &note.NoteType, &note.SortOrder, &note.CreatedAt, &note.UpdatedAt)
@@ -220,12 +218,16 @@ func (r *NoteRepository) Update(ctx context.Context, userID, noteID uuid.UUID, c
 
 // Delete удаляет заметку.
 func (r *NoteRepository) Delete(ctx context.Context, userID, noteID uuid.UUID) error {
+	if _, err := authorizePlan(ctx, r.db, planByNoteID, noteID, userID, editorRoles); err != nil {
+		return err
+	}
+
 	cmd, err := r.db.Exec(ctx,
 		`DELETE FROM notes n
 		 USING budget_plans p
 		 WHERE n.id = $1
 		   AND n.plan_id = p.id
-		   AND p.user_id = $2`,
+		   AND `+editorOf("p.id", "$2"),
 		noteID, userID,
 	)
 	if err != nil {
@@ -253,12 +255,16 @@ func (r *NoteRepository) Reorder(ctx context.Context, userID, noteID uuid.UUID,
 		_ = tx.Rollback(ctx)
 	}()
 
+	if _, err = authorizePlan(ctx, tx, planByNoteID, noteID, userID, editorRoles); err != nil {
+		return err
+	}
+
 	var planID uuid.UUID
 	err = tx.QueryRow(ctx,
 		`SELECT n.plan_id
 		 FROM notes n
 		 JOIN budget_plans p ON p.id = n.plan_id
-		 WHERE n.id = $1 AND p.user_id = $2`,
+		 WHERE n.id = $1 AND `+editorOf("p.id", "$2"),
 		noteID, userID,
 	).Scan(&planID)
 	if err != nil {
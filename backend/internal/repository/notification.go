@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/notifications"
+)
+
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewNotificationRepository создает журнал SSE-событий пользователей.
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Append сохраняет событие и возвращает его с присвоенным ID.
+func (r *NotificationRepository) Append(ctx context.Context, userID uuid.UUID, event notifications.Event) (notifications.Event, error) {
+	var payload []byte
+	if event.Data != nil {
+		var err error
+		payload, err = json.Marshal(event.Data)
+		if err != nil {
+			return event, err
+		}
+	}
+
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO notification_events (user_id, event_type, payload, created_at)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id`,
+		userID, event.Type, payload, event.Timestamp,
+	).Scan(&event.ID)
+	if err != nil {
+		return event, err
+	}
+
+	return event, nil
+}
+
+// ListAfter возвращает события пользователя с ID больше afterID, а также
+// события с меньшим ID, созданные не раньше чем за overlap до события afterID, в порядке ID. Окно
+// нужно потому, что BIGSERIAL выдает ID при вставке: транзакция с меньшим ID
+// может закоммититься позже той, чье событие клиент уже получил. Если события
+// afterID уже нет в журнале, окно не применяется.
+func (r *NotificationRepository) ListAfter(ctx context.Context, userID uuid.UUID, afterID int64, overlap time.Duration, limit int) ([]notifications.Event, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, event_type, payload, created_at
+		 FROM notification_events
+		 WHERE user_id = $1
+		   AND (id > $2 OR id < $2 AND created_at >= (
+		     SELECT created_at - make_interval(secs => $3)
+		     FROM notification_events
+		     WHERE user_id = $1 AND id = $2
+		   ))
+		 ORDER BY id
+		 LIMIT $4`,
+		userID, afterID, overlap.Seconds(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]notifications.Event, 0)
+	for rows.Next() {
+		var event notifications.Event
+		var payload []byte
+
+		if err := rows.Scan(&event.ID, &event.Type, &payload, &event.Timestamp); err != nil {
+			return nil, err
+		}
+		if len(payload) > 0 {
+			event.Data = json.RawMessage(payload)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// DeleteBefore удаляет события старше cutoff и возвращает число удаленных.
+func (r *NotificationRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	cmd, err := r.db.Exec(ctx,
+		`DELETE FROM notification_events
+		 WHERE created_at < $1`,
+		cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
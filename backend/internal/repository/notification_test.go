@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/notifications"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestNotificationListAfterOverlap проверяет, что вместе с событиями после
+// Last-Event-ID возвращаются события с меньшим ID из окна перед ним.
+func TestNotificationListAfterOverlap(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	repo := NewNotificationRepository(db)
+
+	user, err := NewUserRepository(db).Create(ctx, "events@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	now := time.Now().UTC()
+	appendAt := func(eventType string, at time.Time) notifications.Event {
+		t.Helper()
+
+		event, err := repo.Append(ctx, user.ID, notifications.Event{Type: eventType, Timestamp: at})
+		if err != nil {
+			t.Fatalf("append %s: %v", eventType, err)
+		}
+		return event
+	}
+
+	appendAt("old", now.Add(-time.Hour))
+	late := appendAt("late", now.Add(-time.Second))
+	last := appendAt("last", now)
+	next := appendAt("next", now.Add(time.Second))
+
+	events, err := repo.ListAfter(ctx, user.ID, last.ID, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("list after: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != late.ID || events[1].ID != next.ID {
+		t.Fatalf("expected late and next events, got %+v", events)
+	}
+
+	events, err = repo.ListAfter(ctx, user.ID, next.ID+1000, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("list after missing id: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events after unknown id, got %+v", events)
+	}
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+type OIDCRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewOIDCRepository создает репозиторий входа через OpenID Connect.
+func NewOIDCRepository(db *pgxpool.Pool) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// CreateState сохраняет параметры начатого входа и удаляет истекшие.
+func (r *OIDCRepository) CreateState(ctx context.Context, stateHash, codeVerifier, nonce string, expiresAt time.Time) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM oidc_login_states WHERE expires_at <= NOW()`); err != nil {
+		return err
+	}
+
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO oidc_login_states (state_hash, code_verifier, nonce, expires_at)
+		 VALUES ($1, $2, $3, $4)`,
+		stateHash, codeVerifier, nonce, expiresAt,
+	)
+	return err
+}
+
+// ConsumeState удаляет действующий state и возвращает code_verifier и nonce.
+// Повторное использование state возвращает ErrNotFound.
+func (r *OIDCRepository) ConsumeState(ctx context.Context, stateHash string) (string, string, error) {
+	var codeVerifier, nonce string
+
+	err := r.db.QueryRow(ctx,
+		`DELETE FROM oidc_login_states
+		 WHERE state_hash = $1 AND expires_at > NOW()
+		 RETURNING code_verifier, nonce`,
+		stateHash,
+	).Scan(&codeVerifier, &nonce)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", ErrNotFound
+		}
+		return "", "", err
+	}
+
+	return codeVerifier, nonce, nil
+}
+
+// LoginIdentity возвращает пользователя, привязанного к учетной записи
+// провайдера (issuer, subject). Если привязки нет, учетная запись связывается
+// с пользователем с тем же email или создается пользователь без пароля.
+// Связать по email можно только подтвержденный провайдером адрес, иначе
+// возвращается ErrForbidden.
+func (r *OIDCRepository) LoginIdentity(ctx context.Context, issuer, subject, email string, emailVerified bool, name *string) (models.User, error) {
+	var user models.User
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return user, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var userID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`UPDATE user_identities
+		 SET email = $3,
+		     last_login_at = NOW()
+		 WHERE issuer = $1 AND subject = $2
+		 RETURNING user_id`,
+		issuer, subject, email,
+	).Scan(&userID)
+	switch {
+	case err == nil:
+		err = tx.QueryRow(ctx,
+			`SELECT `+userColumns+`
+			 FROM users
+			 WHERE id = $1`,
+			userID,
+		).Scan(userScanDest(&user)...)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return user, ErrNotFound
+			}
+			return user, err
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		if !emailVerified || email == "" {
+			return user, ErrForbidden
+		}
+
+		// Пароль неподтвержденной учетной записи мог задать не владелец адреса,
+		// поэтому при привязке он сбрасывается.
+		err = tx.QueryRow(ctx,
+			`INSERT INTO users (email, password_hash, name, email_verified_at)
+			 VALUES ($1, '', $2, NOW())
+			 ON CONFLICT (email) DO UPDATE
+			 SET password_hash = CASE WHEN users.email_verified_at IS NULL THEN '' ELSE users.password_hash END,
+			     email_verified_at = COALESCE(users.email_verified_at, NOW()),
+			     updated_at = NOW()
+			 RETURNING `+userColumns,
+			email, name,
+		).Scan(userScanDest(&user)...)
+		if err != nil {
+			return user, err
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO user_identities (user_id, issuer, subject, email)
+			 VALUES ($1, $2, $3, $4)`,
+			user.ID, issuer, subject, email,
+		)
+		if err != nil {
+			return user, err
+		}
+	default:
+		return user, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// personalTokenColumns перечисляет колонки personal_access_tokens в порядке personalTokenScanDest.
+const personalTokenColumns = `id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func personalTokenScanDest(token *models.PersonalAccessToken) []any {
+	return []any{&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, &token.TokenHash, &token.Scopes,
+		&token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt, &token.CreatedAt}
+}
+
+// lastUsedResolution — как часто обновляется last_used_at, чтобы не писать в базу на каждый запрос.
+const lastUsedResolution = time.Minute
+
+type PersonalTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPersonalTokenRepository создает репозиторий персональных токенов доступа.
+func NewPersonalTokenRepository(db *pgxpool.Pool) *PersonalTokenRepository {
+	return &PersonalTokenRepository{db: db}
+}
+
+// Create сохраняет хэш нового персонального токена.
+func (r *PersonalTokenRepository) Create(ctx context.Context, token models.PersonalAccessToken) (models.PersonalAccessToken, error) {
+	var created models.PersonalAccessToken
+
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO personal_access_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+personalTokenColumns,
+		token.UserID, token.Name, token.TokenPrefix, token.TokenHash, token.Scopes, token.ExpiresAt,
+	).Scan(personalTokenScanDest(&created)...)
+	if err != nil {
+		return created, err
+	}
+
+	return created, nil
+}
+
+// CountActive возвращает число неотозванных токенов пользователя.
+func (r *PersonalTokenRepository) CountActive(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*)
+		 FROM personal_access_tokens
+		 WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+// ListByUser возвращает неотозванные токены пользователя.
+func (r *PersonalTokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+personalTokenColumns+`
+		 FROM personal_access_tokens
+		 WHERE user_id = $1 AND revoked_at IS NULL
+		 ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]models.PersonalAccessToken, 0)
+	for rows.Next() {
+		var token models.PersonalAccessToken
+		if err := rows.Scan(personalTokenScanDest(&token)...); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Revoke отзывает токен пользователя.
+func (r *PersonalTokenRepository) Revoke(ctx context.Context, userID, tokenID uuid.UUID) error {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE personal_access_tokens
+		 SET revoked_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		tokenID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AuthenticatePersonalToken находит действующий токен по хэшу и отмечает его
+// использование. Реализует auth.PersonalTokenAuthenticator.
+func (r *PersonalTokenRepository) AuthenticatePersonalToken(ctx context.Context, tokenHash string) (uuid.UUID, []string, error) {
+	var token models.PersonalAccessToken
+
+	err := r.db.QueryRow(ctx,
+		`SELECT `+personalTokenColumns+`
+		 FROM personal_access_tokens
+		 WHERE token_hash = $1
+		   AND revoked_at IS NULL
+		   AND (expires_at IS NULL OR expires_at > NOW())`,
+		tokenHash,
+	).Scan(personalTokenScanDest(&token)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, nil, ErrNotFound
+		}
+		return uuid.Nil, nil, err
+	}
+
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) >= lastUsedResolution {
+		_, err = r.db.Exec(ctx,
+			`UPDATE personal_access_tokens
+			 SET last_used_at = NOW()
+			 WHERE id = $1`,
+			token.ID,
+		)
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+	}
+
+	return token.UserID, token.Scopes, nil
+}
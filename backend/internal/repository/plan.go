@@ -53,6 +53,29 @@ var defaultCategories = []defaultCategory{
 type PlanWithSpent struct {
 	Plan       models.BudgetPlan
 	SpentCents int64
+	Role       models.PlanRole
+}
+
+// planColumns перечисляет колонки budget_plans в порядке planScanDest.
+const planColumns = `id, user_id, title, budget_cents, currency, period_start, period_end, background_color, is_ai_generated,
+		recurrence, recurrence_interval_days, carry_over, next_plan_id, created_at, updated_at`
+
+// qualifiedPlanColumns — то же, что planColumns, для запросов с алиасом p.
+const qualifiedPlanColumns = `p.id, p.user_id, p.title, p.budget_cents, p.currency, p.period_start, p.period_end,
+		        p.background_color, p.is_ai_generated, p.recurrence, p.recurrence_interval_days,
+		        p.carry_over, p.next_plan_id, p.created_at, p.updated_at`
+
+// userTodayExpr — текущая дата в часовом поясе пользователя $1. По ней планы
+// делятся на активные и архивные.
+const userTodayExpr = `(NOW() AT TIME ZONE (SELECT time_zone FROM users WHERE id = $1))::date`
+
+// planScanDest возвращает поля плана для Scan в порядке planColumns.
+func planScanDest(plan *models.BudgetPlan) []any {
+	return []any{
+		&plan.ID, &plan.UserID, &plan.Title, &plan.BudgetCents, &plan.Currency, &plan.PeriodStart, &plan.PeriodEnd,
+		&plan.BackgroundColor, &plan.IsAIGenerated, &plan.Recurrence, &plan.RecurrenceDays,
+		&plan.CarryOver, &plan.NextPlanID, &plan.CreatedAt, &plan.UpdatedAt,
+	}
 }
 
 // NewPlanRepository создает репозиторий планов бюджета.
@@ -60,8 +83,9 @@ func NewPlanRepository(db *pgxpool.Pool) *PlanRepository {
 	return &PlanRepository{db: db}
 }
 
-// Create создает план и базовые категории.
-func (r *PlanRepository) Create(ctx context.Context, userID uuid.UUID, title string, budgetCents int64, periodStart, periodEnd time.Time, backgroundColor string, isAIGenerated bool) (models.BudgetPlan, error) {
+// Create создает план и базовые категории. Если currency не задана,
+// используется базовая валюта пользователя.
+func (r *PlanRepository) Create(ctx context.Context, userID uuid.UUID, title string, budgetCents int64, currency *string, periodStart, periodEnd time.Time, backgroundColor string, isAIGenerated bool) (models.BudgetPlan, error) {
 	var plan models.BudgetPlan
 
 	tx, err := r.db.Begin(ctx)
@@ -73,15 +97,23 @@ func (r *PlanRepository) Create(ctx context.Context, userID uuid.UUID, title str
 	}()
 
 	err = tx.QueryRow(ctx,
-		`INSERT INTO budget_plans (user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7)
-		 RETURNING id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated, created_at, updated_at`,
-		userID, title, budgetCents, periodStart, periodEnd, backgroundColor, isAIGenerated,
-	).Scan(&plan.ID, &plan.UserID, &plan.Title, &plan.BudgetCents, &plan.PeriodStart, &plan.PeriodEnd, &plan.BackgroundColor, &plan.IsAIGenerated, &plan.CreatedAt, &plan.UpdatedAt)
+		`INSERT INTO budget_plans (user_id, title, budget_cents, currency, period_start, period_end, background_color, is_ai_generated)
+		 VALUES ($1, $2, $3, COALESCE($8, (SELECT base_currency FROM users WHERE id = $1)), $4, $5, $6, $7)
+		 RETURNING `+planColumns,
+		userID, title, budgetCents, periodStart, periodEnd, backgroundColor, isAIGenerated, currency,
+	).Scan(planScanDest(&plan)...)
 	if err != nil {
 		return plan, err
 	}
 
+	if err = addPlanOwner(ctx, tx, plan.ID, userID); err != nil {
+		return plan, err
+	}
+
+	if err = insertAlertRules(ctx, tx, plan.ID, defaultAlertRules); err != nil {
+		return plan, err
+	}
+
 	for idx, category := range defaultCategories {
 		_, err = tx.Exec(ctx,
 			`INSERT INTO expense_categories (id, plan_id, title, category_type, sort_order)
@@ -101,7 +133,7 @@ func (r *PlanRepository) Create(ctx context.Context, userID uuid.UUID, title str
 }
 
 // CreateWithDetails создает план вместе с категориями и заметками.
-func (r *PlanRepository) CreateWithDetails(ctx context.Context, userID uuid.UUID, title string, budgetCents int64, periodStart, periodEnd time.Time, backgroundColor string, isAIGenerated bool, categories []PlanCategoryInput, notes []PlanNoteInput) (models.BudgetPlan, error) {
+func (r *PlanRepository) CreateWithDetails(ctx context.Context, userID uuid.UUID, title string, budgetCents int64, currency *string, periodStart, periodEnd time.Time, backgroundColor string, isAIGenerated bool, categories []PlanCategoryInput, notes []PlanNoteInput) (models.BudgetPlan, error) {
 	var plan models.BudgetPlan
 
 	if len(categories) == 0 {
@@ -128,15 +160,23 @@ func (r *PlanRepository) CreateWithDetails(ctx context.Context, userID uuid.UUID
 	}()
 
 	err = tx.QueryRow(ctx,
-		`INSERT INTO budget_plans (user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7)
-		 RETURNING id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated, created_at, updated_at`,
-		userID, title, budgetCents, periodStart, periodEnd, backgroundColor, isAIGenerated,
-	).Scan(&plan.ID, &plan.UserID, &plan.Title, &plan.BudgetCents, &plan.PeriodStart, &plan.PeriodEnd, &plan.BackgroundColor, &plan.IsAIGenerated, &plan.CreatedAt, &plan.UpdatedAt)
+		`INSERT INTO budget_plans (user_id, title, budget_cents, currency, period_start, period_end, background_color, is_ai_generated)
+		 VALUES ($1, $2, $3, COALESCE($8, (SELECT base_currency FROM users WHERE id = $1)), $4, $5, $6, $7)
+		 RETURNING `+planColumns,
+		userID, title, budgetCents, periodStart, periodEnd, backgroundColor, isAIGenerated, currency,
+	).Scan(planScanDest(&plan)...)
 	if err != nil {
 		return plan, err
 	}
 
+	if err = addPlanOwner(ctx, tx, plan.ID, userID); err != nil {
+		return plan, err
+	}
+
+	if err = insertAlertRules(ctx, tx, plan.ID, defaultAlertRules); err != nil {
+		return plan, err
+	}
+
 	for idx, category := range categories {
 		if strings.TrimSpace(category.Title) == "" {
 			return plan, ErrInvalid
@@ -190,10 +230,14 @@ func (r *PlanRepository) CreateWithDetails(ctx context.Context, userID uuid.UUID
 	return plan, nil
 }
 
-// Update обновляет план бюджета.
-func (r *PlanRepository) Update(ctx context.Context, userID, planID uuid.UUID, title string, budgetCents int64, periodStart, periodEnd time.Time, backgroundColor *string, isAIGenerated *bool) (models.BudgetPlan, error) {
+// Update обновляет план бюджета. Доступно владельцу и редакторам.
+func (r *PlanRepository) Update(ctx context.Context, userID, planID uuid.UUID, title string, budgetCents int64, currency *string, periodStart, periodEnd time.Time, backgroundColor *string, isAIGenerated *bool) (models.BudgetPlan, error) {
 	var plan models.BudgetPlan
 
+	if _, err := authorizePlan(ctx, r.db, planByID, planID, userID, editorRoles); err != nil {
+		return plan, err
+	}
+
 	err := r.db.QueryRow(ctx,
 		`UPDATE budget_plans
 		 SET title = $3,
@@ -202,11 +246,12 @@ func (r *PlanRepository) Update(ctx context.Context, userID, planID uuid.UUID, t
 		     period_end = $6,
 		     background_color = COALESCE($7, background_color),
 		     is_ai_generated = COALESCE($8, is_ai_generated),
+		     currency = COALESCE($9, currency),
 		     updated_at = NOW()
-		 WHERE id = $1 AND user_id = $2
-		 RETURNING id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated, created_at, updated_at`,
-		planID, userID, title, budgetCents, periodStart, periodEnd, backgroundColor, isAIGenerated,
-	).Scan(&plan.ID, &plan.UserID, &plan.Title, &plan.BudgetCents, &plan.PeriodStart, &plan.PeriodEnd, &plan.BackgroundColor, &plan.IsAIGenerated, &plan.CreatedAt, &plan.UpdatedAt)
+		 WHERE id = $1 AND `+editorOf("id", "$2")+`
+		 RETURNING `+planColumns,
+		planID, userID, title, budgetCents, periodStart, periodEnd, backgroundColor, isAIGenerated, currency,
+	).Scan(planScanDest(&plan)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return plan, ErrNotFound
@@ -217,8 +262,12 @@ func (r *PlanRepository) Update(ctx context.Context, userID, planID uuid.UUID, t
 	return plan, nil
 }
 
-// Delete удаляет план бюджета.
+// Delete удаляет план бюджета. Доступно только владельцу.
 func (r *PlanRepository) Delete(ctx context.Context, userID, planID uuid.UUID) error {
+	if _, err := authorizePlan(ctx, r.db, planByID, planID, userID, ownerRoles); err != nil {
+		return err
+	}
+
 	cmd, err := r.db.Exec(ctx,
 		`DELETE FROM budget_plans
 		 WHERE id = $1 AND user_id = $2`,
@@ -235,16 +284,16 @@ func (r *PlanRepository) Delete(ctx context.Context, userID, planID uuid.UUID) e
 	return nil
 }
 
-// GetByID возвращает план пользователя по идентификатору.
+// GetByID возвращает план по идентификатору, если пользователь участвует в нем.
 func (r *PlanRepository) GetByID(ctx context.Context, userID, planID uuid.UUID) (models.BudgetPlan, error) {
 	var plan models.BudgetPlan
 
 	err := r.db.QueryRow(ctx,
-		`SELECT id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated, created_at, updated_at
+		`SELECT `+planColumns+`
 		 FROM budget_plans
-		 WHERE id = $1 AND user_id = $2`,
+		 WHERE id = $1 AND `+memberOf("id", "$2"),
 		planID, userID,
-	).Scan(&plan.ID, &plan.UserID, &plan.Title, &plan.BudgetCents, &plan.PeriodStart, &plan.PeriodEnd, &plan.BackgroundColor, &plan.IsAIGenerated, &plan.CreatedAt, &plan.UpdatedAt)
+	).Scan(planScanDest(&plan)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return plan, ErrNotFound
@@ -255,18 +304,19 @@ func (r *PlanRepository) GetByID(ctx context.Context, userID, planID uuid.UUID)
 	return plan, nil
 }
 
-// ListByUser возвращает список планов пользователя.
+// ListByUser возвращает активные планы, в которых участвует пользователь.
 func (r *PlanRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]PlanWithSpent, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT p.id, p.user_id, p.title, p.budget_cents, p.period_start, p.period_end,
-		        p.background_color, p.is_ai_generated, p.created_at, p.updated_at,
-		        COALESCE(SUM(CASE WHEN i.is_completed THEN i.amount_cents ELSE 0 END), 0) AS spent_cents
+		`SELECT `+qualifiedPlanColumns+`,
+		        COALESCE(SUM(`+itemSpentExpr+`), 0) AS spent_cents,
+		        pm.role
 		 FROM budget_plans p
+		 JOIN plan_members pm ON pm.plan_id = p.id AND pm.user_id = $1
 		 LEFT JOIN expense_categories c ON c.plan_id = p.id
 		 LEFT JOIN expense_items i ON i.category_id = c.id
-		 WHERE p.user_id = $1 AND p.period_end >= CURRENT_DATE
-		 GROUP BY p.id, p.user_id, p.title, p.budget_cents, p.period_start, p.period_end,
-		          p.background_color, p.is_ai_generated, p.created_at, p.updated_at
+		 `+itemTransactionsJoin+`
+		 WHERE p.period_end >= `+userTodayExpr+`
+		 GROUP BY p.id, pm.role
 		 ORDER BY p.created_at DESC`,
 		userID,
 	)
@@ -279,13 +329,14 @@ func (r *PlanRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Pl
 	for rows.Next() {
 		var plan models.BudgetPlan
 		var spent int64
+		var role models.PlanRole
 
-		err := rows.Scan(&plan.ID, &plan.UserID, &plan.Title, &plan.BudgetCents, &plan.PeriodStart, &plan.PeriodEnd, &plan.BackgroundColor, &plan.IsAIGenerated, &plan.CreatedAt, &plan.UpdatedAt, &spent)
+		err := rows.Scan(append(planScanDest(&plan), &spent, &role)...)
 		if err != nil {
 			return nil, err
 		}
 
-		plans = append(plans, PlanWithSpent{Plan: plan, SpentCents: spent})
+		plans = append(plans, PlanWithSpent{Plan: plan, SpentCents: spent, Role: role})
 	}
 
 	if err := rows.Err(); err != nil {
@@ -295,18 +346,19 @@ func (r *PlanRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Pl
 	return plans, nil
 }
 
-// ListArchivedByUser возвращает архивные планы пользователя.
+// ListArchivedByUser возвращает архивные планы, в которых участвует пользователь.
 func (r *PlanRepository) ListArchivedByUser(ctx context.Context, userID uuid.UUID) ([]PlanWithSpent, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT p.id, p.user_id, p.title, p.budget_cents, p.period_start, p.period_end,
-		        p.background_color, p.is_ai_generated, p.created_at, p.updated_at,
-		        COALESCE(SUM(CASE WHEN i.is_completed THEN i.amount_cents ELSE 0 END), 0) AS spent_cents
+		`SELECT `+qualifiedPlanColumns+`,
+		        COALESCE(SUM(`+itemSpentExpr+`), 0) AS spent_cents,
+		        pm.role
 		 FROM budget_plans p
+		 JOIN plan_members pm ON pm.plan_id = p.id AND pm.user_id = $1
 		 LEFT JOIN expense_categories c ON c.plan_id = p.id
 		 LEFT JOIN expense_items i ON i.category_id = c.id
-		 WHERE p.user_id = $1 AND p.period_end < CURRENT_DATE
-		 GROUP BY p.id, p.user_id, p.title, p.budget_cents, p.period_start, p.period_end,
-		          p.background_color, p.is_ai_generated, p.created_at, p.updated_at
+		 `+itemTransactionsJoin+`
+		 WHERE p.period_end < `+userTodayExpr+`
+		 GROUP BY p.id, pm.role
 		 ORDER BY p.period_end DESC`,
 		userID,
 	)
@@ -319,13 +371,14 @@ func (r *PlanRepository) ListArchivedByUser(ctx context.Context, userID uuid.UUI
 	for rows.Next() {
 		var plan models.BudgetPlan
 		var spent int64
+		var role models.PlanRole
 
-		err := rows.Scan(&plan.ID, &plan.UserID, &plan.Title, &plan.BudgetCents, &plan.PeriodStart, &plan.PeriodEnd, &plan.BackgroundColor, &plan.IsAIGenerated, &plan.CreatedAt, &plan.UpdatedAt, &spent)
+		err := rows.Scan(append(planScanDest(&plan), &spent, &role)...)
 		if err != nil {
 			return nil, err
 		}
 
-		plans = append(plans, PlanWithSpent{Plan: plan, SpentCents: spent})
+		plans = append(plans, PlanWithSpent{Plan: plan, SpentCents: spent, Role: role})
 	}
 
 	if err := rows.Err(); err != nil {
@@ -340,9 +393,10 @@ func (r *PlanRepository) GetSpentCents(ctx context.Context, planID uuid.UUID) (i
 	var spent int64
 
 	err := r.db.QueryRow(ctx,
-		`SELECT COALESCE(SUM(CASE WHEN i.is_completed THEN i.amount_cents ELSE 0 END), 0)
+		`SELECT COALESCE(SUM(`+itemSpentExpr+`), 0)
 		 FROM expense_categories c
 		 LEFT JOIN expense_items i ON i.category_id = c.id
+		 `+itemTransactionsJoin+`
 		 WHERE c.plan_id = $1`,
 		planID,
 	).Scan(&spent)
@@ -423,6 +477,41 @@ func (r *PlanRepository) ListItemsByCategoryIDs(ctx context.Context, categoryIDs
 	return items, nil
 }
 
+// ListItemSpentCents возвращает фактические траты по расходам указанных категорий.
+func (r *PlanRepository) ListItemSpentCents(ctx context.Context, categoryIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	spent := make(map[uuid.UUID]int64)
+	if len(categoryIDs) == 0 {
+		return spent, nil
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT i.id, `+itemSpentExpr+`
+		 FROM expense_items i
+		 `+itemTransactionsJoin+`
+		 WHERE i.category_id = ANY($1)`,
+		categoryIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID uuid.UUID
+		var value int64
+		if err := rows.Scan(&itemID, &value); err != nil {
+			return nil, err
+		}
+		spent[itemID] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return spent, nil
+}
+
 // ListNotes возвращает заметки плана.
 func (r *PlanRepository) ListNotes(ctx context.Context, planID uuid.UUID) ([]models.Note, error) {
 	rows, err := r.db.Query(ctx,
@@ -466,19 +555,9 @@ func (r *PlanRepository) ReorderCategories(ctx context.Context, userID, planID u
 		_ = tx.Rollback(ctx)
 	}()
 
-	var exists bool
-	err = tx.QueryRow(ctx,
-		`SELECT EXISTS (
-			SELECT 1 FROM budget_plans WHERE id = $1 AND user_id = $2
-		 )`,
-		planID, userID,
-	).Scan(&exists)
-	if err != nil {
+	if _, err = authorizePlan(ctx, tx, planByID, planID, userID, editorRoles); err != nil {
 		return err
 	}
-	if !exists {
-		return ErrNotFound
-	}
 
 	var count int
 	err = tx.QueryRow(ctx,
@@ -529,6 +608,7 @@ func (r *PlanRepository) ReorderCategories(ctx context.Context, userID, planID u
 }
 
 // Duplicate создает полную копию плана с категориями и заметками.
+// Копия принадлежит пользователю, даже если исходный план ему только доступен.
 func (r *PlanRepository) Duplicate(ctx context.Context, userID, planID uuid.UUID) (models.BudgetPlan, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -540,11 +620,11 @@ func (r *PlanRepository) Duplicate(ctx context.Context, userID, planID uuid.UUID
 
 	var original models.BudgetPlan
 	err = tx.QueryRow(ctx,
-		`SELECT id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated, created_at, updated_at
+		`SELECT `+planColumns+`
 		 FROM budget_plans
-		 WHERE id = $1 AND user_id = $2`,
+		 WHERE id = $1 AND `+memberOf("id", "$2"),
 		planID, userID,
-	).Scan(&original.ID, &original.UserID, &original.Title, &original.BudgetCents, &original.PeriodStart, &original.PeriodEnd, &original.BackgroundColor, &original.IsAIGenerated, &original.CreatedAt, &original.UpdatedAt)
+	).Scan(planScanDest(&original)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.BudgetPlan{}, ErrNotFound
@@ -556,139 +636,24 @@ func (r *PlanRepository) Duplicate(ctx context.Context, userID, planID uuid.UUID
 
 	var newPlan models.BudgetPlan
 	err = tx.QueryRow(ctx,
-		`INSERT INTO budget_plans (id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		 RETURNING id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated, created_at, updated_at`,
-		uuid.New(), userID, newTitle, original.BudgetCents, original.PeriodStart, original.PeriodEnd, original.BackgroundColor, original.IsAIGenerated,
-	).Scan(&newPlan.ID, &newPlan.UserID, &newPlan.Title, &newPlan.BudgetCents, &newPlan.PeriodStart, &newPlan.PeriodEnd, &newPlan.BackgroundColor, &newPlan.IsAIGenerated, &newPlan.CreatedAt, &newPlan.UpdatedAt)
-	if err != nil {
-		return models.BudgetPlan{}, err
-	}
-
-	categoryRows, err := tx.Query(ctx,
-		`SELECT id, title, category_type, sort_order
-		 FROM expense_categories
-		 WHERE plan_id = $1
-		 ORDER BY sort_order, created_at`,
-		planID,
-	)
+		`INSERT INTO budget_plans (id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated, currency)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING `+planColumns,
+		uuid.New(), userID, newTitle, original.BudgetCents, original.PeriodStart, original.PeriodEnd, original.BackgroundColor, original.IsAIGenerated, original.Currency,
+	).Scan(planScanDest(&newPlan)...)
 	if err != nil {
 		return models.BudgetPlan{}, err
 	}
-	defer categoryRows.Close()
 
-	categoryMap := make(map[uuid.UUID]uuid.UUID)
-	oldCategoryIDs := make([]uuid.UUID, 0)
-
-	for categoryRows.Next() {
-		var oldID uuid.UUID
-		var title string
-		var categoryType models.CategoryType
-		var sortOrder int
-
-		err = categoryRows.Scan(&oldID, &title, &categoryType, &sortOrder)
-		if err != nil {
-			return models.BudgetPlan{}, err
-		}
-
-		newID := uuid.New()
-		categoryMap[oldID] = newID
-		oldCategoryIDs = append(oldCategoryIDs, oldID)
-
-		_, err = tx.Exec(ctx,
-			`INSERT INTO expense_categories (id, plan_id, title, category_type, sort_order)
-			 VALUES ($1, $2, $3, $4, $5)`,
-			newID, newPlan.ID, title, categoryType, sortOrder,
-		)
-		if err != nil {
-			return models.BudgetPlan{}, err
-		}
-	}
-
-	if err = categoryRows.Err(); err != nil {
+	if err = addPlanOwner(ctx, tx, newPlan.ID, userID); err != nil {
 		return models.BudgetPlan{}, err
 	}
 
-	if len(oldCategoryIDs) > 0 {
-		var itemRows pgx.Rows
-		itemRows, err = tx.Query(ctx,
-			`SELECT category_id, title, amount_cents, priority_color, is_completed, sort_order
-			 FROM expense_items
-			 WHERE category_id = ANY($1)
-			 ORDER BY sort_order, created_at`,
-			oldCategoryIDs,
-		)
-		if err != nil {
-			return models.BudgetPlan{}, err
-		}
-		defer itemRows.Close()
-
-		for itemRows.Next() {
-			var oldCategoryID uuid.UUID
-			var title string
-			var amountCents int64
-			var priorityColor models.PriorityColor
-			var isCompleted bool
-			var sortOrder int
-
-			err = itemRows.Scan(&oldCategoryID, &title, &amountCents, &priorityColor, &isCompleted, &sortOrder)
-			if err != nil {
-				return models.BudgetPlan{}, err
-			}
-
-			newCategoryID, ok := categoryMap[oldCategoryID]
-			if !ok {
-				return models.BudgetPlan{}, fmt.Errorf("missing category mapping")
-			}
-
-			_, err = tx.Exec(ctx,
-				`INSERT INTO expense_items (id, category_id, title, amount_cents, priority_color, is_completed, sort_order)
-				 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
-				uuid.New(), newCategoryID, title, amountCents, priorityColor, isCompleted, sortOrder,
-			)
-			if err != nil {
-				return models.BudgetPlan{}, err
-			}
-		}
-
-		if err = itemRows.Err(); err != nil {
-			return models.BudgetPlan{}, err
-		}
-	}
-
-	noteRows, err := tx.Query(ctx,
-		`SELECT content, note_type, sort_order
-		 FROM notes
-		 WHERE plan_id = $1
-		 ORDER BY sort_order, created_at`,
-		planID,
-	)
-	if err != nil {
+	if err = copyPlanContents(ctx, tx, original.ID, newPlan.ID, false); err != nil {
 		return models.BudgetPlan{}, err
 	}
-	defer noteRows.Close()
-
-	for noteRows.Next() {
-		var content string
-		var noteType models.NoteType
-		var sortOrder int
-
-		err := noteRows.Scan(&content, &noteType, &sortOrder)
-		if err != nil {
-			return models.BudgetPlan{}, err
-		}
 
-		_, err = tx.Exec(ctx,
-			`INSERT INTO notes (id, plan_id, content, note_type, sort_order)
-			 VALUES ($1, $2, $3, $4, $5)`,
-			uuid.New(), newPlan.ID, content, noteType, sortOrder,
-		)
-		if err != nil {
-			return models.BudgetPlan{}, err
-		}
-	}
-
-	if err := noteRows.Err(); err != nil {
+	if err = copyAlertRules(ctx, tx, original.ID, newPlan.ID); err != nil {
 		return models.BudgetPlan{}, err
 	}
 
@@ -699,6 +664,40 @@ func (r *PlanRepository) Duplicate(ctx context.Context, userID, planID uuid.UUID
 	return newPlan, nil
 }
 
+// copyPlanContents копирует категории, расходы и заметки плана sourceID в план targetID.
+// При resetCompleted отметки выполненности расходов сбрасываются.
+func copyPlanContents(ctx context.Context, tx pgx.Tx, sourceID, targetID uuid.UUID, resetCompleted bool) error {
+	_, err := tx.Exec(ctx,
+		`WITH mapping AS MATERIALIZED (
+			SELECT id AS old_id, gen_random_uuid() AS new_id
+			FROM expense_categories
+			WHERE plan_id = $1
+		 ), new_categories AS (
+			INSERT INTO expense_categories (id, plan_id, title, category_type, sort_order, is_carry_over)
+			SELECT m.new_id, $2, c.title, c.category_type, c.sort_order, c.is_carry_over
+			FROM expense_categories c
+			JOIN mapping m ON m.old_id = c.id
+		 )
+		 INSERT INTO expense_items (id, category_id, title, amount_cents, priority_color, is_completed, sort_order)
+		 SELECT gen_random_uuid(), m.new_id, i.title, i.amount_cents, i.priority_color, i.is_completed AND NOT $3, i.sort_order
+		 FROM expense_items i
+		 JOIN mapping m ON m.old_id = i.category_id`,
+		sourceID, targetID, resetCompleted,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO notes (id, plan_id, content, note_type, sort_order)
+		 SELECT gen_random_uuid(), $2, content, note_type, sort_order
+		 FROM notes
+		 WHERE plan_id = $1`,
+		sourceID, targetID,
+	)
+	return err
+}
+
 func buildCopyTitle(title string, maxRunes int) string {
 	copyTitle := fmt.Sprintf("Copy of %s", title)
 	if len([]rune(copyTitle)) <= maxRunes {
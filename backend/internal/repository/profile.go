@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// ProfileUpdate описывает изменения профиля; nil-поля не меняются.
+type ProfileUpdate struct {
+	Name         *string
+	ClearName    bool
+	BaseCurrency *string
+	Locale       *string
+	TimeZone     *string
+	WeekStart    *models.WeekStart
+
+	// ChangeEmail записывает PendingEmail — адрес, на который пользователь
+	// хочет сменить email; nil отменяет начатую смену.
+	ChangeEmail  bool
+	PendingEmail *string
+
+	// PasswordHash задает новый пароль; refresh-токены пользователя, кроме
+	// KeepSessionID, отзываются, чтобы текущая сессия осталась активной.
+	PasswordHash  *string
+	KeepSessionID uuid.UUID
+}
+
+// UpdateProfile применяет изменения профиля, email и пароля в одной
+// транзакции: при ошибке не меняется ничего. Возвращает ErrConflict, если
+// новый email занят другим пользователем.
+func (r *UserRepository) UpdateProfile(ctx context.Context, id uuid.UUID, update ProfileUpdate) (models.User, error) {
+	var user models.User
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return user, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	err = tx.QueryRow(ctx,
+		`UPDATE users
+		 SET name = CASE WHEN $7 THEN NULL ELSE COALESCE($2, name) END,
+		     base_currency = COALESCE($3, base_currency),
+		     locale = COALESCE($4, locale),
+		     time_zone = COALESCE($5, time_zone),
+		     week_start = COALESCE($6, week_start),
+		     pending_email = CASE WHEN $8 THEN $9::text ELSE pending_email END,
+		     password_hash = COALESCE($10, password_hash),
+		     updated_at = NOW()
+		 WHERE id = $1
+		   AND NOT ($8 AND EXISTS (SELECT 1 FROM users other WHERE other.email = $9::text AND other.id <> $1))
+		 RETURNING `+userColumns,
+		id, update.Name, update.BaseCurrency, update.Locale, update.TimeZone, update.WeekStart, update.ClearName,
+		update.ChangeEmail, update.PendingEmail, update.PasswordHash,
+	).Scan(userScanDest(&user)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.GetByID(ctx, id); getErr != nil {
+				return user, getErr
+			}
+			return user, ErrConflict
+		}
+		return user, err
+	}
+
+	if update.PasswordHash != nil {
+		_, err = tx.Exec(ctx,
+			`UPDATE refresh_tokens
+			 SET revoked_at = NOW()
+			 WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`,
+			id, update.KeepSessionID,
+		)
+		if err != nil {
+			return user, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// ConfirmEmailChange погашает токен смены email и переносит ожидающий адрес
+// в email пользователя. Возвращает ErrNotFound, если токен недействителен или
+// смена уже отменена, и ErrConflict, если адрес успели занять.
+func (r *UserRepository) ConfirmEmailChange(ctx context.Context, tokenHash string) (models.User, error) {
+	var user models.User
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return user, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	userID, err := consumeUserToken(ctx, tx, models.UserTokenEmailChange, tokenHash)
+	if err != nil {
+		return user, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`UPDATE users
+		 SET email = pending_email,
+		     pending_email = NULL,
+		     email_verified_at = NOW(),
+		     updated_at = NOW()
+		 WHERE id = $1 AND pending_email IS NOT NULL
+		 RETURNING `+userColumns,
+		userID,
+	).Scan(userScanDest(&user)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, ErrNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return user, ErrConflict
+		}
+		return user, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
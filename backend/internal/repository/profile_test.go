@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestUpdateProfileIsAtomic проверяет, что при занятом email не применяются
+// и остальные изменения, в том числе новый пароль.
+func TestUpdateProfileIsAtomic(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	users := NewUserRepository(db)
+
+	user, err := users.Create(ctx, "owner@example.com", "old-hash", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if _, err := users.Create(ctx, "taken@example.com", "hash", nil); err != nil {
+		t.Fatalf("create other user: %v", err)
+	}
+
+	name := "Иван"
+	taken := "taken@example.com"
+	newHash := "new-hash"
+	_, err = users.UpdateProfile(ctx, user.ID, ProfileUpdate{
+		Name:         &name,
+		ChangeEmail:  true,
+		PendingEmail: &taken,
+		PasswordHash: &newHash,
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	stored, err := users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if stored.PasswordHash != "old-hash" || stored.Name != nil || stored.PendingEmail != nil {
+		t.Fatalf("expected profile to stay unchanged, got %+v", stored)
+	}
+
+	pending := "new@example.com"
+	updated, err := users.UpdateProfile(ctx, user.ID, ProfileUpdate{
+		Name:         &name,
+		ChangeEmail:  true,
+		PendingEmail: &pending,
+		PasswordHash: &newHash,
+	})
+	if err != nil {
+		t.Fatalf("update profile: %v", err)
+	}
+	if updated.PasswordHash != newHash || updated.PendingEmail == nil || *updated.PendingEmail != pending {
+		t.Fatalf("expected password and pending email to change, got %+v", updated)
+	}
+}
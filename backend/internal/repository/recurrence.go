@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+const biweeklyIntervalDays = 14
+
+// NextPeriod вычисляет даты следующего периода повторяющегося плана.
+// Месячный период начинается на следующий день после окончания текущего и
+// заканчивается тем же числом следующего месяца (последний день месяца
+// переходит в последний день). Двухнедельный и custom-периоды сдвигаются
+// на фиксированное число дней. Возвращает false, если план не повторяется.
+func NextPeriod(start, end time.Time, recurrence models.Recurrence, intervalDays *int) (time.Time, time.Time, bool) {
+	switch recurrence {
+	case models.RecurrenceMonthly:
+		return end.AddDate(0, 0, 1), addMonthClamped(end), true
+	case models.RecurrenceBiweekly:
+		return start.AddDate(0, 0, biweeklyIntervalDays), end.AddDate(0, 0, biweeklyIntervalDays), true
+	case models.RecurrenceCustom:
+		if intervalDays == nil || *intervalDays <= 0 {
+			return time.Time{}, time.Time{}, false
+		}
+		return start.AddDate(0, 0, *intervalDays), end.AddDate(0, 0, *intervalDays), true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+}
+
+// addMonthClamped сдвигает дату на месяц без перескока через конец месяца.
+func addMonthClamped(date time.Time) time.Time {
+	year, month, day := date.Date()
+	lastDay := daysIn(year, month)
+	nextYear, nextMonth, _ := time.Date(year, month+1, 1, 0, 0, 0, 0, date.Location()).Date()
+	nextLastDay := daysIn(nextYear, nextMonth)
+
+	if day == lastDay || day > nextLastDay {
+		day = nextLastDay
+	}
+
+	return time.Date(nextYear, nextMonth, day, 0, 0, 0, 0, date.Location())
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+const (
+	carryOverCategoryTitle = "Перенос остатка"
+	carryOverItemTitle     = "Остаток прошлого периода"
+)
+
+// UpdateRecurrence задает правило повторения плана. Доступно только владельцу.
+func (r *PlanRepository) UpdateRecurrence(ctx context.Context, userID, planID uuid.UUID, recurrence models.Recurrence, intervalDays *int, carryOver bool) (models.BudgetPlan, error) {
+	var plan models.BudgetPlan
+
+	if _, err := authorizePlan(ctx, r.db, planByID, planID, userID, ownerRoles); err != nil {
+		return plan, err
+	}
+
+	if recurrence != models.RecurrenceCustom {
+		intervalDays = nil
+	}
+
+	err := r.db.QueryRow(ctx,
+		`UPDATE budget_plans
+		 SET recurrence = $3,
+		     recurrence_interval_days = $4,
+		     carry_over = $5,
+		     updated_at = NOW()
+		 WHERE id = $1 AND user_id = $2
+		 RETURNING `+planColumns,
+		planID, userID, recurrence, intervalDays, carryOver,
+	).Scan(planScanDest(&plan)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return plan, ErrNotFound
+		}
+		return plan, err
+	}
+
+	return plan, nil
+}
+
+// ListDueRecurring возвращает повторяющиеся планы, период которых закончился
+// до asOf, и для которых еще не создан следующий план. План, последний день
+// которого asOf, еще идет и в выборку не попадает. Планы, отложенные после
+// неудачного переноса, пропускаются до rollover_retry_at и идут после тех,
+// что переносятся впервые.
+func (r *PlanRepository) ListDueRecurring(ctx context.Context, asOf time.Time, limit int) ([]models.BudgetPlan, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+planColumns+`
+		 FROM budget_plans
+		 WHERE recurrence <> 'none'
+		   AND next_plan_id IS NULL
+		   AND period_end < $1
+		   AND (rollover_retry_at IS NULL OR rollover_retry_at <= NOW())
+		 ORDER BY rollover_attempts, period_end
+		 LIMIT $2`,
+		asOf, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := make([]models.BudgetPlan, 0)
+	for rows.Next() {
+		var plan models.BudgetPlan
+		if err := rows.Scan(planScanDest(&plan)...); err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+// RecordRolloverFailure записывает неудачную попытку переноса плана и
+// откладывает следующую: задержка начинается с baseDelay и удваивается с
+// каждой попыткой, но не превышает maxDelay.
+func (r *PlanRepository) RecordRolloverFailure(ctx context.Context, planID uuid.UUID, baseDelay, maxDelay time.Duration) error {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE budget_plans
+		 SET rollover_attempts = rollover_attempts + 1,
+		     rollover_retry_at = NOW() + LEAST($2::float8 * power(2, LEAST(rollover_attempts, 30)), $3::float8) * INTERVAL '1 second'
+		 WHERE id = $1`,
+		planID, baseDelay.Seconds(), maxDelay.Seconds(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Rollover создает план следующего периода для повторяющегося плана: копирует
+// категории, расходы и заметки со сброшенной выполненностью и, если включен
+// carry_over, переносит неизрасходованный остаток отдельным расходом.
+// Участники исходного плана сохраняют свои роли в новом.
+// Возвращает ErrConflict, если следующий план уже создан.
+func (r *PlanRepository) Rollover(ctx context.Context, planID uuid.UUID) (models.BudgetPlan, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return models.BudgetPlan{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var original models.BudgetPlan
+	err = tx.QueryRow(ctx,
+		`SELECT `+planColumns+`
+		 FROM budget_plans
+		 WHERE id = $1
+		 FOR UPDATE`,
+		planID,
+	).Scan(planScanDest(&original)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.BudgetPlan{}, ErrNotFound
+		}
+		return models.BudgetPlan{}, err
+	}
+
+	if original.NextPlanID != nil {
+		return models.BudgetPlan{}, ErrConflict
+	}
+
+	periodStart, periodEnd, ok := NextPeriod(original.PeriodStart, original.PeriodEnd, original.Recurrence, original.RecurrenceDays)
+	if !ok {
+		return models.BudgetPlan{}, ErrInvalid
+	}
+
+	// Остаток, перенесенный в текущий план, не должен накапливаться в следующих периодах.
+	var previousCarryCents int64
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(SUM(i.amount_cents), 0)
+		 FROM expense_categories c
+		 JOIN expense_items i ON i.category_id = c.id
+		 WHERE c.plan_id = $1 AND c.is_carry_over`,
+		original.ID,
+	).Scan(&previousCarryCents)
+	if err != nil {
+		return models.BudgetPlan{}, err
+	}
+
+	var carryCents int64
+	if original.CarryOver {
+		var spent int64
+		err = tx.QueryRow(ctx,
+			`SELECT COALESCE(SUM(`+itemSpentExpr+`), 0)
+			 FROM expense_categories c
+			 LEFT JOIN expense_items i ON i.category_id = c.id
+			 `+itemTransactionsJoin+`
+			 WHERE c.plan_id = $1`,
+			original.ID,
+		).Scan(&spent)
+		if err != nil {
+			return models.BudgetPlan{}, err
+		}
+
+		if remaining := original.BudgetCents - spent; remaining > 0 {
+			carryCents = remaining
+		}
+	}
+
+	var newPlan models.BudgetPlan
+	err = tx.QueryRow(ctx,
+		`INSERT INTO budget_plans (id, user_id, title, budget_cents, period_start, period_end, background_color, is_ai_generated,
+		                           recurrence, recurrence_interval_days, carry_over, currency)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 RETURNING `+planColumns,
+		uuid.New(), original.UserID, original.Title, original.BudgetCents-previousCarryCents+carryCents, periodStart, periodEnd,
+		original.BackgroundColor, original.IsAIGenerated, original.Recurrence, original.RecurrenceDays, original.CarryOver, original.Currency,
+	).Scan(planScanDest(&newPlan)...)
+	if err != nil {
+		return models.BudgetPlan{}, err
+	}
+
+	if err = copyPlanContents(ctx, tx, original.ID, newPlan.ID, true); err != nil {
+		return models.BudgetPlan{}, err
+	}
+
+	if err = copyAlertRules(ctx, tx, original.ID, newPlan.ID); err != nil {
+		return models.BudgetPlan{}, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO plan_members (plan_id, user_id, role)
+		 SELECT $2, user_id, role
+		 FROM plan_members
+		 WHERE plan_id = $1`,
+		original.ID, newPlan.ID,
+	)
+	if err != nil {
+		return models.BudgetPlan{}, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM expense_categories
+		 WHERE plan_id = $1 AND is_carry_over`,
+		newPlan.ID,
+	)
+	if err != nil {
+		return models.BudgetPlan{}, err
+	}
+
+	if carryCents > 0 {
+		categoryID := uuid.New()
+		_, err = tx.Exec(ctx,
+			`INSERT INTO expense_categories (id, plan_id, title, category_type, sort_order, is_carry_over)
+			 SELECT $1, $2, $3, $4, COALESCE(MAX(sort_order), -1) + 1, TRUE
+			 FROM expense_categories
+			 WHERE plan_id = $2`,
+			categoryID, newPlan.ID, carryOverCategoryTitle, models.CategoryTypeOptional,
+		)
+		if err != nil {
+			return models.BudgetPlan{}, err
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO expense_items (id, category_id, title, amount_cents, priority_color, is_completed, sort_order)
+			 VALUES ($1, $2, $3, $4, $5, FALSE, 0)`,
+			uuid.New(), categoryID, carryOverItemTitle, carryCents, models.PriorityColorGreen,
+		)
+		if err != nil {
+			return models.BudgetPlan{}, err
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE budget_plans
+		 SET next_plan_id = $2,
+		     updated_at = NOW()
+		 WHERE id = $1`,
+		original.ID, newPlan.ID,
+	)
+	if err != nil {
+		return models.BudgetPlan{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.BudgetPlan{}, err
+	}
+
+	return newPlan, nil
+}
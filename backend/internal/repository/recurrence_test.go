@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+func mustDate(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatalf("parse date %s: %v", value, err)
+	}
+	return parsed
+}
+
+// TestNextPeriodMonthly проверяет сдвиг месячного периода, включая концы месяцев.
+func TestNextPeriodMonthly(t *testing.T) {
+	cases := []struct {
+		start, end         string
+		wantStart, wantEnd string
+	}{
+		{"2024-01-01", "2024-01-31", "2024-02-01", "2024-02-29"},
+		{"2024-02-01", "2024-02-29", "2024-03-01", "2024-03-31"},
+		{"2024-01-15", "2024-02-14", "2024-02-15", "2024-03-14"},
+		{"2024-12-01", "2024-12-31", "2025-01-01", "2025-01-31"},
+		{"2024-01-01", "2024-01-30", "2024-01-31", "2024-02-29"},
+	}
+
+	for _, tc := range cases {
+		start, end, ok := NextPeriod(mustDate(t, tc.start), mustDate(t, tc.end), models.RecurrenceMonthly, nil)
+		if !ok {
+			t.Fatalf("expected monthly period for %s..%s", tc.start, tc.end)
+		}
+		if got := start.Format("2006-01-02"); got != tc.wantStart {
+			t.Fatalf("%s..%s: expected start %s, got %s", tc.start, tc.end, tc.wantStart, got)
+		}
+		if got := end.Format("2006-01-02"); got != tc.wantEnd {
+			t.Fatalf("%s..%s: expected end %s, got %s", tc.start, tc.end, tc.wantEnd, got)
+		}
+	}
+}
+
+// TestNextPeriodFixedInterval проверяет двухнедельный и custom-периоды.
+func TestNextPeriodFixedInterval(t *testing.T) {
+	start, end, ok := NextPeriod(mustDate(t, "2024-01-01"), mustDate(t, "2024-01-14"), models.RecurrenceBiweekly, nil)
+	if !ok || start.Format("2006-01-02") != "2024-01-15" || end.Format("2006-01-02") != "2024-01-28" {
+		t.Fatalf("unexpected biweekly period: %v %v %v", start, end, ok)
+	}
+
+	days := 10
+	start, end, ok = NextPeriod(mustDate(t, "2024-01-01"), mustDate(t, "2024-01-10"), models.RecurrenceCustom, &days)
+	if !ok || start.Format("2006-01-02") != "2024-01-11" || end.Format("2006-01-02") != "2024-01-20" {
+		t.Fatalf("unexpected custom period: %v %v %v", start, end, ok)
+	}
+}
+
+// TestNextPeriodInvalid проверяет отказ для неповторяющихся планов.
+func TestNextPeriodInvalid(t *testing.T) {
+	if _, _, ok := NextPeriod(mustDate(t, "2024-01-01"), mustDate(t, "2024-01-31"), models.RecurrenceNone, nil); ok {
+		t.Fatal("expected no period for recurrence none")
+	}
+
+	if _, _, ok := NextPeriod(mustDate(t, "2024-01-01"), mustDate(t, "2024-01-31"), models.RecurrenceCustom, nil); ok {
+		t.Fatal("expected no period for custom without interval")
+	}
+}
+
+// TestRolloverDueAndCarryOver проверяет, что план попадает в перенос только на
+// следующий день после окончания периода, а категория остатка находится по
+// флагу, даже если пользователь ее переименовал.
+func TestRolloverDueAndCarryOver(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	plans := NewPlanRepository(db)
+
+	user, err := NewUserRepository(db).Create(ctx, "rollover@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	plan, err := plans.Create(ctx, user.ID, "Январь", 10000, nil, mustDate(t, "2024-01-01"), mustDate(t, "2024-01-31"), "#FFFFFF", false)
+	if err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	if _, err := plans.UpdateRecurrence(ctx, user.ID, plan.ID, models.RecurrenceMonthly, nil, true); err != nil {
+		t.Fatalf("update recurrence: %v", err)
+	}
+
+	due, err := plans.ListDueRecurring(ctx, mustDate(t, "2024-01-31"), 10)
+	if err != nil {
+		t.Fatalf("list due: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected plan on its last day not to be due, got %d", len(due))
+	}
+
+	due, err = plans.ListDueRecurring(ctx, mustDate(t, "2024-02-01"), 10)
+	if err != nil {
+		t.Fatalf("list due: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != plan.ID {
+		t.Fatalf("expected plan to be due the next day, got %+v", due)
+	}
+
+	february, err := plans.Rollover(ctx, plan.ID)
+	if err != nil {
+		t.Fatalf("rollover: %v", err)
+	}
+	if february.BudgetCents != 20000 {
+		t.Fatalf("expected carried budget 20000, got %d", february.BudgetCents)
+	}
+
+	if _, err := db.Exec(ctx, `UPDATE expense_categories SET title = 'Остаток' WHERE plan_id = $1 AND is_carry_over`, february.ID); err != nil {
+		t.Fatalf("rename carry-over category: %v", err)
+	}
+
+	march, err := plans.Rollover(ctx, february.ID)
+	if err != nil {
+		t.Fatalf("rollover: %v", err)
+	}
+	// Прежний остаток 10000 вычитается, новый 20000 добавляется.
+	if march.BudgetCents != 30000 {
+		t.Fatalf("expected budget 30000 without accumulated carry-over, got %d", march.BudgetCents)
+	}
+
+	var carryCategories int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM expense_categories WHERE plan_id = $1 AND is_carry_over`, march.ID).Scan(&carryCategories); err != nil {
+		t.Fatalf("count carry-over categories: %v", err)
+	}
+	if carryCategories != 1 {
+		t.Fatalf("expected one carry-over category, got %d", carryCategories)
+	}
+}
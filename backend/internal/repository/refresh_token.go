@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"errors"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -21,12 +20,20 @@ func NewRefreshTokenRepository(db *pgxpool.Pool) *RefreshTokenRepository {
 	return &RefreshTokenRepository{db: db}
 }
 
+// refreshTokenColumns перечисляет колонки refresh_tokens в порядке refreshTokenScanDest.
+const refreshTokenColumns = `id, user_id, token_hash, user_agent, ip_address, expires_at, created_at, revoked_at, replaced_by`
+
+func refreshTokenScanDest(token *models.RefreshToken) []any {
+	return []any{&token.ID, &token.UserID, &token.TokenHash, &token.UserAgent, &token.IPAddress,
+		&token.ExpiresAt, &token.CreatedAt, &token.RevokedAt, &token.ReplacedBy}
+}
+
 // Create сохраняет refresh-токен.
 func (r *RefreshTokenRepository) Create(ctx context.Context, token models.RefreshToken) error {
 	_, err := r.db.Exec(ctx,
-		`INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at)
-		 VALUES ($1, $2, $3, $4)`,
-		token.ID, token.UserID, token.TokenHash, token.ExpiresAt,
+		`INSERT INTO refresh_tokens (id, user_id, token_hash, user_agent, ip_address, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		token.ID, token.UserID, token.TokenHash, token.UserAgent, token.IPAddress, token.ExpiresAt,
 	)
 	return err
 }
@@ -34,15 +41,13 @@ func (r *RefreshTokenRepository) Create(ctx context.Context, token models.Refres
 // GetByID возвращает refresh-токен по идентификатору.
 func (r *RefreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (models.RefreshToken, error) {
 	var token models.RefreshToken
-	var revokedAt *time.Time
-	var replacedBy *uuid.UUID
 
 	err := r.db.QueryRow(ctx,
-		`SELECT id, user_id, token_hash, expires_at, created_at, revoked_at, replaced_by
+		`SELECT `+refreshTokenColumns+`
 		 FROM refresh_tokens
 		 WHERE id = $1`,
 		id,
-	).Scan(&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt, &revokedAt, &replacedBy)
+	).Scan(refreshTokenScanDest(&token)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return token, ErrNotFound
@@ -50,8 +55,6 @@ func (r *RefreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (mod
 		return token, err
 	}
 
-	token.RevokedAt = revokedAt
-	token.ReplacedBy = replacedBy
 	return token, nil
 }
 
@@ -85,9 +88,9 @@ func (r *RefreshTokenRepository) Rotate(ctx context.Context, oldID uuid.UUID, ne
 	}()
 
 	_, err = tx.Exec(ctx,
-		`INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at)
-		 VALUES ($1, $2, $3, $4)`,
-		newToken.ID, newToken.UserID, newToken.TokenHash, newToken.ExpiresAt,
+		`INSERT INTO refresh_tokens (id, user_id, token_hash, user_agent, ip_address, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		newToken.ID, newToken.UserID, newToken.TokenHash, newToken.UserAgent, newToken.IPAddress, newToken.ExpiresAt,
 	)
 	if err != nil {
 		return err
@@ -109,3 +112,92 @@ func (r *RefreshTokenRepository) Rotate(ctx context.Context, oldID uuid.UUID, ne
 
 	return tx.Commit(ctx)
 }
+
+// ListActiveByUser возвращает действующие refresh-токены (сессии) пользователя, начиная с новых.
+func (r *RefreshTokenRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.RefreshToken, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+refreshTokenColumns+`
+		 FROM refresh_tokens
+		 WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		 ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]models.RefreshToken, 0)
+	for rows.Next() {
+		var token models.RefreshToken
+		if err := rows.Scan(refreshTokenScanDest(&token)...); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// RevokeForUser отзывает действующий refresh-токен пользователя.
+func (r *RefreshTokenRepository) RevokeForUser(ctx context.Context, userID, id uuid.UUID) error {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE refresh_tokens
+		 SET revoked_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// RevokeAllForUser отзывает все действующие refresh-токены пользователя и возвращает их число.
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE refresh_tokens
+		 SET revoked_at = NOW()
+		 WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
+
+// RevokeFamily отзывает токен id и все токены, выпущенные после него по цепочке
+// replaced_by, и возвращает число отозванных.
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, id uuid.UUID) (int64, error) {
+	cmd, err := r.db.Exec(ctx,
+		`WITH RECURSIVE family AS (
+		     SELECT id, replaced_by
+		     FROM refresh_tokens
+		     WHERE id = $1
+		     UNION
+		     SELECT t.id, t.replaced_by
+		     FROM refresh_tokens t
+		     JOIN family f ON t.id = f.replaced_by
+		 )
+		 UPDATE refresh_tokens
+		 SET revoked_at = NOW()
+		 WHERE id IN (SELECT id FROM family) AND revoked_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestRevokeFamily проверяет, что отзывается цепочка замен от токена вперед,
+// а другие сессии пользователя остаются активными.
+func TestRevokeFamily(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	tokens := NewRefreshTokenRepository(db)
+
+	user, err := NewUserRepository(db).Create(ctx, "family@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	newToken := func() uuid.UUID {
+		t.Helper()
+
+		token := models.RefreshToken{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			TokenHash: uuid.NewString(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		if err := tokens.Create(ctx, token); err != nil {
+			t.Fatalf("create token: %v", err)
+		}
+		return token.ID
+	}
+
+	first, second, third, other := newToken(), newToken(), newToken(), newToken()
+	if err := tokens.Revoke(ctx, first, &second); err != nil {
+		t.Fatalf("rotate first: %v", err)
+	}
+	if err := tokens.Revoke(ctx, second, &third); err != nil {
+		t.Fatalf("rotate second: %v", err)
+	}
+
+	revoked, err := tokens.RevokeFamily(ctx, first)
+	if err != nil {
+		t.Fatalf("revoke family: %v", err)
+	}
+	if revoked != 1 {
+		t.Fatalf("expected only the last token of the chain to be newly revoked, got %d", revoked)
+	}
+
+	last, err := tokens.GetByID(ctx, third)
+	if err != nil || last.RevokedAt == nil {
+		t.Fatalf("expected the end of the chain to be revoked, got %+v, %v", last, err)
+	}
+
+	active, err := tokens.ListActiveByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("list active: %v", err)
+	}
+	if len(active) != 1 || active[0].ID != other {
+		t.Fatalf("expected the unrelated session to stay active, got %+v", active)
+	}
+}
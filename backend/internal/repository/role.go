@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+type RoleRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewRoleRepository создает репозиторий ролей и прав пользователей.
+func NewRoleRepository(db *pgxpool.Pool) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// ListRoles возвращает все роли с их правами.
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]models.Role, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT r.name, r.description, r.created_at,
+		        COALESCE(array_agg(p.permission ORDER BY p.permission) FILTER (WHERE p.permission IS NOT NULL), '{}')
+		 FROM roles r
+		 LEFT JOIN role_permissions p ON p.role = r.name
+		 GROUP BY r.name
+		 ORDER BY r.name`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]models.Role, 0)
+	for rows.Next() {
+		var role models.Role
+		var permissions []string
+		if err := rows.Scan(&role.Name, &role.Description, &role.CreatedAt, &permissions); err != nil {
+			return nil, err
+		}
+		role.Permissions = toPermissions(permissions)
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// PermissionsForUser возвращает объединение прав всех ролей пользователя.
+func (r *RoleRepository) PermissionsForUser(ctx context.Context, userID uuid.UUID) ([]models.Permission, error) {
+	var permissions []string
+
+	err := r.db.QueryRow(ctx,
+		`SELECT COALESCE(array_agg(DISTINCT p.permission), '{}')
+		 FROM user_roles ur
+		 JOIN role_permissions p ON p.role = ur.role
+		 WHERE ur.user_id = $1`,
+		userID,
+	).Scan(&permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPermissions(permissions), nil
+}
+
+// RolesForUser возвращает названия ролей пользователя.
+func (r *RoleRepository) RolesForUser(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var exists bool
+	var roles []string
+
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1),
+		        COALESCE((SELECT array_agg(role ORDER BY role) FROM user_roles WHERE user_id = $1), '{}')`,
+		userID,
+	).Scan(&exists, &roles)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return roles, nil
+}
+
+// Grant выдает пользователю роль. Повторная выдача ничего не меняет.
+// Возвращает ErrNotFound, если нет пользователя или роли.
+func (r *RoleRepository) Grant(ctx context.Context, userID uuid.UUID, role string, grantedBy uuid.UUID) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO user_roles (user_id, role, granted_by)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, role) DO NOTHING`,
+		userID, role, grantedBy,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Revoke забирает у пользователя роль. Возвращает ErrNotFound, если роли у
+// пользователя нет, и ErrConflict, если после этого не останется никого с
+// правом управлять ролями.
+func (r *RoleRepository) Revoke(ctx context.Context, userID uuid.UUID, role string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Блокировка не дает двум одновременным отзывам оставить систему без администраторов.
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('user_roles'))`); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx,
+		`DELETE FROM user_roles
+		 WHERE user_id = $1 AND role = $2`,
+		userID, role,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	var managers int
+	err = tx.QueryRow(ctx,
+		`SELECT COUNT(DISTINCT ur.user_id)
+		 FROM user_roles ur
+		 JOIN role_permissions p ON p.role = ur.role
+		 WHERE p.permission = $1`,
+		models.PermissionRolesManage,
+	).Scan(&managers)
+	if err != nil {
+		return err
+	}
+	if managers == 0 {
+		return ErrConflict
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Bootstrap выдает роль admin существующим пользователям с email из списка,
+// только пока ни у кого нет права управлять ролями. После появления первого
+// администратора список не применяется, поэтому отозванная через API роль не
+// возвращается при следующем запуске. Возвращает число новых выдач.
+func (r *RoleRepository) Bootstrap(ctx context.Context, emails []string) (int64, error) {
+	if len(emails) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Та же блокировка, что в Revoke: экземпляры, запущенные одновременно,
+	// не выдадут роль повторно после отзыва.
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('user_roles'))`); err != nil {
+		return 0, err
+	}
+
+	var hasManagers bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS (
+		   SELECT 1
+		   FROM user_roles ur
+		   JOIN role_permissions p ON p.role = ur.role
+		   WHERE p.permission = $1
+		 )`,
+		models.PermissionRolesManage,
+	).Scan(&hasManagers)
+	if err != nil {
+		return 0, err
+	}
+	if hasManagers {
+		return 0, nil
+	}
+
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO user_roles (user_id, role)
+		 SELECT id, $2
+		 FROM users
+		 WHERE LOWER(email) = ANY($1)
+		 ON CONFLICT (user_id, role) DO NOTHING`,
+		emails, models.RoleAdmin,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func toPermissions(values []string) []models.Permission {
+	permissions := make([]models.Permission, 0, len(values))
+	for _, value := range values {
+		permissions = append(permissions, models.Permission(value))
+	}
+	return permissions
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestBootstrapKeepsRevokedAdmin проверяет, что ADMIN_EMAILS применяется, пока
+// нет администраторов, и не возвращает роль, отозванную через API.
+func TestBootstrapKeepsRevokedAdmin(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	users := NewUserRepository(db)
+	roles := NewRoleRepository(db)
+	emails := []string{"first@example.com", "second@example.com"}
+
+	first, err := users.Create(ctx, "first@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create first user: %v", err)
+	}
+
+	granted, err := roles.Bootstrap(ctx, emails)
+	if err != nil || granted != 1 {
+		t.Fatalf("expected first admin to be bootstrapped, got %d, %v", granted, err)
+	}
+
+	second, err := users.Create(ctx, "second@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create second user: %v", err)
+	}
+	if err := roles.Grant(ctx, second.ID, "admin", first.ID); err != nil {
+		t.Fatalf("grant second admin: %v", err)
+	}
+	if err := roles.Revoke(ctx, first.ID, "admin"); err != nil {
+		t.Fatalf("revoke first admin: %v", err)
+	}
+
+	granted, err = roles.Bootstrap(ctx, emails)
+	if err != nil || granted != 0 {
+		t.Fatalf("expected no grants once an admin exists, got %d, %v", granted, err)
+	}
+
+	firstRoles, err := roles.RolesForUser(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("roles for first user: %v", err)
+	}
+	if len(firstRoles) != 0 {
+		t.Fatalf("expected revoked admin to stay revoked, got %v", firstRoles)
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// signingKeyColumns перечисляет колонки signing_keys в порядке signingKeyScanDest.
+const signingKeyColumns = `id, algorithm, encrypted_private_key, activates_at, expires_at, created_at`
+
+func signingKeyScanDest(key *models.SigningKey) []any {
+	return []any{&key.ID, &key.Algorithm, &key.EncryptedPrivateKey, &key.ActivatesAt, &key.ExpiresAt, &key.CreatedAt}
+}
+
+type SigningKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewSigningKeyRepository создает репозиторий ключей подписи JWT.
+func NewSigningKeyRepository(db *pgxpool.Pool) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+// Latest возвращает ключ с самой поздней датой активации.
+func (r *SigningKeyRepository) Latest(ctx context.Context) (models.SigningKey, error) {
+	var key models.SigningKey
+
+	err := r.db.QueryRow(ctx,
+		`SELECT `+signingKeyColumns+`
+		 FROM signing_keys
+		 ORDER BY activates_at DESC, created_at DESC
+		 LIMIT 1`,
+	).Scan(signingKeyScanDest(&key)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return key, ErrNotFound
+		}
+		return key, err
+	}
+
+	return key, nil
+}
+
+// ListUsable возвращает ключи, которые еще проверяют подписи на момент now.
+func (r *SigningKeyRepository) ListUsable(ctx context.Context, now time.Time) ([]models.SigningKey, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+signingKeyColumns+`
+		 FROM signing_keys
+		 WHERE expires_at IS NULL OR expires_at > $1
+		 ORDER BY activates_at`,
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]models.SigningKey, 0)
+	for rows.Next() {
+		var key models.SigningKey
+		if err := rows.Scan(signingKeyScanDest(&key)...); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Rotate добавляет новый ключ, назначает предыдущим срок проверки
+// predecessorsExpireAt и удаляет истекшие ключи. previousID — ключ, который
+// вызывающий считал последним; если другой экземпляр уже выполнил ротацию,
+// ключ не добавляется и возвращается false.
+func (r *SigningKeyRepository) Rotate(ctx context.Context, key models.SigningKey, previousID string, predecessorsExpireAt time.Time) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('signing_keys'))`); err != nil {
+		return false, err
+	}
+
+	var latestID string
+	err = tx.QueryRow(ctx,
+		`SELECT id
+		 FROM signing_keys
+		 ORDER BY activates_at DESC, created_at DESC
+		 LIMIT 1`,
+	).Scan(&latestID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+	if latestID != previousID {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE signing_keys
+		 SET expires_at = $1
+		 WHERE expires_at IS NULL`,
+		predecessorsExpireAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO signing_keys (id, algorithm, encrypted_private_key, activates_at)
+		 VALUES ($1, $2, $3, $4)`,
+		key.ID, key.Algorithm, key.EncryptedPrivateKey, key.ActivatesAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM signing_keys WHERE expires_at <= NOW()`); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
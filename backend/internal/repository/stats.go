@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"example.com/ai-budget-planner/backend/internal/models"
@@ -20,19 +22,22 @@ type OverviewStats struct {
 	ArchivedPlans    int
 	TotalBudgetCents int64
 	TotalSpentCents  int64
+	UnconvertedPlans int
 }
 
 type CategorySpend struct {
 	CategoryID   uuid.UUID
 	Title        string
 	CategoryType models.CategoryType
+	PlannedCents int64
 	SpentCents   int64
 }
 
 type MonthlyComparison struct {
-	Month       time.Time
-	BudgetCents int64
-	SpentCents  int64
+	Month            time.Time
+	BudgetCents      int64
+	SpentCents       int64
+	UnconvertedPlans int
 }
 
 // NewStatsRepository создает репозиторий статистики.
@@ -40,36 +45,61 @@ func NewStatsRepository(db *pgxpool.Pool) *StatsRepository {
 	return &StatsRepository{db: db}
 }
 
-// Overview возвращает сводную статистику по планам пользователя.
+// Overview возвращает сводную статистику по планам, в которых участвует
+// пользователь, включая совместные. Суммы пересчитываются в базовую валюту
+// пользователя; планы без курса пересчета не входят в суммы и учитываются
+// в UnconvertedPlans.
 func (r *StatsRepository) Overview(ctx context.Context, userID uuid.UUID) (OverviewStats, error) {
 	var stats OverviewStats
 
 	err := r.db.QueryRow(ctx,
-		`SELECT COUNT(*) AS total_plans,
-		        COUNT(*) FILTER (WHERE period_end >= CURRENT_DATE) AS active_plans,
-		        COUNT(*) FILTER (WHERE period_end < CURRENT_DATE) AS archived_plans,
-		        COALESCE(SUM(budget_cents), 0) AS total_budget_cents
-		 FROM budget_plans
-		 WHERE user_id = $1`,
+		`WITH plan_spent AS (
+			SELECT p.id, p.currency, p.period_end, p.budget_cents,
+			       COALESCE(SUM(`+itemSpentExpr+`), 0) AS spent_cents
+			FROM budget_plans p
+			LEFT JOIN expense_categories c ON c.plan_id = p.id
+			LEFT JOIN expense_items i ON i.category_id = c.id
+			`+itemTransactionsJoin+`
+			WHERE `+memberOf("p.id", "$1")+`
+			GROUP BY p.id
+		), converted AS (
+			SELECT ps.period_end, ps.budget_cents, ps.spent_cents, `+planRateExpr+` AS rate
+			FROM plan_spent ps
+			JOIN users u ON u.id = $1
+			`+planRateJoin+`
+		)
+		SELECT COUNT(*) AS total_plans,
+		       COUNT(*) FILTER (WHERE period_end >= `+userTodayExpr+`) AS active_plans,
+		       COUNT(*) FILTER (WHERE period_end < `+userTodayExpr+`) AS archived_plans,
+		       COALESCE(ROUND(SUM(budget_cents * rate)), 0)::bigint AS total_budget_cents,
+		       COALESCE(ROUND(SUM(spent_cents * rate)), 0)::bigint AS total_spent_cents,
+		       COUNT(*) FILTER (WHERE rate IS NULL) AS unconverted_plans
+		FROM converted`,
 		userID,
-	).Scan(&stats.TotalPlans, &stats.ActivePlans, &stats.ArchivedPlans, &stats.TotalBudgetCents)
+	).Scan(&stats.TotalPlans, &stats.ActivePlans, &stats.ArchivedPlans, &stats.TotalBudgetCents, &stats.TotalSpentCents, &stats.UnconvertedPlans)
 	if err != nil {
 		return stats, err
 	}
 
-	err = r.db.QueryRow(ctx,
-		`SELECT COALESCE(SUM(CASE WHEN i.is_completed THEN i.amount_cents ELSE 0 END), 0)
-		 FROM budget_plans p
-		 LEFT JOIN expense_categories c ON c.plan_id = p.id
-		 LEFT JOIN expense_items i ON i.category_id = c.id
-		 WHERE p.user_id = $1`,
+	return stats, nil
+}
+
+// BaseCurrency возвращает валюту, в которую пересчитывается статистика пользователя.
+func (r *StatsRepository) BaseCurrency(ctx context.Context, userID uuid.UUID) (string, error) {
+	var baseCurrency string
+
+	err := r.db.QueryRow(ctx,
+		`SELECT base_currency FROM users WHERE id = $1`,
 		userID,
-	).Scan(&stats.TotalSpentCents)
+	).Scan(&baseCurrency)
 	if err != nil {
-		return stats, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
 	}
 
-	return stats, nil
+	return baseCurrency, nil
 }
 
 // SpendingByCategory возвращает траты по категориям в плане.
@@ -77,7 +107,7 @@ func (r *StatsRepository) SpendingByCategory(ctx context.Context, userID, planID
 	var exists bool
 	err := r.db.QueryRow(ctx,
 		`SELECT EXISTS (
-			SELECT 1 FROM budget_plans WHERE id = $1 AND user_id = $2
+			SELECT 1 FROM plan_members WHERE plan_id = $1 AND user_id = $2
 		 )`,
 		planID, userID,
 	).Scan(&exists)
@@ -88,11 +118,18 @@ func (r *StatsRepository) SpendingByCategory(ctx context.Context, userID, planID
 		return nil, ErrNotFound
 	}
 
-	rows, err := r.db.Query(ctx,
+	return listCategorySpending(ctx, r.db, planID)
+}
+
+// listCategorySpending возвращает плановые и фактические траты по категориям плана.
+func listCategorySpending(ctx context.Context, db *pgxpool.Pool, planID uuid.UUID) ([]CategorySpend, error) {
+	rows, err := db.Query(ctx,
 		`SELECT c.id, c.title, c.category_type,
-		        COALESCE(SUM(CASE WHEN i.is_completed THEN i.amount_cents ELSE 0 END), 0) AS spent_cents
+		        COALESCE(SUM(i.amount_cents), 0) AS planned_cents,
+		        COALESCE(SUM(`+itemSpentExpr+`), 0) AS spent_cents
 		 FROM expense_categories c
 		 LEFT JOIN expense_items i ON i.category_id = c.id
+		 `+itemTransactionsJoin+`
 		 WHERE c.plan_id = $1
 		 GROUP BY c.id, c.title, c.category_type, c.sort_order
 		 ORDER BY c.sort_order, c.created_at`,
@@ -106,7 +143,7 @@ func (r *StatsRepository) SpendingByCategory(ctx context.Context, userID, planID
 	spending := make([]CategorySpend, 0)
 	for rows.Next() {
 		var row CategorySpend
-		err := rows.Scan(&row.CategoryID, &row.Title, &row.CategoryType, &row.SpentCents)
+		err := rows.Scan(&row.CategoryID, &row.Title, &row.CategoryType, &row.PlannedCents, &row.SpentCents)
 		if err != nil {
 			return nil, err
 		}
@@ -120,7 +157,9 @@ func (r *StatsRepository) SpendingByCategory(ctx context.Context, userID, planID
 	return spending, nil
 }
 
-// MonthlyComparison возвращает сравнение бюджетов по месяцам.
+// MonthlyComparison возвращает сравнение бюджетов по месяцам в базовой
+// валюте пользователя по тем же планам, что и Overview. Планы без курса
+// пересчета не входят в суммы.
 func (r *StatsRepository) MonthlyComparison(ctx context.Context, userID uuid.UUID, months int) ([]MonthlyComparison, error) {
 	if months <= 0 {
 		return nil, ErrInvalid
@@ -128,20 +167,27 @@ func (r *StatsRepository) MonthlyComparison(ctx context.Context, userID uuid.UUI
 
 	rows, err := r.db.Query(ctx,
 		`WITH plan_spent AS (
-			SELECT p.id,
+			SELECT p.id, p.currency, p.period_end,
 			       date_trunc('month', p.period_start)::date AS month,
 			       p.budget_cents,
-			       COALESCE(SUM(CASE WHEN i.is_completed THEN i.amount_cents ELSE 0 END), 0) AS spent_cents
+			       COALESCE(SUM(`+itemSpentExpr+`), 0) AS spent_cents
 			FROM budget_plans p
 			LEFT JOIN expense_categories c ON c.plan_id = p.id
 			LEFT JOIN expense_items i ON i.category_id = c.id
-			WHERE p.user_id = $1
-			GROUP BY p.id, month, p.budget_cents
+			`+itemTransactionsJoin+`
+			WHERE `+memberOf("p.id", "$1")+`
+			GROUP BY p.id
+		), converted AS (
+			SELECT ps.month, ps.budget_cents, ps.spent_cents, `+planRateExpr+` AS rate
+			FROM plan_spent ps
+			JOIN users u ON u.id = $1
+			`+planRateJoin+`
 		)
 		SELECT month,
-		       COALESCE(SUM(budget_cents), 0) AS budget_cents,
-		       COALESCE(SUM(spent_cents), 0) AS spent_cents
-		FROM plan_spent
+		       COALESCE(ROUND(SUM(budget_cents * rate)), 0)::bigint AS budget_cents,
+		       COALESCE(ROUND(SUM(spent_cents * rate)), 0)::bigint AS spent_cents,
+		       COUNT(*) FILTER (WHERE rate IS NULL) AS unconverted_plans
+		FROM converted
 		GROUP BY month
 		ORDER BY month DESC
 		LIMIT $2`,
@@ -156,7 +202,7 @@ func (r *StatsRepository) MonthlyComparison(ctx context.Context, userID uuid.UUI
 	for rows.Next() {
 		var row MonthlyComparison
 		var month time.Time
-		err := rows.Scan(&month, &row.BudgetCents, &row.SpentCents)
+		err := rows.Scan(&month, &row.BudgetCents, &row.SpentCents, &row.UnconvertedPlans)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestStatsCurrencyConversion проверяет пересчет статистики в базовую валюту:
+// берется последний курс на конец периода плана, при его отсутствии —
+// обратный курс, а план без курса не входит в суммы.
+func TestStatsCurrencyConversion(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	plans := NewPlanRepository(db)
+	stats := NewStatsRepository(db)
+
+	user, err := NewUserRepository(db).Create(ctx, "rates@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	start, end := mustDate(t, "2024-01-01"), mustDate(t, "2024-01-31")
+	newPlan := func(currency string, budget int64) models.BudgetPlan {
+		t.Helper()
+
+		plan, err := plans.Create(ctx, user.ID, "План "+currency, budget, &currency, start, end, "#FFFFFF", false)
+		if err != nil {
+			t.Fatalf("create %s plan: %v", currency, err)
+		}
+		return plan
+	}
+
+	newPlan("RUB", 1000)
+	usd := newPlan("USD", 100)
+	newPlan("EUR", 100)
+	newPlan("GBP", 100)
+
+	category, err := NewCategoryRepository(db).Create(ctx, user.ID, usd.ID, "Расходы", models.CategoryTypeMandatory)
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	if _, err := NewItemRepository(db).Create(ctx, user.ID, usd.ID, category.ID, "Билеты", 10, models.PriorityColorRed, true); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+
+	rates := NewExchangeRateRepository(db)
+	// Курс на ту же дату перезаписывается, курс после конца периода не берется.
+	for _, batch := range [][]models.ExchangeRate{
+		{{FromCurrency: "USD", ToCurrency: "RUB", Rate: 80, EffectiveDate: mustDate(t, "2024-01-01")}},
+		{
+			{FromCurrency: "USD", ToCurrency: "RUB", Rate: 90, EffectiveDate: mustDate(t, "2024-01-01")},
+			{FromCurrency: "USD", ToCurrency: "RUB", Rate: 100, EffectiveDate: mustDate(t, "2024-02-01")},
+			{FromCurrency: "RUB", ToCurrency: "EUR", Rate: 0.01, EffectiveDate: mustDate(t, "2024-01-10")},
+		},
+	} {
+		if err := rates.Upsert(ctx, batch); err != nil {
+			t.Fatalf("upsert rates: %v", err)
+		}
+	}
+
+	overview, err := stats.Overview(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("overview: %v", err)
+	}
+	// 1000 RUB + 100 USD * 90 + 100 EUR * (1 / 0.01); GBP без курса.
+	if overview.TotalPlans != 4 || overview.TotalBudgetCents != 20000 || overview.TotalSpentCents != 900 || overview.UnconvertedPlans != 1 {
+		t.Fatalf("unexpected overview: %+v", overview)
+	}
+
+	monthly, err := stats.MonthlyComparison(ctx, user.ID, 12)
+	if err != nil {
+		t.Fatalf("monthly comparison: %v", err)
+	}
+	if len(monthly) != 1 || monthly[0].BudgetCents != 20000 || monthly[0].SpentCents != 900 || monthly[0].UnconvertedPlans != 1 {
+		t.Fatalf("unexpected monthly comparison: %+v", monthly)
+	}
+}
+
+// TestExchangeRateUpsertRejectsInvalid проверяет, что пакет с неверным курсом
+// не сохраняется целиком.
+func TestExchangeRateUpsertRejectsInvalid(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	rates := NewExchangeRateRepository(db)
+
+	err := rates.Upsert(ctx, []models.ExchangeRate{
+		{FromCurrency: "USD", ToCurrency: "RUB", Rate: 90, EffectiveDate: mustDate(t, "2024-01-01")},
+		{FromCurrency: "EUR", ToCurrency: "EUR", Rate: 1, EffectiveDate: mustDate(t, "2024-01-01")},
+	})
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+
+	stored, err := rates.List(ctx, nil, 10, 0)
+	if err != nil || len(stored) != 0 {
+		t.Fatalf("expected no stored rates, got %+v, %v", stored, err)
+	}
+}
+
+// TestStatsIncludeSharedPlans проверяет, что обзор и сравнение по месяцам
+// учитывают совместные планы участника так же, как статистика по плану.
+func TestStatsIncludeSharedPlans(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	users := NewUserRepository(db)
+	members := NewMemberRepository(db)
+	stats := NewStatsRepository(db)
+
+	owner, err := users.Create(ctx, "owner@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+	viewer, err := users.Create(ctx, "viewer@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create viewer: %v", err)
+	}
+
+	plan, err := NewPlanRepository(db).Create(ctx, owner.ID, "Общий план", 5000, nil, mustDate(t, "2024-01-01"), mustDate(t, "2024-01-31"), "#FFFFFF", false)
+	if err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	if _, err := members.CreateInvite(ctx, owner.ID, plan.ID, viewer.Email, models.PlanRoleViewer, "invite-hash", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("invite viewer: %v", err)
+	}
+	if _, err := members.AcceptInvite(ctx, viewer.ID, "invite-hash"); err != nil {
+		t.Fatalf("accept invite: %v", err)
+	}
+
+	overview, err := stats.Overview(ctx, viewer.ID)
+	if err != nil {
+		t.Fatalf("overview: %v", err)
+	}
+	if overview.TotalPlans != 1 || overview.TotalBudgetCents != 5000 {
+		t.Fatalf("expected the shared plan in the overview, got %+v", overview)
+	}
+	monthly, err := stats.MonthlyComparison(ctx, viewer.ID, 12)
+	if err != nil {
+		t.Fatalf("monthly comparison: %v", err)
+	}
+	if len(monthly) != 1 || monthly[0].BudgetCents != 5000 {
+		t.Fatalf("expected the shared plan in the monthly comparison, got %+v", monthly)
+	}
+
+	if err := members.Remove(ctx, owner.ID, plan.ID, viewer.ID); err != nil {
+		t.Fatalf("remove viewer: %v", err)
+	}
+	overview, err = stats.Overview(ctx, viewer.ID)
+	if err != nil || overview.TotalPlans != 0 {
+		t.Fatalf("expected no plans after removal, got %+v, %v", overview, err)
+	}
+}
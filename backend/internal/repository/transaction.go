@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// itemTransactionsJoin подключает к expense_items i сумму транзакций по расходу.
+const itemTransactionsJoin = `LEFT JOIN LATERAL (
+			SELECT SUM(tr.amount_cents) AS spent_cents
+			FROM transactions tr
+			WHERE tr.item_id = i.id
+		 ) t ON TRUE`
+
+// itemSpentExpr считает фактические траты по расходу: сумму транзакций,
+// а для расходов без транзакций — плановую сумму, если расход отмечен выполненным.
+const itemSpentExpr = `COALESCE(t.spent_cents, CASE WHEN i.is_completed THEN i.amount_cents ELSE 0 END)`
+
+type TransactionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewTransactionRepository создает репозиторий фактических транзакций.
+func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// GetPlanIDByTransactionID возвращает план и расход, к которым относится транзакция.
+func (r *TransactionRepository) GetPlanIDByTransactionID(ctx context.Context, userID, transactionID uuid.UUID) (uuid.UUID, uuid.UUID, error) {
+	var planID uuid.UUID
+	var itemID uuid.UUID
+
+	err := r.db.QueryRow(ctx,
+		`SELECT p.id, i.id
+		 FROM transactions t
+		 JOIN expense_items i ON i.id = t.item_id
+		 JOIN expense_categories c ON c.id = i.category_id
+		 JOIN budget_plans p ON p.id = c.plan_id
+		 WHERE t.id = $1 AND `+memberOf("p.id", "$2"),
+		transactionID, userID,
+	).Scan(&planID, &itemID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, uuid.Nil, ErrNotFound
+		}
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return planID, itemID, nil
+}
+
+// ListByItem возвращает транзакции расхода в хронологическом порядке.
+func (r *TransactionRepository) ListByItem(ctx context.Context, userID, itemID uuid.UUID) ([]models.Transaction, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT t.id, t.item_id, t.amount_cents, t.occurred_on, t.merchant, t.memo, t.created_at, t.updated_at
+		 FROM transactions t
+		 JOIN expense_items i ON i.id = t.item_id
+		 JOIN expense_categories c ON c.id = i.category_id
+		 JOIN budget_plans p ON p.id = c.plan_id
+		 WHERE t.item_id = $1 AND `+memberOf("p.id", "$2")+`
+		 ORDER BY t.occurred_on, t.created_at`,
+		itemID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+	for rows.Next() {
+		var transaction models.Transaction
+
+		err := rows.Scan(&transaction.ID, &transaction.ItemID, &transaction.AmountCents, &transaction.OccurredOn, &transaction.Merchant, &transaction.Memo, &transaction.CreatedAt, &transaction.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// Create записывает фактическую оплату по расходу.
+func (r *TransactionRepository) Create(ctx context.Context, userID, itemID uuid.UUID, amountCents int64, occurredOn time.Time, merchant, memo *string) (models.Transaction, error) {
+	var transaction models.Transaction
+
+	if _, err := authorizePlan(ctx, r.db, planByItemID, itemID, userID, editorRoles); err != nil {
+		return transaction, err
+	}
+
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO transactions (id, item_id, amount_cents, occurred_on, merchant, memo)
+		 SELECT $1, i.id, $3, $4, $5, $6
+		 FROM expense_items i
+		 JOIN expense_categories c ON c.id = i.category_id
+		 JOIN budget_plans p ON p.id = c.plan_id
+		 WHERE i.id = $2 AND `+editorOf("p.id", "$7")+`
+		 RETURNING id, item_id, amount_cents, occurred_on, merchant, memo, created_at, updated_at`,
+		uuid.New(), itemID, amountCents, occurredOn, merchant, memo, userID,
+	).Scan(&transaction.ID, &transaction.ItemID, &transaction.AmountCents, &transaction.OccurredOn, &transaction.Merchant, &transaction.Memo, &transaction.CreatedAt, &transaction.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transaction, ErrNotFound
+		}
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+// Update изменяет транзакцию.
+func (r *TransactionRepository) Update(ctx context.Context, userID, transactionID uuid.UUID, amountCents int64, occurredOn time.Time, merchant, memo *string) (models.Transaction, error) {
+	var transaction models.Transaction
+
+	if _, err := authorizePlan(ctx, r.db, planByTransactionID, transactionID, userID, editorRoles); err != nil {
+		return transaction, err
+	}
+
+	err := r.db.QueryRow(ctx,
+		`UPDATE transactions t
+		 SET amount_cents = $2,
+		     occurred_on = $3,
+		     merchant = $4,
+		     memo = $5,
+		     updated_at = NOW()
+		 FROM expense_items i
+		 JOIN expense_categories c ON c.id = i.category_id
+		 JOIN budget_plans p ON p.id = c.plan_id
+		 WHERE t.id = $1
+		   AND t.item_id = i.id
+		   AND `+editorOf("p.id", "$6")+`
+		 RETURNING t.id, t.item_id, t.amount_cents, t.occurred_on, t.merchant, t.memo, t.created_at, t.updated_at`,
+		transactionID, amountCents, occurredOn, merchant, memo, userID,
+	).Scan(&transaction.ID, &transaction.ItemID, &transaction.AmountCents, &transaction.OccurredOn, &transaction.Merchant, &transaction.Memo, &transaction.CreatedAt, &transaction.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transaction, ErrNotFound
+		}
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+// Delete удаляет транзакцию.
+func (r *TransactionRepository) Delete(ctx context.Context, userID, transactionID uuid.UUID) error {
+	if _, err := authorizePlan(ctx, r.db, planByTransactionID, transactionID, userID, editorRoles); err != nil {
+		return err
+	}
+
+	cmd, err := r.db.Exec(ctx,
+		`DELETE FROM transactions t
+		 USING expense_items i, expense_categories c, budget_plans p
+		 WHERE t.id = $1
+		   AND t.item_id = i.id
+		   AND i.category_id = c.id
+		   AND c.plan_id = p.id
+		   AND `+editorOf("p.id", "$2"),
+		transactionID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestSpentAggregation проверяет подсчет фактических трат: сумма транзакций
+// расхода, а без транзакций — плановая сумма выполненного расхода.
+func TestSpentAggregation(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	plans := NewPlanRepository(db)
+	items := NewItemRepository(db)
+	transactions := NewTransactionRepository(db)
+
+	user, err := NewUserRepository(db).Create(ctx, "spent@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	now := time.Now()
+	plan, err := plans.Create(ctx, user.ID, "План", 100000, nil, now, now.AddDate(0, 1, 0), "#FFFFFF", false)
+	if err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	category, err := NewCategoryRepository(db).Create(ctx, user.ID, plan.ID, "Расходы", models.CategoryTypeMandatory)
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	newItem := func(title string, amount int64, completed bool) models.ExpenseItem {
+		t.Helper()
+
+		item, err := items.Create(ctx, user.ID, plan.ID, category.ID, title, amount, models.PriorityColorRed, completed)
+		if err != nil {
+			t.Fatalf("create item %s: %v", title, err)
+		}
+		return item
+	}
+	pay := func(item models.ExpenseItem, amount int64) models.Transaction {
+		t.Helper()
+
+		transaction, err := transactions.Create(ctx, user.ID, item.ID, amount, now, nil, nil)
+		if err != nil {
+			t.Fatalf("create transaction for %s: %v", item.Title, err)
+		}
+		return transaction
+	}
+
+	partial := newItem("Частично", 10000, false)
+	completed := newItem("Выполнен", 3000, true)
+	completedPaid := newItem("Выполнен с оплатой", 2000, true)
+	unpaid := newItem("Не оплачен", 4000, false)
+
+	first := pay(partial, 1500)
+	pay(partial, 2500)
+	pay(completedPaid, 500)
+
+	expected := map[uuid.UUID]int64{partial.ID: 4000, completed.ID: 3000, completedPaid.ID: 500, unpaid.ID: 0}
+	spent, err := plans.ListItemSpentCents(ctx, []uuid.UUID{category.ID})
+	if err != nil {
+		t.Fatalf("list item spent: %v", err)
+	}
+	for itemID, want := range expected {
+		if spent[itemID] != want {
+			t.Fatalf("item %s: expected spent %d, got %d", itemID, want, spent[itemID])
+		}
+	}
+
+	total, err := plans.GetSpentCents(ctx, plan.ID)
+	if err != nil || total != 7500 {
+		t.Fatalf("expected plan spent 7500, got %d, %v", total, err)
+	}
+
+	byCategory, err := NewStatsRepository(db).SpendingByCategory(ctx, user.ID, plan.ID)
+	if err != nil {
+		t.Fatalf("spending by category: %v", err)
+	}
+	if len(byCategory) != 1 || byCategory[0].PlannedCents != 19000 || byCategory[0].SpentCents != 7500 {
+		t.Fatalf("unexpected spending by category: %+v", byCategory)
+	}
+
+	if _, err := transactions.Update(ctx, user.ID, first.ID, 1000, now, nil, nil); err != nil {
+		t.Fatalf("update transaction: %v", err)
+	}
+	if itemSpent, err := items.GetSpentCents(ctx, partial.ID); err != nil || itemSpent != 3500 {
+		t.Fatalf("expected 3500 after update, got %d, %v", itemSpent, err)
+	}
+
+	if err := transactions.Delete(ctx, user.ID, first.ID); err != nil {
+		t.Fatalf("delete transaction: %v", err)
+	}
+	if itemSpent, err := items.GetSpentCents(ctx, partial.ID); err != nil || itemSpent != 2500 {
+		t.Fatalf("expected 2500 after delete, got %d, %v", itemSpent, err)
+	}
+}
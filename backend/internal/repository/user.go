@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -12,6 +13,16 @@ import (
 	"example.com/ai-budget-planner/backend/internal/models"
 )
 
+// userColumns перечисляет колонки users в порядке userScanDest.
+const userColumns = `id, email, password_hash, name, base_currency, locale, time_zone, week_start, pending_email,
+	email_verified_at, totp_secret, totp_enabled_at, failed_login_attempts, locked_until, deleted_at, created_at, updated_at`
+
+// userScanDest возвращает поля пользователя для Scan в порядке userColumns.
+func userScanDest(user *models.User) []any {
+	return []any{&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.BaseCurrency, &user.Locale, &user.TimeZone, &user.WeekStart, &user.PendingEmail,
+		&user.EmailVerifiedAt, &user.TOTPSecret, &user.TOTPEnabledAt, &user.FailedLogins, &user.LockedUntil, &user.DeletedAt, &user.CreatedAt, &user.UpdatedAt}
+}
+
 type UserRepository struct {
 	db *pgxpool.Pool
 }
@@ -24,14 +35,13 @@ func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 // Create создает пользователя в базе.
 func (r *UserRepository) Create(ctx context.Context, email, passwordHash string, name *string) (models.User, error) {
 	var user models.User
-	var nameValue *string
 
 	err := r.db.QueryRow(ctx,
 		`INSERT INTO users (email, password_hash, name)
 		 VALUES ($1, $2, $3)
-		 RETURNING id, email, password_hash, name, created_at, updated_at`,
+		 RETURNING `+userColumns,
 		email, passwordHash, name,
-	).Scan(&user.ID, &user.Email, &user.PasswordHash, &nameValue, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(userScanDest(&user)...)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -40,21 +50,19 @@ func (r *UserRepository) Create(ctx context.Context, email, passwordHash string,
 		return user, err
 	}
 
-	user.Name = nameValue
 	return user, nil
 }
 
 // GetByEmail возвращает пользователя по email.
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (models.User, error) {
 	var user models.User
-	var nameValue *string
 
 	err := r.db.QueryRow(ctx,
-		`SELECT id, email, password_hash, name, created_at, updated_at
+		`SELECT `+userColumns+`
 		 FROM users
 		 WHERE email = $1`,
 		email,
-	).Scan(&user.ID, &user.Email, &user.PasswordHash, &nameValue, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(userScanDest(&user)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return user, ErrNotFound
@@ -62,21 +70,19 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (models.U
 		return user, err
 	}
 
-	user.Name = nameValue
 	return user, nil
 }
 
 // GetByID возвращает пользователя по идентификатору.
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (models.User, error) {
 	var user models.User
-	var nameValue *string
 
 	err := r.db.QueryRow(ctx,
-		`SELECT id, email, password_hash, name, created_at, updated_at
+		`SELECT `+userColumns+`
 		 FROM users
 		 WHERE id = $1`,
 		id,
-	).Scan(&user.ID, &user.Email, &user.PasswordHash, &nameValue, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(userScanDest(&user)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return user, ErrNotFound
@@ -84,6 +90,177 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (models.User
 		return user, err
 	}
 
-	user.Name = nameValue
 	return user, nil
 }
+
+// ResetPassword погашает токен сброса пароля, задает новый пароль, снимает
+// блокировку входа и отзывает все refresh-токены пользователя. Возвращает
+// ErrNotFound, если токен не найден, уже использован или истек.
+func (r *UserRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (models.User, error) {
+	var user models.User
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return user, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	userID, err := consumeUserToken(ctx, tx, models.UserTokenPasswordReset, tokenHash)
+	if err != nil {
+		return user, err
+	}
+
+	// Письмо со ссылкой сброса дошло до владельца адреса, значит email подтвержден.
+	err = tx.QueryRow(ctx,
+		`UPDATE users
+		 SET password_hash = $2,
+		     email_verified_at = COALESCE(email_verified_at, NOW()),
+		     failed_login_attempts = 0,
+		     last_failed_login_at = NULL,
+		     locked_until = NULL,
+		     updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+userColumns,
+		userID, passwordHash,
+	).Scan(userScanDest(&user)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, ErrNotFound
+		}
+		return user, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE refresh_tokens
+		 SET revoked_at = NOW()
+		 WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return user, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// VerifyEmail погашает токен подтверждения и отмечает email пользователя подтвержденным.
+func (r *UserRepository) VerifyEmail(ctx context.Context, tokenHash string) (models.User, error) {
+	var user models.User
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return user, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	userID, err := consumeUserToken(ctx, tx, models.UserTokenEmailVerification, tokenHash)
+	if err != nil {
+		return user, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`UPDATE users
+		 SET email_verified_at = COALESCE(email_verified_at, NOW()),
+		     updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+userColumns,
+		userID,
+	).Scan(userScanDest(&user)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, ErrNotFound
+		}
+		return user, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// RecordLoginFailure увеличивает счетчик неудачных входов и возвращает его.
+// Если прошлая неудача была раньше windowStart, счет начинается заново.
+func (r *UserRepository) RecordLoginFailure(ctx context.Context, id uuid.UUID, windowStart time.Time) (int, error) {
+	var failures int
+
+	err := r.db.QueryRow(ctx,
+		`UPDATE users
+		 SET failed_login_attempts = CASE
+		         WHEN last_failed_login_at IS NULL OR last_failed_login_at < $2 THEN 1
+		         ELSE failed_login_attempts + 1
+		     END,
+		     last_failed_login_at = NOW()
+		 WHERE id = $1
+		 RETURNING failed_login_attempts`,
+		id, windowStart,
+	).Scan(&failures)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+// LockLogin закрывает вход пользователю до until.
+func (r *UserRepository) LockLogin(ctx context.Context, id uuid.UUID, until time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE users
+		 SET locked_until = GREATEST(locked_until, $2)
+		 WHERE id = $1`,
+		id, until,
+	)
+	return err
+}
+
+// ResetLoginFailures сбрасывает счетчик неудачных входов и снимает блокировку.
+// Возвращает ErrNotFound, если пользователя нет.
+func (r *UserRepository) ResetLoginFailures(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE users
+		 SET failed_login_attempts = 0,
+		     last_failed_login_at = NULL,
+		     locked_until = NULL
+		 WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CancelDeletion снимает с учетной записи пометку об удалении.
+func (r *UserRepository) CancelDeletion(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE users
+		 SET deleted_at = NULL,
+		     updated_at = NOW()
+		 WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
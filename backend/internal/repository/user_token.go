@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+type UserTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewUserTokenRepository создает репозиторий одноразовых токенов пользователей
+// (сброс пароля, подтверждение email).
+func NewUserTokenRepository(db *pgxpool.Pool) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// Create сохраняет хэш нового токена. Неиспользованные токены пользователя
+// с тем же назначением перестают действовать.
+func (r *UserTokenRepository) Create(ctx context.Context, userID uuid.UUID, purpose models.UserTokenPurpose, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx,
+		`UPDATE user_tokens
+		 SET used_at = NOW()
+		 WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		userID, purpose,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+		 VALUES ($1, $2, $3, $4)`,
+		userID, purpose, tokenHash, expiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// consumeUserToken отмечает действующий токен использованным и возвращает его владельца.
+func consumeUserToken(ctx context.Context, q querier, purpose models.UserTokenPurpose, tokenHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+
+	err := q.QueryRow(ctx,
+		`UPDATE user_tokens
+		 SET used_at = NOW()
+		 WHERE token_hash = $1
+		   AND purpose = $2
+		   AND used_at IS NULL
+		   AND expires_at > NOW()
+		 RETURNING user_id`,
+		tokenHash, purpose,
+	).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrNotFound
+		}
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+)
+
+// webhookEndpointColumns перечисляет колонки webhook_endpoints в порядке webhookEndpointScanDest.
+const webhookEndpointColumns = `id, user_id, url, secret, event_types, created_at, updated_at`
+
+func webhookEndpointScanDest(endpoint *models.WebhookEndpoint) []any {
+	return []any{&endpoint.ID, &endpoint.UserID, &endpoint.URL, &endpoint.Secret, &endpoint.EventTypes, &endpoint.CreatedAt, &endpoint.UpdatedAt}
+}
+
+// webhookDeliveryColumns перечисляет колонки webhook_deliveries в порядке webhookDeliveryScanDest.
+const webhookDeliveryColumns = `d.id, d.endpoint_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+	d.last_status_code, d.last_error, d.delivered_at, d.created_at`
+
+func webhookDeliveryScanDest(delivery *models.WebhookDelivery) []any {
+	return []any{&delivery.ID, &delivery.EndpointID, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.Attempts,
+		&delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.DeliveredAt, &delivery.CreatedAt}
+}
+
+// DueWebhookDelivery — доставка, готовая к отправке, вместе с адресом и секретом endpoint.
+type DueWebhookDelivery struct {
+	Delivery models.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewWebhookRepository создает репозиторий вебхуков и очереди их доставки.
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// CreateEndpoint регистрирует endpoint пользователя. Пустой eventTypes означает все события.
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, userID uuid.UUID, url, secret string, eventTypes []string) (models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO webhook_endpoints (user_id, url, secret, event_types)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+webhookEndpointColumns,
+		userID, url, secret, eventTypes,
+	).Scan(webhookEndpointScanDest(&endpoint)...)
+	if err != nil {
+		return endpoint, err
+	}
+
+	return endpoint, nil
+}
+
+// CountEndpoints возвращает число endpoint пользователя.
+func (r *WebhookRepository) CountEndpoints(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM webhook_endpoints WHERE user_id = $1`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+// ListEndpoints возвращает endpoint пользователя.
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, userID uuid.UUID) ([]models.WebhookEndpoint, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+webhookEndpointColumns+`
+		 FROM webhook_endpoints
+		 WHERE user_id = $1
+		 ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := make([]models.WebhookEndpoint, 0)
+	for rows.Next() {
+		var endpoint models.WebhookEndpoint
+		if err := rows.Scan(webhookEndpointScanDest(&endpoint)...); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+// DeleteEndpoint удаляет endpoint пользователя вместе с журналом доставок.
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error {
+	cmd, err := r.db.Exec(ctx,
+		`DELETE FROM webhook_endpoints
+		 WHERE id = $1 AND user_id = $2`,
+		endpointID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveries возвращает доставки endpoint, начиная с новых.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, userID, endpointID uuid.UUID, limit, offset int) ([]models.WebhookDelivery, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM webhook_endpoints WHERE id = $1 AND user_id = $2)`,
+		endpointID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT `+webhookDeliveryColumns+`
+		 FROM webhook_deliveries d
+		 WHERE d.endpoint_id = $1
+		 ORDER BY d.created_at DESC
+		 LIMIT $2 OFFSET $3`,
+		endpointID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := rows.Scan(webhookDeliveryScanDest(&delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// EnqueueDeliveries ставит событие в очередь доставки на все endpoint пользователя,
+// подписанные на eventType. Возвращает число созданных доставок.
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, userID uuid.UUID, eventType string, payload []byte) (int64, error) {
+	cmd, err := r.db.Exec(ctx,
+		`INSERT INTO webhook_deliveries (endpoint_id, event_type, payload)
+		 SELECT id, $2, $3
+		 FROM webhook_endpoints
+		 WHERE user_id = $1
+		   AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))`,
+		userID, eventType, payload,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
+
+// ClaimDueDeliveries забирает до limit доставок, время попытки которых наступило,
+// и откладывает их следующую попытку на lease, чтобы другие экземпляры
+// сервиса не отправили их повторно, пока идет текущая попытка.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]DueWebhookDelivery, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE webhook_deliveries d
+		 SET next_attempt_at = $2,
+		     updated_at = NOW()
+		 FROM webhook_endpoints e
+		 WHERE e.id = d.endpoint_id
+		   AND d.id IN (
+		       SELECT id
+		       FROM webhook_deliveries
+		       WHERE status = 'pending' AND next_attempt_at <= $1
+		       ORDER BY next_attempt_at
+		       LIMIT $3
+		       FOR UPDATE SKIP LOCKED
+		   )
+		 RETURNING `+webhookDeliveryColumns+`, e.url, e.secret`,
+		now, now.Add(lease), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := make([]DueWebhookDelivery, 0)
+	for rows.Next() {
+		var item DueWebhookDelivery
+		dest := append(webhookDeliveryScanDest(&item.Delivery), &item.URL, &item.Secret)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		due = append(due, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return due, nil
+}
+
+// MarkDelivered отмечает успешную доставку.
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, deliveryID uuid.UUID, statusCode int) error {
+	return r.recordAttempt(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = 'delivered',
+		     attempts = attempts + 1,
+		     last_status_code = $2,
+		     last_error = NULL,
+		     delivered_at = NOW(),
+		     updated_at = NOW()
+		 WHERE id = $1`,
+		deliveryID, statusCode,
+	)
+}
+
+// MarkFailed записывает неудачную попытку. Если nextAttemptAt не задан,
+// попытки исчерпаны и доставка переводится в статус failed.
+func (r *WebhookRepository) MarkFailed(ctx context.Context, deliveryID uuid.UUID, statusCode *int, message string, nextAttemptAt *time.Time) error {
+	return r.recordAttempt(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		     attempts = attempts + 1,
+		     last_status_code = $2,
+		     last_error = $3,
+		     next_attempt_at = COALESCE($4, next_attempt_at),
+		     updated_at = NOW()
+		 WHERE id = $1`,
+		deliveryID, statusCode, message, nextAttemptAt,
+	)
+}
+
+func (r *WebhookRepository) recordAttempt(ctx context.Context, query string, args ...any) error {
+	cmd, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteFinishedBefore удаляет завершенные доставки старше cutoff и возвращает число удаленных.
+func (r *WebhookRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	cmd, err := r.db.Exec(ctx,
+		`DELETE FROM webhook_deliveries
+		 WHERE status <> 'pending' AND created_at < $1`,
+		cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
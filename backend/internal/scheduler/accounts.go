@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+// AccountPurgeJob окончательно удаляет учетные записи, удаление которых не
+// было отменено в течение grace.
+func AccountPurgeJob(accounts *repository.AccountRepository, logger *slog.Logger, interval, grace time.Duration) Job {
+	return Job{
+		Name:     "account_purge",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			purged, err := accounts.PurgeDeleted(ctx, time.Now().Add(-grace))
+			if err != nil {
+				return err
+			}
+
+			if purged > 0 {
+				logger.Info("deleted accounts purged", slog.Int64("purged", purged))
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+// NotificationPruneJob удаляет из журнала уведомлений события старше retention.
+func NotificationPruneJob(events *repository.NotificationRepository, logger *slog.Logger, interval, retention time.Duration) Job {
+	return Job{
+		Name:     "notification_prune",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			deleted, err := events.DeleteBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return err
+			}
+
+			if deleted > 0 {
+				logger.Info("notification events pruned", slog.Int64("deleted", deleted))
+			}
+
+			return nil
+		},
+	}
+}
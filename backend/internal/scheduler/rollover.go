@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const (
+	rolloverBatchSize = 100
+
+	// Задержка повторного переноса после неудачи: удваивается с каждой
+	// попыткой, пока не упрется в rolloverRetryMaxDelay.
+	rolloverRetryBaseDelay = 5 * time.Minute
+	rolloverRetryMaxDelay  = 24 * time.Hour
+)
+
+// PlanRolloverJob создает планы следующего периода для повторяющихся планов,
+// период которых закончился.
+func PlanRolloverJob(plans *repository.PlanRepository, logger *slog.Logger, interval time.Duration) Job {
+	return Job{
+		Name:     "plan_rollover",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			return rolloverDuePlans(ctx, plans, logger, rolloverBatchSize)
+		},
+	}
+}
+
+// rolloverDuePlans переносит до limit планов, период которых закончился.
+// Неудачный перенос откладывает план, чтобы он не занимал место в следующих
+// пакетах и не мешал переносу остальных.
+func rolloverDuePlans(ctx context.Context, plans *repository.PlanRepository, logger *slog.Logger, limit int) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	due, err := plans.ListDueRecurring(ctx, today, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, plan := range due {
+		next, err := plans.Rollover(ctx, plan.ID)
+		if err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				continue
+			}
+			logger.Error("plan rollover failed",
+				slog.String("plan_id", plan.ID.String()),
+				slog.String("error", err.Error()),
+			)
+
+			if err := plans.RecordRolloverFailure(ctx, plan.ID, rolloverRetryBaseDelay, rolloverRetryMaxDelay); err != nil {
+				logger.Error("plan rollover failure not recorded",
+					slog.String("plan_id", plan.ID.String()),
+					slog.String("error", err.Error()),
+				)
+			}
+			continue
+		}
+
+		logger.Info("plan rolled over",
+			slog.String("plan_id", plan.ID.String()),
+			slog.String("next_plan_id", next.ID.String()),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/testdb"
+)
+
+// TestRolloverFailureDoesNotBlockOthers проверяет, что план, перенос которого
+// падает, откладывается и не занимает пакет: следующий запуск переносит
+// остальные планы.
+func TestRolloverFailureDoesNotBlockOthers(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	plans := repository.NewPlanRepository(db)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	user, err := repository.NewUserRepository(db).Create(ctx, "rollover@example.com", "hash", nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	recurring := func(title string, start, end time.Time) uuid.UUID {
+		t.Helper()
+
+		plan, err := plans.Create(ctx, user.ID, title, 10000, nil, start, end, "#FFFFFF", false)
+		if err != nil {
+			t.Fatalf("create plan %s: %v", title, err)
+		}
+		if _, err := plans.UpdateRecurrence(ctx, user.ID, plan.ID, models.RecurrenceMonthly, nil, false); err != nil {
+			t.Fatalf("update recurrence %s: %v", title, err)
+		}
+		return plan.ID
+	}
+
+	// Сломанный план закончился раньше и без отсрочки всегда шел бы первым.
+	broken := recurring("Сломанный", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))
+	healthy := recurring("Рабочий", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+
+	for _, statement := range []string{
+		`CREATE FUNCTION reject_broken_plan() RETURNS trigger AS $$
+		 BEGIN
+		     RAISE EXCEPTION 'broken plan';
+		 END
+		 $$ LANGUAGE plpgsql`,
+		`CREATE TRIGGER budget_plans_broken BEFORE INSERT ON budget_plans
+		 FOR EACH ROW WHEN (NEW.title = 'Сломанный') EXECUTE FUNCTION reject_broken_plan()`,
+	} {
+		if _, err := db.Exec(ctx, statement); err != nil {
+			t.Fatalf("install broken plan trigger: %v", err)
+		}
+	}
+
+	for run := 0; run < 2; run++ {
+		if err := rolloverDuePlans(ctx, plans, logger, 1); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+	}
+
+	var (
+		nextPlanID *uuid.UUID
+		attempts   int
+		retryAt    *time.Time
+	)
+	err = db.QueryRow(ctx,
+		`SELECT next_plan_id, rollover_attempts, rollover_retry_at FROM budget_plans WHERE id = $1`,
+		broken,
+	).Scan(&nextPlanID, &attempts, &retryAt)
+	if err != nil {
+		t.Fatalf("get broken plan: %v", err)
+	}
+	if nextPlanID != nil || attempts != 1 || retryAt == nil || !retryAt.After(time.Now()) {
+		t.Fatalf("expected one failed attempt postponed to the future, got next %v, attempts %d, retry at %v", nextPlanID, attempts, retryAt)
+	}
+
+	if err := db.QueryRow(ctx, `SELECT next_plan_id FROM budget_plans WHERE id = $1`, healthy).Scan(&nextPlanID); err != nil {
+		t.Fatalf("get healthy plan: %v", err)
+	}
+	if nextPlanID == nil {
+		t.Fatal("expected the healthy plan to roll over despite the broken one")
+	}
+}
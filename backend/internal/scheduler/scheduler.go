@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job описывает периодическую фоновую задачу.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	logger *slog.Logger
+	jobs   []Job
+	wg     sync.WaitGroup
+}
+
+// New создает планировщик фоновых задач.
+func New(logger *slog.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Add регистрирует задачу. Задачи нужно добавить до вызова Start.
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start запускает все задачи: каждая выполняется сразу и затем с заданным
+// интервалом, пока не будет отменен ctx.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait ждет завершения всех задач после отмены контекста.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.logger.Error("scheduled job panicked",
+				slog.String("job", job.Name),
+				slog.String("panic", fmt.Sprint(recovered)),
+			)
+		}
+	}()
+
+	startedAt := time.Now()
+	if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+		s.logger.Error("scheduled job failed",
+			slog.String("job", job.Name),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	s.logger.Debug("scheduled job finished",
+		slog.String("job", job.Name),
+		slog.Duration("duration", time.Since(startedAt)),
+	)
+}
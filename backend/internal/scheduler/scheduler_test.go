@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSchedulerRunsJobUntilCancelled проверяет повторный запуск задачи и остановку по контексту.
+func TestSchedulerRunsJobUntilCancelled(t *testing.T) {
+	var runs atomic.Int32
+	s := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.Add(Job{
+		Name:     "test",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return errors.New("ignored")
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+
+	deadline := time.After(time.Second)
+	for runs.Load() < 3 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected at least 3 runs, got %d", runs.Load())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	s.Wait()
+
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Fatal("expected job to stop after cancel")
+	}
+}
+
+// TestSchedulerRecoversPanic проверяет, что паника в задаче не останавливает планировщик.
+func TestSchedulerRecoversPanic(t *testing.T) {
+	var runs atomic.Int32
+	s := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.Add(Job{
+		Name:     "panic",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			panic("boom")
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+
+	deadline := time.After(time.Second)
+	for runs.Load() < 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected job to keep running after panic, got %d runs", runs.Load())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	s.Wait()
+}
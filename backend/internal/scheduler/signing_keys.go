@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"example.com/ai-budget-planner/backend/internal/auth"
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+// signingKeyPublishLead — за сколько до активации новый ключ появляется в JWKS,
+// чтобы все экземпляры и внешние сервисы успели его загрузить.
+const signingKeyPublishLead = 15 * time.Minute
+
+// SigningKeyJob выпускает новый ключ подписи JWT раз в rotateEvery и загружает
+// действующие ключи из базы в keys. Предыдущие ключи проверяют подписи еще
+// verifyFor после активации преемника — столько живет самый долгий токен.
+// Закрытые ключи хранятся в базе зашифрованными encryptionKey.
+func SigningKeyJob(store *repository.SigningKeyRepository, keys *auth.KeySet, logger *slog.Logger, interval time.Duration, algorithm string, encryptionKey []byte, rotateEvery, verifyFor time.Duration) Job {
+	return Job{
+		Name:     "signing_keys",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			now := time.Now()
+
+			latest, err := store.Latest(ctx)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+
+			// Первый ключ активируется сразу: без него нельзя выпускать токены.
+			first := errors.Is(err, repository.ErrNotFound)
+			due := first || latest.Algorithm != algorithm || !latest.ActivatesAt.Add(rotateEvery).After(now.Add(signingKeyPublishLead))
+			if due {
+				privateKey, err := auth.GenerateSigningKey(algorithm)
+				if err != nil {
+					return err
+				}
+
+				activatesAt := now.Add(signingKeyPublishLead)
+				if first {
+					activatesAt = now
+				}
+
+				kid := uuid.NewString()
+				encrypted, err := auth.SealSigningKey(encryptionKey, kid, privateKey)
+				if err != nil {
+					return err
+				}
+
+				key := models.SigningKey{
+					ID:                  kid,
+					Algorithm:           algorithm,
+					EncryptedPrivateKey: encrypted,
+					ActivatesAt:         activatesAt,
+				}
+				rotated, err := store.Rotate(ctx, key, latest.ID, activatesAt.Add(verifyFor))
+				if err != nil {
+					return err
+				}
+				if rotated {
+					logger.Info("signing key rotated",
+						slog.String("kid", key.ID),
+						slog.String("algorithm", algorithm),
+						slog.Time("activates_at", activatesAt),
+					)
+				}
+			}
+
+			stored, err := store.ListUsable(ctx, now)
+			if err != nil {
+				return err
+			}
+
+			loaded := make([]auth.SigningKey, 0, len(stored))
+			for _, key := range stored {
+				data, err := auth.OpenSigningKey(encryptionKey, key.ID, key.EncryptedPrivateKey)
+				if err != nil {
+					return fmt.Errorf("signing key %s: %w", key.ID, err)
+				}
+				privateKey, err := auth.ParseSigningKey(key.Algorithm, data)
+				if err != nil {
+					return fmt.Errorf("signing key %s: %w", key.ID, err)
+				}
+				loaded = append(loaded, auth.SigningKey{
+					ID:          key.ID,
+					Algorithm:   key.Algorithm,
+					PrivateKey:  privateKey,
+					ActivatesAt: key.ActivatesAt,
+					ExpiresAt:   key.ExpiresAt,
+				})
+			}
+			keys.Replace(loaded)
+
+			return nil
+		},
+	}
+}
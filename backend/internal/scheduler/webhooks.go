@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"example.com/ai-budget-planner/backend/internal/repository"
+	"example.com/ai-budget-planner/backend/internal/webhooks"
+)
+
+// WebhookDeliveryJob отправляет вебхуки из очереди, время попытки которых наступило.
+func WebhookDeliveryJob(dispatcher *webhooks.Dispatcher, logger *slog.Logger, interval time.Duration) Job {
+	return Job{
+		Name:     "webhook_delivery",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			delivered, err := dispatcher.DeliverDue(ctx)
+			if delivered > 0 {
+				logger.Info("webhooks delivered", slog.Int("delivered", delivered))
+			}
+			return err
+		},
+	}
+}
+
+// WebhookPruneJob удаляет из журнала завершенные доставки вебхуков старше retention.
+func WebhookPruneJob(deliveries *repository.WebhookRepository, logger *slog.Logger, interval, retention time.Duration) Job {
+	return Job{
+		Name:     "webhook_prune",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			deleted, err := deliveries.DeleteFinishedBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return err
+			}
+
+			if deleted > 0 {
+				logger.Info("webhook deliveries pruned", slog.Int64("deleted", deleted))
+			}
+
+			return nil
+		},
+	}
+}
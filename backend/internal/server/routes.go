@@ -3,7 +3,9 @@ package server
 import (
 	"github.com/labstack/echo/v4"
 
+	"example.com/ai-budget-planner/backend/internal/auth"
 	"example.com/ai-budget-planner/backend/internal/handlers"
+	"example.com/ai-budget-planner/backend/internal/models"
 )
 
 func registerRoutes(
@@ -11,68 +13,141 @@ func registerRoutes(
 	authHandler *handlers.AuthHandler,
 	planHandler *handlers.PlanHandler,
 	itemHandler *handlers.ItemHandler,
+	categoryHandler *handlers.CategoryHandler,
+	transactionHandler *handlers.TransactionHandler,
 	noteHandler *handlers.NoteHandler,
+	memberHandler *handlers.MemberHandler,
 	statsHandler *handlers.StatsHandler,
 	aiHandler *handlers.AIHandler,
 	notificationHandler *handlers.NotificationHandler,
+	webhookHandler *handlers.WebhookHandler,
+	personalTokenHandler *handlers.PersonalTokenHandler,
 	adminHandler *handlers.AdminHandler,
+	accountHandler *handlers.AccountHandler,
+	jwksHandler *handlers.JWKSHandler,
+	oidcHandler *handlers.OIDCHandler,
 	authMiddleware echo.MiddlewareFunc,
 	adminMiddleware echo.MiddlewareFunc,
 	authRateLimiter echo.MiddlewareFunc,
 	aiRateLimiter echo.MiddlewareFunc,
 ) {
 	e.GET("/health", handlers.Health)
+	e.GET("/.well-known/jwks.json", jwksHandler.Get)
+
+	// Персональные токены ограничены своими scopes; управлять учетной записью
+	// можно только из сессии, полученной входом.
+	methodScope := auth.MethodScope()
+	sessionOnly := auth.SessionOnly()
 
 	api := e.Group("/api/v1")
 	authGroup := api.Group("/auth", authRateLimiter)
 
 	authGroup.POST("/register", authHandler.Register)
 	authGroup.POST("/login", authHandler.Login)
+	authGroup.POST("/login/mfa", authHandler.LoginMFA)
 	authGroup.POST("/refresh", authHandler.Refresh)
 	authGroup.POST("/logout", authHandler.Logout)
-	authGroup.GET("/me", authHandler.Me, authMiddleware)
+	if oidcHandler != nil {
+		authGroup.GET("/oidc/authorize", oidcHandler.Authorize)
+		authGroup.POST("/oidc/callback", oidcHandler.Callback)
+	}
+	authGroup.POST("/password/forgot", authHandler.ForgotPassword)
+	authGroup.POST("/password/reset", authHandler.ResetPassword)
+	authGroup.POST("/verify-email", authHandler.VerifyEmail)
+	authGroup.POST("/verify-email/resend", authHandler.ResendVerification, authMiddleware, sessionOnly)
+	authGroup.POST("/email/confirm", authHandler.ConfirmEmailChange)
+	authGroup.GET("/me", authHandler.Me, authMiddleware, methodScope)
+	authGroup.PATCH("/me", authHandler.UpdateProfile, authMiddleware, sessionOnly)
+	authGroup.GET("/me/export", accountHandler.Export, authMiddleware, sessionOnly)
+	authGroup.DELETE("/me", accountHandler.Delete, authMiddleware, sessionOnly)
+	authGroup.GET("/sessions", authHandler.ListSessions, authMiddleware, sessionOnly)
+	authGroup.DELETE("/sessions", authHandler.RevokeAllSessions, authMiddleware, sessionOnly)
+	authGroup.DELETE("/sessions/:id", authHandler.RevokeSession, authMiddleware, sessionOnly)
+	authGroup.GET("/mfa", authHandler.MFAStatus, authMiddleware, sessionOnly)
+	authGroup.POST("/mfa/totp/setup", authHandler.SetupTOTP, authMiddleware, sessionOnly)
+	authGroup.POST("/mfa/totp/enable", authHandler.EnableTOTP, authMiddleware, sessionOnly)
+	authGroup.POST("/mfa/totp/disable", authHandler.DisableTOTP, authMiddleware, sessionOnly)
+	authGroup.POST("/mfa/recovery-codes", authHandler.RegenerateRecoveryCodes, authMiddleware, sessionOnly)
+	authGroup.GET("/tokens", personalTokenHandler.List, authMiddleware, sessionOnly)
+	authGroup.POST("/tokens", personalTokenHandler.Create, authMiddleware, sessionOnly)
+	authGroup.DELETE("/tokens/:id", personalTokenHandler.Revoke, authMiddleware, sessionOnly)
 
-	plans := api.Group("/plans", authMiddleware)
+	plans := api.Group("/plans", authMiddleware, methodScope)
 	plans.GET("", planHandler.List)
 	plans.GET("/archive", planHandler.Archive)
 	plans.POST("", planHandler.Create)
 	plans.GET("/:planId/notes", noteHandler.List)
 	plans.POST("/:planId/notes", noteHandler.Create)
+	plans.POST("/:planId/categories", categoryHandler.Create)
 	plans.POST("/:planId/categories/:categoryId/items", itemHandler.Create)
 	plans.PATCH("/:id/reorder", planHandler.ReorderCategories)
 	plans.POST("/:id/duplicate", planHandler.Duplicate)
+	plans.PUT("/:id/recurrence", planHandler.UpdateRecurrence)
+	plans.POST("/:id/rollover", planHandler.Rollover)
+	plans.GET("/:id/alert-rules", planHandler.ListAlertRules)
+	plans.PUT("/:id/alert-rules", planHandler.ReplaceAlertRules)
+	plans.GET("/:id/members", memberHandler.List)
+	plans.PUT("/:id/members/:userId", memberHandler.UpdateRole)
+	plans.DELETE("/:id/members/:userId", memberHandler.Remove)
+	plans.POST("/:id/invites", memberHandler.Invite)
 	plans.GET("/:id", planHandler.Get)
 	plans.GET("/:id/export/json", planHandler.ExportJSON)
 	plans.GET("/:id/export/csv", planHandler.ExportCSV)
 	plans.PUT("/:id", planHandler.Update)
 	plans.DELETE("/:id", planHandler.Delete)
 
-	items := api.Group("/items", authMiddleware)
+	invites := api.Group("/invites", authMiddleware, methodScope)
+	invites.POST("/accept", memberHandler.AcceptInvite)
+
+	items := api.Group("/items", authMiddleware, methodScope)
 	items.PUT("/:itemId", itemHandler.Update)
 	items.DELETE("/:itemId", itemHandler.Delete)
 	items.PATCH("/:itemId/toggle", itemHandler.Toggle)
 	items.PATCH("/:itemId/reorder", itemHandler.Reorder)
 	items.PATCH("/:itemId/color", itemHandler.UpdateColor)
+	items.GET("/:itemId/transactions", transactionHandler.List)
+	items.POST("/:itemId/transactions", transactionHandler.Create)
+
+	transactions := api.Group("/transactions", authMiddleware, methodScope)
+	transactions.PUT("/:transactionId", transactionHandler.Update)
+	transactions.DELETE("/:transactionId", transactionHandler.Delete)
 
-	notes := api.Group("/notes", authMiddleware)
+	categories := api.Group("/categories", authMiddleware, methodScope)
+	categories.PUT("/:categoryId", categoryHandler.Update)
+	categories.DELETE("/:categoryId", categoryHandler.Delete)
+
+	notes := api.Group("/notes", authMiddleware, methodScope)
 	notes.PUT("/:noteId", noteHandler.Update)
 	notes.DELETE("/:noteId", noteHandler.Delete)
 	notes.PATCH("/:noteId/reorder", noteHandler.Reorder)
 
-	stats := api.Group("/stats", authMiddleware)
+	stats := api.Group("/stats", authMiddleware, methodScope)
 	stats.GET("/overview", statsHandler.Overview)
 	stats.GET("/spending-by-category", statsHandler.SpendingByCategory)
 	stats.GET("/monthly-comparison", statsHandler.MonthlyComparison)
 
-	notifications := api.Group("/notifications", authMiddleware)
+	notifications := api.Group("/notifications", authMiddleware, methodScope)
 	notifications.GET("/stream", notificationHandler.Stream)
 
-	admin := api.Group("/admin", authMiddleware, adminMiddleware)
-	admin.GET("/users", adminHandler.ListUsers)
-	admin.GET("/ai-requests", adminHandler.ListAIRequests)
-	admin.GET("/usage", adminHandler.Usage)
+	webhooks := api.Group("/webhooks", authMiddleware)
+	webhooks.GET("", webhookHandler.List, methodScope)
+	webhooks.POST("", webhookHandler.Create, sessionOnly)
+	webhooks.DELETE("/:id", webhookHandler.Delete, sessionOnly)
+	webhooks.GET("/:id/deliveries", webhookHandler.Deliveries, methodScope)
+
+	admin := api.Group("/admin", authMiddleware, sessionOnly, adminMiddleware)
+	admin.GET("/users", adminHandler.ListUsers, handlers.RequirePermission(models.PermissionUsersRead))
+	admin.DELETE("/users/:id/lock", adminHandler.UnlockUser, handlers.RequirePermission(models.PermissionUsersUnlock))
+	admin.GET("/ai-requests", adminHandler.ListAIRequests, handlers.RequirePermission(models.PermissionAIRequestsRead))
+	admin.GET("/usage", adminHandler.Usage, handlers.RequirePermission(models.PermissionUsageRead))
+	admin.GET("/exchange-rates", adminHandler.ListExchangeRates, handlers.RequirePermission(models.PermissionExchangeRatesRead))
+	admin.POST("/exchange-rates", adminHandler.ImportExchangeRates, handlers.RequirePermission(models.PermissionExchangeRatesWrite))
+	admin.GET("/roles", adminHandler.ListRoles, handlers.RequirePermission(models.PermissionRolesManage))
+	admin.GET("/users/:id/roles", adminHandler.ListUserRoles, handlers.RequirePermission(models.PermissionRolesManage))
+	admin.PUT("/users/:id/roles/:role", adminHandler.GrantRole, handlers.RequirePermission(models.PermissionRolesManage))
+	admin.DELETE("/users/:id/roles/:role", adminHandler.RevokeRole, handlers.RequirePermission(models.PermissionRolesManage))
 
-	aiGroup := api.Group("/ai", authMiddleware, aiRateLimiter)
+	aiGroup := api.Group("/ai", authMiddleware, auth.RequireScope(auth.ScopeAI), aiRateLimiter)
 	aiGroup.POST("/generate-plan", aiHandler.GeneratePlan)
 	aiGroup.POST("/analyze-spending", aiHandler.AnalyzeSpending)
 	aiGroup.GET("/advices/:planId", aiHandler.GetAdvices)
@@ -3,8 +3,8 @@ package server
 import (
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -16,12 +16,15 @@ import (
 	"example.com/ai-budget-planner/backend/internal/auth"
 	"example.com/ai-budget-planner/backend/internal/config"
 	"example.com/ai-budget-planner/backend/internal/handlers"
+	"example.com/ai-budget-planner/backend/internal/mailer"
 	"example.com/ai-budget-planner/backend/internal/notifications"
+	"example.com/ai-budget-planner/backend/internal/oidc"
 	"example.com/ai-budget-planner/backend/internal/repository"
 )
 
-// New собирает HTTP-сервер Echo с роутами и зависимостями.
-func New(cfg config.Config, logger *slog.Logger, db *pgxpool.Pool) *echo.Echo {
+// New собирает HTTP-сервер Echo с роутами и зависимостями. signingKeys задает
+// асимметричные ключи подписи JWT; при nil токены подписываются JWT_SECRET.
+func New(cfg config.Config, logger *slog.Logger, db *pgxpool.Pool, notificationHub *notifications.Hub, signingKeys *auth.KeySet) *echo.Echo {
 	if logger == nil {
 		logger = slog.Default()
 	}
@@ -30,50 +33,83 @@ func New(cfg config.Config, logger *slog.Logger, db *pgxpool.Pool) *echo.Echo {
 	e.HideBanner = true
 	e.HidePort = true
 	e.Validator = NewValidator()
+	e.IPExtractor = ipExtractor(cfg.Server.TrustedProxies)
 
 	e.Use(middleware.Recover())
 	e.Use(middleware.RequestID())
 	e.Use(requestLogger(logger))
 
-	tokenManager := auth.NewTokenManager(cfg.Auth.JWTSecret, cfg.Auth.JWTIssuer, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+	var tokenOptions []auth.TokenManagerOption
+	if signingKeys != nil {
+		tokenOptions = append(tokenOptions, auth.WithKeySet(signingKeys))
+	}
+	tokenManager := auth.NewTokenManager(cfg.Auth.JWTSecret, cfg.Auth.JWTIssuer, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL, tokenOptions...)
 	userRepo := repository.NewUserRepository(db)
 	tokenRepo := repository.NewRefreshTokenRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
 	planRepo := repository.NewPlanRepository(db)
 	itemRepo := repository.NewItemRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
 	noteRepo := repository.NewNoteRepository(db)
+	memberRepo := repository.NewMemberRepository(db)
 	statsRepo := repository.NewStatsRepository(db)
 	aiRepo := repository.NewAIRepository(db)
 	adminRepo := repository.NewAdminRepository(db)
-	notificationHub := notifications.NewHub()
-	var aiClient ai.Client
-	switch strings.ToLower(cfg.AI.Provider) {
-	case "gemini":
-		aiClient = ai.NewGeminiClient(cfg.AI.APIKey, cfg.AI.BaseURL, cfg.AI.Model, cfg.AI.Timeout, cfg.AI.MaxOutputTokens)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	personalTokenRepo := repository.NewPersonalTokenRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	aiService := ai.NewService(newAIClient(cfg.AI, logger), cfg.AI.RepairAttempts, cfg.AI.RequestTimeout)
+	var mail mailer.Mailer
+	switch cfg.Mail.Driver {
+	case config.MailDriverSMTP:
+		mail = mailer.NewSMTPMailer(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From)
 	default:
-		aiClient = ai.NewGroqClient(cfg.AI.APIKey, cfg.AI.BaseURL, cfg.AI.Model, cfg.AI.Timeout, cfg.AI.MaxOutputTokens)
+		mail = mailer.NewLogMailer(logger)
 	}
-	aiService := ai.NewService(aiClient)
-	authHandler := handlers.NewAuthHandler(userRepo, tokenRepo, tokenManager)
-	planHandler := handlers.NewPlanHandler(planRepo, notificationHub)
+	authHandler := handlers.NewAuthHandler(userRepo, tokenRepo, userTokenRepo, tokenManager, mail, cfg.AppURL,
+		auth.LoginLockout{Threshold: cfg.Auth.LockoutThreshold, Duration: cfg.Auth.LockoutDuration})
+	planHandler := handlers.NewPlanHandler(planRepo, userRepo, notificationHub)
 	itemHandler := handlers.NewItemHandler(itemRepo, planRepo, notificationHub)
+	categoryHandler := handlers.NewCategoryHandler(categoryRepo, planRepo, notificationHub)
+	transactionHandler := handlers.NewTransactionHandler(transactionRepo, itemRepo, planRepo, notificationHub)
 	noteHandler := handlers.NewNoteHandler(noteRepo)
+	memberHandler := handlers.NewMemberHandler(memberRepo)
 	statsHandler := handlers.NewStatsHandler(statsRepo)
-	aiHandler := handlers.NewAIHandler(aiService, planRepo, noteRepo, aiRepo, notificationHub, cfg.AI.Provider, cfg.AI.Model)
+	aiHandler := handlers.NewAIHandler(aiService, planRepo, noteRepo, aiRepo, userRepo, notificationHub, cfg.AI.Provider, cfg.AI.Model)
 	notificationHandler := handlers.NewNotificationHandler(notificationHub)
-	adminHandler := handlers.NewAdminHandler(adminRepo)
+	webhookHandler := handlers.NewWebhookHandler(webhookRepo)
+	personalTokenHandler := handlers.NewPersonalTokenHandler(personalTokenRepo)
+	adminHandler := handlers.NewAdminHandler(adminRepo, exchangeRateRepo, roleRepo)
+	accountHandler := handlers.NewAccountHandler(authHandler, repository.NewAccountRepository(db), planRepo, cfg.Auth.DeletionGrace)
+	jwksHandler := handlers.NewJWKSHandler(signingKeys)
+	var oidcHandler *handlers.OIDCHandler
+	if cfg.OIDC.IssuerURL != "" {
+		provider := oidc.NewProvider(cfg.OIDC.IssuerURL, cfg.OIDC.ClientID, cfg.OIDC.ClientSecret, cfg.OIDC.RedirectURL, cfg.OIDC.Timeout)
+		oidcHandler = handlers.NewOIDCHandler(authHandler, provider, repository.NewOIDCRepository(db))
+	}
 
 	registerRoutes(
 		e,
 		authHandler,
 		planHandler,
 		itemHandler,
+		categoryHandler,
+		transactionHandler,
 		noteHandler,
+		memberHandler,
 		statsHandler,
 		aiHandler,
 		notificationHandler,
+		webhookHandler,
+		personalTokenHandler,
 		adminHandler,
-		auth.JWTMiddleware(tokenManager),
-		handlers.AdminMiddleware(userRepo, cfg.Admin.Emails),
+		accountHandler,
+		jwksHandler,
+		oidcHandler,
+		auth.JWTMiddleware(tokenManager, personalTokenRepo),
+		handlers.AdminAccess(roleRepo),
 		authRateLimiter(cfg.Auth),
 		aiRateLimiter(cfg.AI),
 	)
@@ -81,6 +117,73 @@ func New(cfg config.Config, logger *slog.Logger, db *pgxpool.Pool) *echo.Echo {
 	return e
 }
 
+// newAIClient собирает цепочку провайдеров AI: основной из AI_PROVIDER и
+// резервные из AI_FALLBACK_PROVIDERS. Gemini и Groq без ключа пропускаются;
+// если не осталось ни одного провайдера, возвращает nil и планы составляются без AI.
+func newAIClient(cfg config.AIConfig, logger *slog.Logger) ai.Client {
+	chain := cfg.Chain()
+	providers := make([]ai.FailoverProvider, 0, len(chain))
+	for _, provider := range chain {
+		if provider.APIKey == "" && (provider.Name == config.AIProviderGemini || provider.Name == config.AIProviderGroq) {
+			logger.Warn("ai provider skipped: api key is missing", slog.String("provider", provider.Name))
+			continue
+		}
+
+		var client ai.Client
+		switch provider.Name {
+		case config.AIProviderGemini:
+			client = ai.NewGeminiClient(provider.APIKey, provider.BaseURL, provider.Model, provider.Timeout, cfg.MaxOutputTokens)
+		case config.AIProviderOllama:
+			client = ai.NewOllamaClient(provider.BaseURL, provider.Model, provider.Timeout, cfg.MaxOutputTokens)
+		case config.AIProviderGroq:
+			client = ai.NewGroqClient(provider.APIKey, provider.BaseURL, provider.Model, provider.Timeout, cfg.MaxOutputTokens, openAIOptions(provider)...)
+		default:
+			client = ai.NewOpenAIClient(provider.APIKey, provider.BaseURL, provider.Model, provider.Timeout, cfg.MaxOutputTokens, openAIOptions(provider)...)
+		}
+		providers = append(providers, ai.FailoverProvider{Name: provider.Name, Client: client, Timeout: provider.Timeout})
+	}
+	if len(providers) == 0 {
+		logger.Warn("ai providers are not configured, plans are built offline")
+		return nil
+	}
+
+	return ai.NewFailoverClient(providers, cfg.BreakerThreshold, cfg.BreakerCooldown, logger)
+}
+
+// openAIOptions переносит дополнительные настройки провайдера в OpenAI-совместимый клиент.
+func openAIOptions(provider config.AIProviderConfig) []ai.OpenAIOption {
+	opts := make([]ai.OpenAIOption, 0, 3)
+	if provider.Organization != "" {
+		opts = append(opts, ai.WithOrganization(provider.Organization))
+	}
+	if len(provider.Headers) > 0 {
+		opts = append(opts, ai.WithHeaders(provider.Headers))
+	}
+	if provider.JSONResponse {
+		opts = append(opts, ai.WithJSONResponse())
+	}
+	return opts
+}
+
+// ipExtractor определяет адрес клиента для c.RealIP(), лимитов запросов и
+// сессий. Без доверенных прокси берется адрес соединения, а X-Forwarded-For
+// и X-Real-IP игнорируются: их может подделать любой клиент.
+func ipExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, network := range trustedProxies {
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
 // NewHTTPServer создает net/http сервер с заданными таймаутами.
 func NewHTTPServer(cfg config.ServerConfig, handler http.Handler) *http.Server {
 	return &http.Server{
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestIPExtractorIgnoresForgedHeaders проверяет, что без доверенных прокси
+// адрес клиента берется из соединения, а не из заголовков.
+func TestIPExtractorIgnoresForgedHeaders(t *testing.T) {
+	request := httptest.NewRequest("GET", "/", nil)
+	request.RemoteAddr = "203.0.113.7:51234"
+	request.Header.Set("X-Forwarded-For", "198.51.100.1")
+	request.Header.Set("X-Real-IP", "198.51.100.2")
+
+	if got := ipExtractor(nil)(request); got != "203.0.113.7" {
+		t.Fatalf("expected connection address, got %q", got)
+	}
+}
+
+// TestIPExtractorTrustedProxy проверяет, что X-Forwarded-For учитывается
+// только для запросов от доверенного прокси.
+func TestIPExtractorTrustedProxy(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	extract := ipExtractor([]*net.IPNet{proxies})
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.RemoteAddr = "10.1.2.3:51234"
+	request.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if got := extract(request); got != "198.51.100.1" {
+		t.Fatalf("expected forwarded address, got %q", got)
+	}
+
+	request.RemoteAddr = "192.168.1.5:51234"
+	if got := extract(request); got != "192.168.1.5" {
+		t.Fatalf("expected untrusted proxy address, got %q", got)
+	}
+}
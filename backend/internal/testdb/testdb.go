@@ -0,0 +1,146 @@
+// Package testdb готовит Postgres для интеграционных тестов: каждая проверка
+// получает отдельную схему с примененными миграциями, которая удаляется после
+// теста. Адрес базы берется из TEST_DATABASE_URL; без него тесты пропускаются.
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	envDatabaseURL = "TEST_DATABASE_URL"
+	gooseUp        = "-- +goose Up"
+	gooseDown      = "-- +goose Down"
+)
+
+// New создает схему, применяет к ней Up-части миграций из backend/migrations и
+// возвращает пул, у соединений которого эта схема первая в search_path.
+func New(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	databaseURL := os.Getenv(envDatabaseURL)
+	if databaseURL == "" {
+		t.Skip(envDatabaseURL + " is not set")
+	}
+
+	ctx := context.Background()
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+
+	admin, err := pgx.Connect(ctx, databaseURL)
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	defer admin.Close(ctx)
+
+	// Расширения общие для базы: pgcrypto ставится в public заранее, иначе
+	// миграция создала бы его во временной схеме и удалила вместе с ней.
+	// Блокировка защищает от гонки пакетов тестов, запущенных параллельно.
+	_, err = admin.Exec(ctx, `SELECT pg_advisory_lock(hashtext('testdb'));
+		CREATE EXTENSION IF NOT EXISTS pgcrypto WITH SCHEMA public;
+		SELECT pg_advisory_unlock(hashtext('testdb'))`)
+	if err != nil {
+		t.Fatalf("create extensions: %v", err)
+	}
+
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		cleanup, err := pgx.Connect(context.Background(), databaseURL)
+		if err != nil {
+			t.Logf("drop schema %s: %v", schema, err)
+			return
+		}
+		defer cleanup.Close(context.Background())
+
+		if _, err := cleanup.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Logf("drop schema %s: %v", schema, err)
+		}
+	})
+
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatalf("parse %s: %v", envDatabaseURL, err)
+	}
+	// public остается в пути поиска ради функций расширений.
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("open test pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := migrate(ctx, pool); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+
+	return pool
+}
+
+// migrate применяет Up-части миграций по порядку имен файлов, как goose.
+func migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	files, err := filepath.Glob(filepath.Join(migrationsDir(), "*.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no migrations in %s", migrationsDir())
+	}
+	sort.Strings(files)
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		up, err := upSection(string(content))
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+
+		// Простой протокол выполняет несколько команд за один запрос.
+		if _, err := conn.Conn().PgConn().Exec(ctx, up).ReadAll(); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return nil
+}
+
+func upSection(content string) (string, error) {
+	start := strings.Index(content, gooseUp)
+	if start == -1 {
+		return "", fmt.Errorf("missing %q", gooseUp)
+	}
+
+	up := content[start+len(gooseUp):]
+	if end := strings.Index(up, gooseDown); end != -1 {
+		up = up[:end]
+	}
+
+	return up, nil
+}
+
+func migrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "migrations")
+}
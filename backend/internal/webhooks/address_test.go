@@ -0,0 +1,57 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+// TestIsForbiddenIP проверяет, какие адреса считаются внутренними.
+func TestIsForbiddenIP(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"172.16.0.1":      true,
+		"192.168.1.1":     true,
+		"169.254.169.254": true,
+		"0.0.0.0":         true,
+		"100.64.0.1":      true,
+		"::1":             true,
+		"::":              true,
+		"fe80::1":         true,
+		"fd00::1":         true,
+		"::ffff:10.0.0.1": true,
+		"93.184.216.34":   false,
+		"2606:4700::1111": false,
+	}
+
+	for value, want := range cases {
+		if got := IsForbiddenIP(net.ParseIP(value)); got != want {
+			t.Fatalf("%s: expected %v, got %v", value, want, got)
+		}
+	}
+}
+
+// TestValidateURL проверяет отклонение внутренних адресов при регистрации endpoint.
+func TestValidateURL(t *testing.T) {
+	forbidden := []string{
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"https://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+	}
+	for _, value := range forbidden {
+		if err := ValidateURL(context.Background(), value); !errors.Is(err, ErrForbiddenAddress) {
+			t.Fatalf("%s: expected forbidden address, got %v", value, err)
+		}
+	}
+
+	if err := ValidateURL(context.Background(), "ftp://93.184.216.34/hook"); err == nil || errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected scheme error, got %v", err)
+	}
+	if err := ValidateURL(context.Background(), "https://93.184.216.34/hook"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"example.com/ai-budget-planner/backend/internal/notifications"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	batchSize        = 50
+	claimLease       = 5 * time.Minute
+	retryBaseDelay   = 30 * time.Second
+	retryMaxDelay    = 6 * time.Hour
+	maxErrorBodySize = 512
+)
+
+// Store хранит очередь доставок вебхуков.
+type Store interface {
+	EnqueueDeliveries(ctx context.Context, userID uuid.UUID, eventType string, payload []byte) (int64, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]repository.DueWebhookDelivery, error)
+	MarkDelivered(ctx context.Context, deliveryID uuid.UUID, statusCode int) error
+	MarkFailed(ctx context.Context, deliveryID uuid.UUID, statusCode *int, message string, nextAttemptAt *time.Time) error
+}
+
+// Dispatcher ставит события хаба уведомлений в очередь и доставляет их на
+// зарегистрированные endpoint с подписью HMAC-SHA256 и повторными попытками.
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	logger      *slog.Logger
+	maxAttempts int
+	now         func() time.Time
+}
+
+// NewDispatcher создает диспетчер вебхуков. timeout ограничивает один запрос,
+// maxAttempts — общее число попыток доставки. Запросы во внутреннюю сеть
+// отклоняются при соединении, см. NewHTTPClient.
+func NewDispatcher(store Store, logger *slog.Logger, timeout time.Duration, maxAttempts int) *Dispatcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Dispatcher{
+		store:       store,
+		client:      NewHTTPClient(timeout),
+		logger:      logger,
+		maxAttempts: maxAttempts,
+		now:         time.Now,
+	}
+}
+
+// Enqueue ставит событие в очередь доставки на endpoint пользователя.
+// Реализует notifications.Sink.
+func (d *Dispatcher) Enqueue(ctx context.Context, userID uuid.UUID, event notifications.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.store.EnqueueDeliveries(ctx, userID, event.Type, payload)
+	return err
+}
+
+// DeliverDue отправляет доставки, время попытки которых наступило, и возвращает
+// число успешных.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	due, err := d.store.ClaimDueDeliveries(ctx, d.now(), claimLease, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, item := range due {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+
+		ok, err := d.deliver(ctx, item)
+		if err != nil {
+			return delivered, err
+		}
+		if ok {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// deliver выполняет одну попытку и записывает ее результат.
+func (d *Dispatcher) deliver(ctx context.Context, item repository.DueWebhookDelivery) (bool, error) {
+	delivery := item.Delivery
+
+	statusCode, err := d.send(ctx, item)
+	if err == nil {
+		return true, d.store.MarkDelivered(ctx, delivery.ID, statusCode)
+	}
+
+	var status *int
+	if statusCode != 0 {
+		status = &statusCode
+	}
+
+	attempt := delivery.Attempts + 1
+	var nextAttemptAt *time.Time
+	if attempt < d.maxAttempts {
+		next := d.now().Add(Backoff(attempt))
+		nextAttemptAt = &next
+	}
+
+	d.logger.Warn("webhook delivery failed",
+		slog.String("delivery_id", delivery.ID.String()),
+		slog.String("endpoint_id", delivery.EndpointID.String()),
+		slog.Int("attempt", attempt),
+		slog.Bool("final", nextAttemptAt == nil),
+		slog.String("error", err.Error()),
+	)
+
+	return false, d.store.MarkFailed(ctx, delivery.ID, status, err.Error(), nextAttemptAt)
+}
+
+// send отправляет подписанный запрос и возвращает HTTP-статус ответа (0, если ответа нет).
+func (d *Dispatcher) send(ctx context.Context, item repository.DueWebhookDelivery) (int, error) {
+	body := []byte(item.Delivery.Payload)
+	timestamp := d.now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, item.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "budget-planner-webhooks")
+	req.Header.Set(EventHeader, item.Delivery.EventType)
+	req.Header.Set(DeliveryHeader, item.Delivery.ID.String())
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(item.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// Backoff возвращает задержку перед попыткой attempt+1: 30s, 1m, 2m, ... не более 6h.
+func Backoff(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// Sign возвращает подпись тела запроса: "sha256=" + hex(HMAC-SHA256(secret, "<unix timestamp>.<body>")).
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись запроса в константное время.
+func Verify(secret string, timestamp time.Time, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,297 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"example.com/ai-budget-planner/backend/internal/models"
+	"example.com/ai-budget-planner/backend/internal/notifications"
+	"example.com/ai-budget-planner/backend/internal/repository"
+)
+
+type memoryEndpoint struct {
+	userID uuid.UUID
+	url    string
+	secret string
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	endpoints  []memoryEndpoint
+	deliveries map[uuid.UUID]*models.WebhookDelivery
+	urls       map[uuid.UUID]memoryEndpoint
+}
+
+func newMemoryStore(endpoints ...memoryEndpoint) *memoryStore {
+	return &memoryStore{
+		endpoints:  endpoints,
+		deliveries: make(map[uuid.UUID]*models.WebhookDelivery),
+		urls:       make(map[uuid.UUID]memoryEndpoint),
+	}
+}
+
+func (s *memoryStore) EnqueueDeliveries(_ context.Context, userID uuid.UUID, eventType string, payload []byte) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var created int64
+	for _, endpoint := range s.endpoints {
+		if endpoint.userID != userID {
+			continue
+		}
+		delivery := &models.WebhookDelivery{
+			ID:        uuid.New(),
+			EventType: eventType,
+			Payload:   payload,
+			Status:    models.WebhookDeliveryPending,
+		}
+		s.deliveries[delivery.ID] = delivery
+		s.urls[delivery.ID] = endpoint
+		created++
+	}
+	return created, nil
+}
+
+func (s *memoryStore) ClaimDueDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]repository.DueWebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make([]repository.DueWebhookDelivery, 0)
+	for id, delivery := range s.deliveries {
+		if len(due) == limit {
+			break
+		}
+		if delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.NextAttemptAt = now.Add(lease)
+		endpoint := s.urls[id]
+		due = append(due, repository.DueWebhookDelivery{Delivery: *delivery, URL: endpoint.url, Secret: endpoint.secret})
+	}
+	return due, nil
+}
+
+func (s *memoryStore) MarkDelivered(_ context.Context, deliveryID uuid.UUID, statusCode int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery := s.deliveries[deliveryID]
+	delivery.Status = models.WebhookDeliveryDelivered
+	delivery.Attempts++
+	delivery.LastStatusCode = &statusCode
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(_ context.Context, deliveryID uuid.UUID, statusCode *int, message string, nextAttemptAt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery := s.deliveries[deliveryID]
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = &message
+	if nextAttemptAt == nil {
+		delivery.Status = models.WebhookDeliveryFailed
+	} else {
+		delivery.NextAttemptAt = *nextAttemptAt
+	}
+	return nil
+}
+
+func (s *memoryStore) only(t *testing.T) models.WebhookDelivery {
+	t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(s.deliveries))
+	}
+	for _, delivery := range s.deliveries {
+		return *delivery
+	}
+	return models.WebhookDelivery{}
+}
+
+func newTestDispatcher(store Store, clock *time.Time, maxAttempts int) *Dispatcher {
+	dispatcher := NewDispatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second, maxAttempts)
+	dispatcher.now = func() time.Time { return *clock }
+	// Тестовые серверы слушают loopback, поэтому проверка адресов отключена.
+	dispatcher.client = &http.Client{Timeout: time.Second}
+	return dispatcher
+}
+
+// TestDispatcherDeliversSignedPayload проверяет подпись и содержимое доставленного события.
+func TestDispatcherDeliversSignedPayload(t *testing.T) {
+	const secret = "test-secret"
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	userID := uuid.New()
+	store := newMemoryStore(memoryEndpoint{userID: userID, url: server.URL, secret: secret})
+	clock := time.Date(2026, 1, 12, 14, 0, 0, 0, time.UTC)
+	dispatcher := newTestDispatcher(store, &clock, 3)
+
+	event := notifications.Event{ID: 7, Type: "budget_updated", Timestamp: clock, Data: map[string]int64{"spent_cents": 100}}
+	if err := dispatcher.Enqueue(context.Background(), userID, event); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	delivered, err := dispatcher.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("expected 1 delivered, got %d", delivered)
+	}
+
+	req := <-received
+	body := <-bodies
+	unix, err := strconv.ParseInt(req.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("parse timestamp: %v", err)
+	}
+	if !Verify(secret, time.Unix(unix, 0), body, req.Header.Get(SignatureHeader)) {
+		t.Fatal("expected valid signature")
+	}
+	if Verify("other-secret", time.Unix(unix, 0), body, req.Header.Get(SignatureHeader)) {
+		t.Fatal("expected signature to depend on secret")
+	}
+	if req.Header.Get(EventHeader) != "budget_updated" {
+		t.Fatalf("unexpected event header %q", req.Header.Get(EventHeader))
+	}
+
+	var got notifications.Event
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if got.ID != 7 || got.Type != "budget_updated" {
+		t.Fatalf("unexpected payload %+v", got)
+	}
+
+	if delivery := store.only(t); delivery.Status != models.WebhookDeliveryDelivered || delivery.Attempts != 1 {
+		t.Fatalf("unexpected delivery state %+v", delivery)
+	}
+}
+
+// TestDispatcherRetriesWithBackoff проверяет повтор после ошибки и отказ после исчерпания попыток.
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	userID := uuid.New()
+	store := newMemoryStore(memoryEndpoint{userID: userID, url: server.URL, secret: "s"})
+	clock := time.Date(2026, 1, 12, 14, 0, 0, 0, time.UTC)
+	dispatcher := newTestDispatcher(store, &clock, 2)
+
+	if err := dispatcher.Enqueue(context.Background(), userID, notifications.Event{Type: "ai_advices"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	if _, err := dispatcher.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	delivery := store.only(t)
+	if delivery.Status != models.WebhookDeliveryPending || delivery.Attempts != 1 {
+		t.Fatalf("expected pending retry, got %+v", delivery)
+	}
+	if want := clock.Add(Backoff(1)); !delivery.NextAttemptAt.Equal(want) {
+		t.Fatalf("expected next attempt at %s, got %s", want, delivery.NextAttemptAt)
+	}
+	if delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected last status 500, got %v", delivery.LastStatusCode)
+	}
+
+	// До наступления времени повтора доставка не отправляется.
+	if _, err := dispatcher.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	clock = clock.Add(Backoff(1))
+	if _, err := dispatcher.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	delivery = store.only(t)
+	if delivery.Status != models.WebhookDeliveryFailed || delivery.Attempts != 2 {
+		t.Fatalf("expected failed delivery, got %+v", delivery)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 {
+		t.Fatalf("expected 2 requests, got %d", calls)
+	}
+}
+
+// TestBackoff проверяет экспоненциальный рост задержки и ее ограничение.
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		20: retryMaxDelay,
+	}
+	for attempt, want := range cases {
+		if got := Backoff(attempt); got != want {
+			t.Fatalf("attempt %d: expected %s, got %s", attempt, want, got)
+		}
+	}
+}
+
+// TestDispatcherRefusesInternalAddress проверяет, что доставка на внутренний
+// адрес отклоняется при соединении, даже если endpoint уже зарегистрирован.
+func TestDispatcherRefusesInternalAddress(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	userID := uuid.New()
+	store := newMemoryStore(memoryEndpoint{userID: userID, url: server.URL, secret: "s"})
+	dispatcher := NewDispatcher(store, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Second, 3)
+
+	if err := dispatcher.Enqueue(context.Background(), userID, notifications.Event{Type: "budget_updated"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	delivered, err := dispatcher.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if delivered != 0 || called {
+		t.Fatalf("expected delivery to loopback to be refused")
+	}
+
+	delivery := store.only(t)
+	if delivery.LastError == nil || !strings.Contains(*delivery.LastError, ErrForbiddenAddress.Error()) {
+		t.Fatalf("expected forbidden address error, got %v", delivery.LastError)
+	}
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id UUID NOT NULL REFERENCES expense_items(id) ON DELETE CASCADE,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    occurred_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    merchant VARCHAR(200),
+    memo TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transactions_item_id ON transactions (item_id);
+CREATE INDEX idx_transactions_occurred_on ON transactions (occurred_on);
+
+-- +goose Down
+DROP TABLE IF EXISTS transactions;
@@ -0,0 +1,36 @@
+-- +goose Up
+ALTER TABLE budget_plans
+    ADD COLUMN recurrence VARCHAR(20) NOT NULL DEFAULT 'none'
+        CHECK (recurrence IN ('none', 'monthly', 'biweekly', 'custom')),
+    ADD COLUMN recurrence_interval_days INTEGER
+        CHECK (recurrence_interval_days IS NULL OR recurrence_interval_days > 0),
+    ADD COLUMN carry_over BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN next_plan_id UUID REFERENCES budget_plans(id) ON DELETE SET NULL,
+    -- Неудачные попытки переноса: план откладывается до rollover_retry_at,
+    -- чтобы не занимать пакет планировщика раз за разом.
+    ADD COLUMN rollover_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN rollover_retry_at TIMESTAMPTZ;
+
+ALTER TABLE budget_plans
+    ADD CONSTRAINT budget_plans_custom_interval_check
+        CHECK (recurrence <> 'custom' OR recurrence_interval_days IS NOT NULL);
+
+-- Категория с остатком прошлого периода, которую создает перенос остатка.
+ALTER TABLE expense_categories
+    ADD COLUMN is_carry_over BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_budget_plans_recurrence_due ON budget_plans (period_end)
+    WHERE recurrence <> 'none' AND next_plan_id IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_budget_plans_recurrence_due;
+ALTER TABLE expense_categories
+    DROP COLUMN IF EXISTS is_carry_over;
+ALTER TABLE budget_plans
+    DROP CONSTRAINT IF EXISTS budget_plans_custom_interval_check,
+    DROP COLUMN IF EXISTS rollover_retry_at,
+    DROP COLUMN IF EXISTS rollover_attempts,
+    DROP COLUMN IF EXISTS next_plan_id,
+    DROP COLUMN IF EXISTS carry_over,
+    DROP COLUMN IF EXISTS recurrence_interval_days,
+    DROP COLUMN IF EXISTS recurrence;
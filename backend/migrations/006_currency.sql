@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN base_currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE budget_plans
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE exchange_rates (
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    effective_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (from_currency, to_currency, effective_date),
+    CHECK (from_currency <> to_currency)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE budget_plans DROP COLUMN IF EXISTS currency;
+ALTER TABLE users DROP COLUMN IF EXISTS base_currency;
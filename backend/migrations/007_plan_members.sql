@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE plan_members (
+    plan_id UUID NOT NULL REFERENCES budget_plans(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (plan_id, user_id)
+);
+
+CREATE INDEX idx_plan_members_user_id ON plan_members (user_id);
+
+INSERT INTO plan_members (plan_id, user_id, role, created_at)
+SELECT id, user_id, 'owner', created_at
+FROM budget_plans;
+
+CREATE TABLE plan_invites (
+    id UUID PRIMARY KEY,
+    plan_id UUID NOT NULL REFERENCES budget_plans(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_plan_invites_plan_id ON plan_invites (plan_id);
+CREATE UNIQUE INDEX idx_plan_invites_pending ON plan_invites (plan_id, lower(email))
+    WHERE accepted_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS plan_invites;
+DROP TABLE IF EXISTS plan_members;
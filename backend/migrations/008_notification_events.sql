@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE notification_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_events_user_id ON notification_events (user_id, id);
+CREATE INDEX idx_notification_events_created_at ON notification_events (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_events;
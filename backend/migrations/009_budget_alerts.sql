@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE budget_alert_rules (
+    id UUID PRIMARY KEY,
+    plan_id UUID NOT NULL REFERENCES budget_plans(id) ON DELETE CASCADE,
+    rule_type VARCHAR(20) NOT NULL CHECK (rule_type IN ('plan_total', 'category_share')),
+    threshold_percent INTEGER NOT NULL CHECK (threshold_percent > 0 AND threshold_percent <= 1000),
+    category_id UUID REFERENCES expense_categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (rule_type = 'category_share' OR category_id IS NULL)
+);
+
+CREATE INDEX idx_budget_alert_rules_plan_id ON budget_alert_rules (plan_id);
+
+-- scope_id — план для plan_total и категория для category_share:
+-- каждое правило срабатывает для своей области не больше одного раза.
+CREATE TABLE budget_alert_triggers (
+    rule_id UUID NOT NULL REFERENCES budget_alert_rules(id) ON DELETE CASCADE,
+    scope_id UUID NOT NULL,
+    triggered_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (rule_id, scope_id)
+);
+
+INSERT INTO budget_alert_rules (id, plan_id, rule_type, threshold_percent)
+SELECT gen_random_uuid(), p.id, d.rule_type, d.threshold_percent
+FROM budget_plans p
+CROSS JOIN (VALUES ('plan_total', 50), ('plan_total', 80), ('plan_total', 100), ('category_share', 100))
+    AS d(rule_type, threshold_percent);
+
+-- +goose Down
+DROP TABLE IF EXISTS budget_alert_triggers;
+DROP TABLE IF EXISTS budget_alert_rules;
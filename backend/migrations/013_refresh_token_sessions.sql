@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE refresh_tokens
+    ADD COLUMN user_agent TEXT,
+    ADD COLUMN ip_address TEXT;
+
+-- +goose Down
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS user_agent;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE signing_keys (
+    id TEXT PRIMARY KEY,
+    algorithm TEXT NOT NULL CHECK (algorithm IN ('RS256', 'EdDSA')),
+    encrypted_private_key BYTEA NOT NULL,
+    activates_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_signing_keys_activates_at ON signing_keys (activates_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS signing_keys;
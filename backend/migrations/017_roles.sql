@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE roles (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE role_permissions (
+    role TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE user_roles (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role)
+);
+
+CREATE INDEX idx_user_roles_role ON user_roles (role);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access to the admin panel, including role management'),
+    ('support', 'Read-only access to users, AI request logs and usage');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:read'),
+    ('admin', 'ai_requests:read'),
+    ('admin', 'ai_requests:payloads'),
+    ('admin', 'usage:read'),
+    ('admin', 'exchange_rates:read'),
+    ('admin', 'exchange_rates:write'),
+    ('admin', 'roles:manage'),
+    ('support', 'users:read'),
+    ('support', 'ai_requests:read'),
+    ('support', 'usage:read');
+
+-- +goose Down
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN failed_login_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_failed_login_at TIMESTAMPTZ,
+    ADD COLUMN locked_until TIMESTAMPTZ;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:unlock'),
+    ('support', 'users:unlock');
+
+-- +goose Down
+DELETE FROM role_permissions WHERE permission = 'users:unlock';
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS last_failed_login_at,
+    DROP COLUMN IF EXISTS failed_login_attempts;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'ru',
+    ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    ADD COLUMN week_start VARCHAR(10) NOT NULL DEFAULT 'monday' CHECK (week_start IN ('monday', 'sunday')),
+    ADD COLUMN pending_email VARCHAR(255);
+
+ALTER TABLE user_tokens DROP CONSTRAINT user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+    CHECK (purpose IN ('password_reset', 'email_verification', 'email_change'));
+
+-- +goose Down
+DELETE FROM user_tokens WHERE purpose = 'email_change';
+
+ALTER TABLE user_tokens DROP CONSTRAINT user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+    CHECK (purpose IN ('password_reset', 'email_verification'));
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS pending_email,
+    DROP COLUMN IF EXISTS week_start,
+    DROP COLUMN IF EXISTS time_zone,
+    DROP COLUMN IF EXISTS locale;